@@ -1,20 +1,186 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/cli"
+	"github.com/N95Ryan/leaf/internal/config"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/watcher"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 )
 
 func main() {
-	m := app.NewModel()
+	dir := flag.String("dir", "", "notes directory (overrides $"+config.EnvDir+")")
+	vaultName := flag.String("vault", "", "name of a vault from the config file")
+	flag.Parse()
+
+	// Load the user configuration
+	cfgPath, err := config.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Pick the vault to open
+	vault, err := cfg.ResolveVault(*dir, *vaultName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fileMode, err := cfg.NoteFileMode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	trashRetention, err := cfg.TrashRetentionDuration()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	maxVersions, maxAge, err := cfg.HistoryRetention()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	filenames, err := cfg.FilenameStrategy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	storageOptions := []storage.LocalOption{
+		storage.WithFileMode(fileMode),
+		storage.WithTrashRetention(trashRetention),
+		storage.WithHistoryRetention(maxVersions, maxAge),
+		storage.WithFilenames(filenames),
+	}
+
+	commitDelay, err := cfg.CommitDelay()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	notes, err := openStorage(vault, commitDelay, storageOptions, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Run a non-interactive command if one is given
+	if args := flag.Args(); len(args) > 0 {
+		code := cli.Run(&cli.Env{
+			Storage: notes,
+			Stdin:   os.Stdin,
+			Stdout:  os.Stdout,
+			Stderr:  os.Stderr,
+
+			StdinIsTerminal: term.IsTerminal(os.Stdin.Fd()),
+
+			Journal: cfg.Journal(),
+		}, args)
+		if !closeStorage(notes) && code == cli.ExitOK {
+			code = cli.ExitError
+		}
+		os.Exit(code)
+	}
+
+	// Make sure the active vault is listed in the switcher
+	vaults := cfg.Vaults
+	if _, ok := cfg.Vault(vault.Name); !ok {
+		vaults = append([]config.Vault{vault}, vaults...)
+	}
+
+	m := app.NewModel(
+		app.WithStorage(notes),
+		app.WithVaults(vaults, vault.Name),
+		app.WithStorageOpener(func(path string) (storage.FileSystem, error) {
+			// Vaults opened by path are git vaults if the config says so
+			opened := config.Vault{Path: path}
+			for _, v := range vaults {
+				if v.Path == path {
+					opened = v
+				}
+			}
+			// The interface owns the terminal, so maintenance stays quiet
+			return openStorage(opened, commitDelay, storageOptions, io.Discard)
+		}),
+		app.WithWatcher(watcher.New),
+		app.WithJournal(cfg.Journal()),
+	)
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Commit what the last vault opened still has pending
+	if final, ok := final.(app.Model); ok && !closeStorage(final.Storage()) {
+		os.Exit(1)
+	}
+}
+
+// openStorage opens the notes of vault, committing every change to git when
+// the vault asks for it
+// It first cleans up after a crash and purges the expired trash, reporting
+// problems to log since neither keeps the vault from opening
+func openStorage(vault config.Vault, commitDelay time.Duration, opts []storage.LocalOption, log io.Writer) (storage.FileSystem, error) {
+	local, err := storage.NewLocalFileSystemAt(vault.Path, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up after writes interrupted by a crash
+	removed, err := local.Recover()
+	for _, path := range removed {
+		fmt.Fprintf(log, "leaf: removed leftover temporary file %s\n", path)
+	}
+	if err != nil {
+		fmt.Fprintf(log, "leaf: %v\n", err)
+	}
+
+	// Forget the notes deleted longer ago than the retention
+	if _, err := local.PurgeExpired(context.Background()); err != nil {
+		fmt.Fprintf(log, "leaf: %v\n", err)
+	}
+
+	if !vault.Git {
+		return local, nil
+	}
+
+	git, err := storage.NewGitFileSystem(local, storage.WithCommitDelay(commitDelay))
+	if err != nil {
+		return nil, err
+	}
+	return git, nil
+}
+
+// closeStorage releases a storage that needs it, reporting whether that went well
+func closeStorage(fs storage.FileSystem) bool {
+	closer, ok := fs.(io.Closer)
+	if !ok {
+		return true
+	}
+	if err := closer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "leaf: %v\n", err)
+		return false
+	}
+	return true
 }
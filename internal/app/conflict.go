@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/diff"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// conflictChromeHeight is the number of lines around the diff in ModeConflict
+const conflictChromeHeight = 8
+
+// conflictMsg is sent once the version on disk of a conflicting note is loaded
+type conflictMsg struct {
+	mine   *storage.Note // The note as the user saved it
+	theirs *storage.Note // The note as it is on disk
+	err    error
+}
+
+// startEditing opens note in ModeEdit
+// A copy of the note is kept as the base of a three-way merge, should saving
+// it conflict with a change made on disk meanwhile
+func (m *Model) startEditing(note *storage.Note) {
+	m.mode = ModeEdit
+	m.currentNote = note
+	base := *note
+	m.editBase = &base
+	// Load note title and content into editors
+	m.titleInput.SetValue(note.Title)
+	m.contentEditor.SetValue(note.Content)
+	// Start with content focused
+	m.editFocus = "content"
+	m.titleInput.Blur()
+	m.contentEditor.Focus()
+}
+
+// loadConflictCmd loads the version on disk of a note that failed to save
+func loadConflictCmd(fs storage.FileSystem, mine *storage.Note) tea.Cmd {
+	return func() tea.Msg {
+		theirs, err := fs.GetNote(context.Background(), mine.ID)
+		return conflictMsg{mine: mine, theirs: theirs, err: err}
+	}
+}
+
+// handleConflict opens the conflict screen
+func (m Model) handleConflict(msg conflictMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.lastError = fmt.Sprintf("could not save %s: it changed on disk and could not be reloaded: %v", msg.mine.Title, msg.err)
+		return m, nil
+	}
+
+	m.mode = ModeConflict
+	m.conflictMine = msg.mine
+	m.conflictTheirs = msg.theirs
+	m.lastError = ""
+	return m, nil
+}
+
+// handleConflictMode handles key presses in ModeConflict
+func (m Model) handleConflictMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	mine, theirs := m.conflictMine, m.conflictTheirs
+
+	switch msg.String() {
+	case "o":
+		// Keep our version, replacing the one on disk
+		mine.Revision = theirs.Revision
+		m.clearConflict()
+		m.mode = ModeList
+		return m, saveNoteCmd(m.storage, mine)
+
+	case "r":
+		// Drop our changes and show the version on disk
+		m.clearConflict()
+		m.editBase = nil
+		return m.enterViewMode(theirs), loadNotesCmd(m.storage)
+
+	case "m":
+		// Merge both versions and let the user review the result
+		base := m.editBase
+		if base == nil || base.ID != mine.ID {
+			base = &storage.Note{}
+		}
+		merged := *mine
+		merged.Revision = theirs.Revision
+		if mine.Title == base.Title {
+			merged.Title = theirs.Title
+		}
+		content, conflicts := diff.Merge3(base.Content, mine.Content, theirs.Content)
+		merged.Content = content
+
+		m.clearConflict()
+		m.startEditing(&merged)
+		// Further conflicts are merged against the version we merged with
+		theirsBase := *theirs
+		m.editBase = &theirsBase
+		if conflicts > 0 {
+			m.lastError = fmt.Sprintf("%d conflicting section(s) marked in the note, resolve them before saving", conflicts)
+		}
+		return m, nil
+
+	case "esc":
+		// Back to our changes, saving them again will conflict again
+		m.clearConflict()
+		base := m.editBase
+		m.startEditing(mine)
+		m.editBase = base
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// clearConflict forgets the notes of the conflict screen
+func (m *Model) clearConflict() {
+	m.conflictMine = nil
+	m.conflictTheirs = nil
+}
+
+// renderConflict displays the conflict screen
+func (m Model) renderConflict() string {
+	mine, theirs := m.conflictMine, m.conflictTheirs
+	if mine == nil || theirs == nil {
+		return "No conflict"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("⚠️  '%s' changed on disk while you were editing it\n\n", theirs.Title))
+
+	if mine.Title != theirs.Title {
+		b.WriteString(fmt.Sprintf("Title: yours '%s', on disk '%s'\n", mine.Title, theirs.Title))
+	}
+	b.WriteString(ui.DimStyle.Render("Changes from the version on disk (-) to yours (+):") + "\n")
+
+	height := 0
+	if m.height > 0 {
+		height = max(m.height-conflictChromeHeight, 1)
+	}
+	b.WriteString(renderDiff(diff.Lines(theirs.Content, mine.Content), height))
+
+	b.WriteString("\n\nShortcuts: o (overwrite with yours), r (reload from disk), m (merge), Esc (back to editing)")
+	b.WriteString(m.renderError())
+
+	return b.String()
+}
+
+// renderDiff displays diff lines with +/- markers, cut to height lines
+// A height of 0 shows every line
+func renderDiff(lines []diff.Line, height int) string {
+	var out []string
+	for _, line := range lines {
+		switch line.Kind {
+		case diff.Insert:
+			out = append(out, ui.DiffInsertStyle.Render("+ "+line.Text))
+		case diff.Delete:
+			out = append(out, ui.DiffDeleteStyle.Render("- "+line.Text))
+		default:
+			out = append(out, "  "+line.Text)
+		}
+	}
+
+	if height > 0 && len(out) > height {
+		hidden := len(out) - height + 1
+		out = append(out[:height-1], ui.DimStyle.Render(fmt.Sprintf("… %d more line(s)", hidden)))
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// permanentErrors are the storage errors that retrying can't fix
+var permanentErrors = []error{
+	storage.ErrNotFound,
+	storage.ErrConflict,
+	storage.ErrInvalidID,
+	storage.ErrReadOnly,
+	storage.ErrCorrupt,
+}
+
+// storageFailed shows a storage error and, when it may be transient (a busy
+// disk, a network share gone for a moment), offers to retry with retry
+func (m *Model) storageFailed(err error, retry tea.Cmd) {
+	m.lastError = err.Error()
+	m.retry = nil
+	if transient(err) {
+		m.retry = retry
+	}
+}
+
+// transient reports whether the operation that failed with err may succeed
+// if tried again
+func transient(err error) bool {
+	for _, permanent := range permanentErrors {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	var parseErr *storage.ParseError
+	return !errors.As(err, &parseErr)
+}
+
+// retryFailed runs the operation that failed last again
+func (m Model) retryFailed() (tea.Model, tea.Cmd) {
+	retry := m.retry
+	m.retry = nil
+	m.lastError = ""
+	return m, retry
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/N95Ryan/leaf/internal/editor"
+	"github.com/N95Ryan/leaf/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorClosedMsg is sent when the external editor exits
+type editorClosedMsg struct {
+	note *storage.Note // The note as it was before editing
+	err  error
+}
+
+// ExternalEditMsg is sent once a note edited externally has been reloaded
+type ExternalEditMsg struct {
+	Note    *storage.Note
+	Changed bool
+	Err     error
+}
+
+// openInEditor suspends the program and opens note in $VISUAL/$EDITOR
+func (m Model) openInEditor(note *storage.Note) (tea.Model, tea.Cmd) {
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	return m, externalEditCmd(note)
+}
+
+// externalEditCmd runs the user's editor on the note file
+func externalEditCmd(note *storage.Note) tea.Cmd {
+	cmd, err := editor.Command(note.FilePath)
+	if err != nil {
+		return func() tea.Msg {
+			return ExternalEditMsg{Note: note, Err: fmt.Errorf("could not open %s: %w", note.Title, err)}
+		}
+	}
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorClosedMsg{note: note, err: err}
+	})
+}
+
+// handleEditorClosed reloads the note once the editor exits
+func (m Model) handleEditorClosed(msg editorClosedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		// The file may still have been written, reload it anyway
+		m.lastError = fmt.Sprintf("editor failed: %v", msg.err)
+	}
+	return m, reloadEditedNoteCmd(m.storage, msg.note)
+}
+
+// reloadEditedNoteCmd re-parses a note after an external edit and saves it
+// when it changed, so its updated timestamp and the search index follow
+func reloadEditedNoteCmd(fs storage.FileSystem, before *storage.Note) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		after, err := fs.GetNote(ctx, before.ID)
+		if err != nil {
+			return ExternalEditMsg{Note: before, Err: err}
+		}
+		if after.SameContent(before) {
+			return ExternalEditMsg{Note: after}
+		}
+
+		if err := fs.SaveNote(ctx, after); err != nil {
+			return ExternalEditMsg{Note: after, Err: err}
+		}
+		return ExternalEditMsg{Note: after, Changed: true}
+	}
+}
+
+// handleExternalEdit shows the edited note and refreshes the list
+func (m Model) handleExternalEdit(msg ExternalEditMsg) (tea.Model, tea.Cmd) {
+	if errors.Is(msg.Err, storage.ErrNotFound) {
+		// The note was deleted or renamed from the editor
+		m.dropNote(msg.Note.ID)
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+	if !msg.Changed {
+		return m, nil
+	}
+
+	m.lastError = ""
+	if m.currentNote != nil && m.currentNote.ID == msg.Note.ID {
+		m.currentNote = msg.Note
+		m.refreshViewer()
+	}
+	return m, loadNotesCmd(m.storage)
+}
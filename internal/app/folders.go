@@ -0,0 +1,445 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// foldersLoadedMsg is sent when the folders of the vault are loaded
+type foldersLoadedMsg struct {
+	folders []string
+	err     error
+}
+
+// folderChangedMsg is sent when a folder was created (from is empty),
+// renamed or moved
+type folderChangedMsg struct {
+	from, to string
+	err      error
+}
+
+// NoteMovedMsg is sent when a note was moved to another folder
+type NoteMovedMsg struct {
+	Note *storage.Note
+	Err  error
+}
+
+// folderRow is a line of the folder tree
+type folderRow struct {
+	folder   string // "" for the root of the vault
+	depth    int
+	children bool
+}
+
+// newFolderInput creates the input used to name folders
+func newFolderInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 40
+	return ti
+}
+
+// enterFoldersMode focuses the folder tree, with the current folder selected
+// action is "" to browse, or "moveNote" to pick the folder of the selected note
+func (m Model) enterFoldersMode(action string) (tea.Model, tea.Cmd) {
+	if _, ok := m.storage.(storage.Folders); !ok {
+		m.lastError = "this vault has no folders"
+		return m, nil
+	}
+
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	m.mode = ModeFolders
+	m.folderAction = action
+	m.folderMoving = ""
+	if action == "moveNote" {
+		m.folderMoving = m.notes[m.selectedIdx].ID
+	}
+	m.selectFolder(m.folderFilter)
+	m.followSelection()
+	return m, nil
+}
+
+// handleFoldersMode handles key presses in ModeFolders
+func (m Model) handleFoldersMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	folders, _ := m.storage.(storage.Folders)
+	key := msg.String()
+
+	// Naming a new or renamed folder
+	if m.folderAction == "create" || m.folderAction == "rename" {
+		switch key {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "esc":
+			m.folderAction = ""
+			m.folderInput.Blur()
+			return m, nil
+
+		case "enter":
+			name := strings.Trim(strings.TrimSpace(m.folderInput.Value()), "/")
+			if name == "" || folders == nil {
+				return m, nil
+			}
+			selected := m.selectedFolder()
+			action := m.folderAction
+			m.folderAction = ""
+			m.folderInput.Blur()
+			if action == "create" {
+				return m, createFolderCmd(folders, path.Join(selected, name))
+			}
+			return m, renameFolderCmd(folders, selected, path.Join(parentFolder(selected), name))
+		}
+
+		var cmd tea.Cmd
+		m.folderInput, cmd = m.folderInput.Update(msg)
+		return m, cmd
+	}
+
+	rows := m.folderRows()
+	selected := m.selectedFolder()
+
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		// Cancel a move, or go back to the notes
+		if m.folderAction != "" {
+			m.folderAction = ""
+			m.folderMoving = ""
+			return m, nil
+		}
+		m.mode = ModeList
+		m.followSelection()
+		return m, nil
+
+	case "j", "down":
+		if m.folderIdx < len(rows)-1 {
+			m.folderIdx++
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.folderIdx > 0 {
+			m.folderIdx--
+		}
+		return m, nil
+
+	case "h", "left":
+		// Collapse the folder, or go up to its parent
+		row := rows[m.folderIdx]
+		if row.children && !m.folderCollapsed[row.folder] {
+			m.folderCollapsed[row.folder] = true
+		} else if row.folder != "" {
+			m.selectFolder(parentFolder(row.folder))
+		}
+		return m, nil
+
+	case "l", "right":
+		delete(m.folderCollapsed, selected)
+		return m, nil
+
+	case " ":
+		if m.folderCollapsed[selected] {
+			delete(m.folderCollapsed, selected)
+		} else if rows[m.folderIdx].children {
+			m.folderCollapsed[selected] = true
+		}
+		return m, nil
+
+	case "enter":
+		action, moving := m.folderAction, m.folderMoving
+		m.folderAction = ""
+		m.folderMoving = ""
+		switch {
+		case action == "moveNote" && folders != nil:
+			m.mode = ModeList
+			m.followSelection()
+			return m, moveNoteCmd(folders, moving, selected)
+		case action == "move" && folders != nil:
+			return m, renameFolderCmd(folders, moving, path.Join(selected, path.Base(moving)))
+		}
+
+		// Show the notes of the folder and its subfolders
+		m.folderFilter = selected
+		m.mode = ModeList
+		m.selectedIdx = 0
+		m.listOffset = 0
+		return m, loadNotesCmd(m.storage)
+	}
+
+	// The other commands wait until the destination of a move is picked
+	if m.folderAction != "" {
+		return m, nil
+	}
+
+	switch key {
+	case "n":
+		// Create a folder inside the selected one
+		m.folderAction = "create"
+		m.folderInput.Placeholder = "New folder name"
+		m.folderInput.SetValue("")
+		m.folderInput.Focus()
+		return m, textinput.Blink
+
+	case "r":
+		if selected == "" {
+			return m, nil
+		}
+		m.folderAction = "rename"
+		m.folderInput.Placeholder = "Folder name"
+		m.folderInput.SetValue(path.Base(selected))
+		m.folderInput.CursorEnd()
+		m.folderInput.Focus()
+		return m, textinput.Blink
+
+	case "m":
+		// Pick where the selected folder goes
+		if selected == "" {
+			return m, nil
+		}
+		m.folderAction = "move"
+		m.folderMoving = selected
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// folderRows returns the visible lines of the folder tree, starting with the
+// root of the vault and skipping the content of collapsed folders
+func (m Model) folderRows() []folderRow {
+	rows := []folderRow{{folder: "", children: len(m.folders) > 0}}
+	for i, folder := range m.folders {
+		hidden := false
+		for parent := parentFolder(folder); parent != ""; parent = parentFolder(parent) {
+			if m.folderCollapsed[parent] {
+				hidden = true
+				break
+			}
+		}
+		if hidden {
+			continue
+		}
+		children := i+1 < len(m.folders) && strings.HasPrefix(m.folders[i+1], folder+"/")
+		rows = append(rows, folderRow{folder: folder, depth: strings.Count(folder, "/") + 1, children: children})
+	}
+	if m.folderCollapsed[""] {
+		rows = rows[:1]
+	}
+	return rows
+}
+
+// selectedFolder returns the folder selected in the tree
+func (m Model) selectedFolder() string {
+	rows := m.folderRows()
+	if m.folderIdx >= len(rows) {
+		return ""
+	}
+	return rows[m.folderIdx].folder
+}
+
+// selectFolder selects folder in the tree, expanding its parents
+func (m *Model) selectFolder(folder string) {
+	for parent := parentFolder(folder); parent != ""; parent = parentFolder(parent) {
+		delete(m.folderCollapsed, parent)
+	}
+	if folder != "" {
+		delete(m.folderCollapsed, "")
+	}
+
+	m.folderIdx = 0
+	for i, row := range m.folderRows() {
+		if row.folder == folder {
+			m.folderIdx = i
+			break
+		}
+	}
+}
+
+// inFolder reports whether folder is parent or one of its subfolders
+func inFolder(folder, parent string) bool {
+	return parent == "" || folder == parent || strings.HasPrefix(folder, parent+"/")
+}
+
+// parentFolder returns the folder holding folder, "" for the root
+func parentFolder(folder string) string {
+	if i := strings.LastIndex(folder, "/"); i >= 0 {
+		return folder[:i]
+	}
+	return ""
+}
+
+// loadFoldersCmd loads the folders of the vault
+// It returns nil when the storage has no folders
+func loadFoldersCmd(fs storage.FileSystem) tea.Cmd {
+	folders, ok := fs.(storage.Folders)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		list, err := folders.ListFolders(context.Background())
+		return foldersLoadedMsg{folders: list, err: err}
+	}
+}
+
+// createFolderCmd creates a folder
+func createFolderCmd(folders storage.Folders, folder string) tea.Cmd {
+	return func() tea.Msg {
+		err := folders.CreateFolder(context.Background(), folder)
+		return folderChangedMsg{to: folder, err: err}
+	}
+}
+
+// renameFolderCmd renames or moves a folder
+func renameFolderCmd(folders storage.Folders, from, to string) tea.Cmd {
+	return func() tea.Msg {
+		err := folders.RenameFolder(context.Background(), from, to)
+		return folderChangedMsg{from: from, to: to, err: err}
+	}
+}
+
+// moveNoteCmd moves a note to another folder
+func moveNoteCmd(folders storage.Folders, id, folder string) tea.Cmd {
+	return func() tea.Msg {
+		note, err := folders.MoveNote(context.Background(), id, folder)
+		return NoteMovedMsg{Note: note, Err: err}
+	}
+}
+
+// handleFoldersLoaded shows the folders in the tree
+func (m Model) handleFoldersLoaded(msg foldersLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	selected := m.selectedFolder()
+	m.folders = msg.folders
+	sort.Strings(m.folders)
+	if m.folderFocus != "" {
+		selected = m.folderFocus
+		m.folderFocus = ""
+	}
+	m.selectFolder(selected)
+	// The tree pane may have appeared, which narrows the preview
+	m.followSelection()
+	return m, nil
+}
+
+// handleFolderChanged selects the new or renamed folder and reloads the notes
+func (m Model) handleFolderChanged(msg folderChangedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	if msg.from != "" && inFolder(m.folderFilter, msg.from) && m.folderFilter != "" {
+		// The notes shown moved along with their folder
+		m.folderFilter = msg.to + strings.TrimPrefix(m.folderFilter, msg.from)
+	}
+	m.folderFocus = msg.to
+	return m, loadNotesCmd(m.storage)
+}
+
+// handleNoteMoved reloads the notes, the moved one may leave the list
+func (m Model) handleNoteMoved(msg NoteMovedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	return m, loadNotesCmd(m.storage)
+}
+
+// renderFolderTree displays height lines of the folder tree
+func (m Model) renderFolderTree(height int) string {
+	rows := m.folderRows()
+	labels := make([]string, len(rows))
+	for i, row := range rows {
+		marker := "  "
+		switch {
+		case row.children && m.folderCollapsed[row.folder]:
+			marker = "▸ "
+		case row.children:
+			marker = "▾ "
+		}
+		name := "All notes"
+		if row.folder != "" {
+			name = path.Base(row.folder)
+		}
+		if row.folder == m.folderFilter {
+			name += " •"
+		}
+		labels[i] = strings.Repeat("  ", row.depth) + marker + name
+	}
+
+	tree := ui.NoteList{
+		Titles:   labels,
+		Selected: -1,
+		Offset:   listWindow(0, m.folderIdx, height, len(rows)),
+		Height:   height,
+	}
+	if m.mode == ModeFolders {
+		tree.Selected = m.folderIdx
+	}
+	if m.splitPane() {
+		tree.Width = m.sidePaneWidth()
+	}
+	return tree.View()
+}
+
+// renderFolderPrompt displays the input naming a folder, or what is being moved
+func (m Model) renderFolderPrompt() string {
+	if m.mode != ModeFolders {
+		return ""
+	}
+	switch m.folderAction {
+	case "create":
+		return fmt.Sprintf("\nNew folder in %s: %s", folderLabel(m.selectedFolder()), m.folderInput.View())
+	case "rename":
+		return fmt.Sprintf("\nRename %s: %s", m.selectedFolder(), m.folderInput.View())
+	case "move":
+		return fmt.Sprintf("\nMove folder %s to…", m.folderMoving)
+	case "moveNote":
+		title := m.folderMoving
+		for _, note := range m.notes {
+			if note.ID == m.folderMoving {
+				title = note.Title
+				break
+			}
+		}
+		return fmt.Sprintf("\nMove '%s' to…", title)
+	}
+	return ""
+}
+
+// folderShortcuts returns the shortcuts of ModeFolders
+func (m Model) folderShortcuts() string {
+	switch m.folderAction {
+	case "create", "rename":
+		return "Shortcuts: Enter (confirm), Esc (cancel)"
+	case "move", "moveNote":
+		return "Shortcuts: j/k (pick a folder), h/l (collapse/expand), Enter (move here), Esc (cancel)"
+	}
+	return "Shortcuts: j/k (move), Enter (show notes), h/l/space (collapse/expand), n (new folder), r (rename), m (move), Esc (back)"
+}
+
+// folderLabel returns the name shown for folder
+func folderLabel(folder string) string {
+	if folder == "" {
+		return "the root"
+	}
+	return folder
+}
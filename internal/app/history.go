@@ -0,0 +1,323 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/diff"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// historyListHeight is the number of revisions shown at once in ModeHistory
+	historyListHeight = 6
+
+	// historyChromeHeight is the number of lines around the revisions and the
+	// diff in ModeHistory (title, blank lines, diff header, shortcuts)
+	historyChromeHeight = 7
+
+	// historyContext is the number of unchanged lines around each change in
+	// the unified diff
+	historyContext = 3
+)
+
+// historyLoadedMsg is sent when the versions of a note are loaded
+type historyLoadedMsg struct {
+	noteID   string
+	versions []*storage.Version
+	notes    []*storage.Note // Content of each version, in the same order
+	err      error
+}
+
+// enterHistoryMode opens the history of the note being viewed
+func (m Model) enterHistoryMode() (tea.Model, tea.Cmd) {
+	history, ok := m.storage.(storage.History)
+	if !ok {
+		m.lastError = "this vault keeps no history"
+		return m, nil
+	}
+	if m.currentNote == nil {
+		return m, nil
+	}
+
+	m.mode = ModeHistory
+	m.historyVersions = nil
+	m.historyNotes = nil
+	m.historyIdx = 0
+	m.historyBase = -1
+	m.historyDiff = viewport.New(defaultViewerWidth, 0)
+	return m, loadHistoryCmd(history, m.currentNote.ID)
+}
+
+// loadHistoryCmd loads every version of note id
+func loadHistoryCmd(history storage.History, id string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		versions, err := history.ListVersions(ctx, id)
+		if err != nil {
+			return historyLoadedMsg{noteID: id, err: err}
+		}
+
+		notes := make([]*storage.Note, len(versions))
+		for i, version := range versions {
+			notes[i], err = history.GetVersion(ctx, id, version.ID)
+			if err != nil {
+				return historyLoadedMsg{noteID: id, err: err}
+			}
+		}
+		return historyLoadedMsg{noteID: id, versions: versions, notes: notes}
+	}
+}
+
+// handleHistoryLoaded shows the versions of the note
+func (m Model) handleHistoryLoaded(msg historyLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.mode != ModeHistory || m.currentNote == nil || m.currentNote.ID != msg.noteID {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	m.historyVersions = msg.versions
+	m.historyNotes = msg.notes
+	m.historyIdx = 0
+	m.historyBase = -1
+	m.refreshHistoryDiff()
+	m.historyDiff.GotoTop()
+	return m, nil
+}
+
+// handleHistoryMode handles key presses in ModeHistory
+func (m Model) handleHistoryMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeView
+		m.historyVersions = nil
+		m.historyNotes = nil
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "j", "down":
+		if m.historyIdx < len(m.historyVersions)-1 {
+			m.historyIdx++
+			m.refreshHistoryDiff()
+			m.historyDiff.GotoTop()
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.historyIdx > 0 {
+			m.historyIdx--
+			m.refreshHistoryDiff()
+			m.historyDiff.GotoTop()
+		}
+		return m, nil
+
+	case "b":
+		// Compare against the selected revision, or back to the previous one
+		if m.historyBase == m.historyIdx {
+			m.historyBase = -1
+		} else {
+			m.historyBase = m.historyIdx
+		}
+		m.refreshHistoryDiff()
+		m.historyDiff.GotoTop()
+		return m, nil
+
+	case "s":
+		// Toggle between the unified and the side-by-side diff
+		m.historySideBySide = !m.historySideBySide
+		m.refreshHistoryDiff()
+		m.historyDiff.GotoTop()
+		return m, nil
+
+	case "r", "enter":
+		return m.restoreVersion()
+	}
+
+	// Anything else scrolls the diff
+	var cmd tea.Cmd
+	m.historyDiff, cmd = m.historyDiff.Update(msg)
+	return m, cmd
+}
+
+// restoreVersion saves the selected revision as the latest version of the note
+func (m Model) restoreVersion() (tea.Model, tea.Cmd) {
+	if m.currentNote == nil || m.historyIdx >= len(m.historyNotes) {
+		return m, nil
+	}
+	version := m.historyNotes[m.historyIdx]
+
+	// Keep the revision of the note on disk, the save must not look like a conflict
+	restored := *m.currentNote
+	restored.Title = version.Title
+	restored.Content = version.Content
+	restored.Tags = version.Tags
+	restored.Aliases = version.Aliases
+	restored.Metadata = version.Metadata
+
+	m.historyVersions = nil
+	m.historyNotes = nil
+	m = m.enterViewMode(&restored)
+	return m, saveNoteCmd(m.storage, &restored)
+}
+
+// historyBaseIdx returns the revision the selected one is compared with,
+// -1 when it is the oldest one
+func (m Model) historyBaseIdx() int {
+	if m.historyBase >= 0 && m.historyBase != m.historyIdx {
+		return m.historyBase
+	}
+	if m.historyIdx+1 < len(m.historyNotes) {
+		return m.historyIdx + 1
+	}
+	return -1
+}
+
+// refreshHistoryDiff lays out the diff viewport and fills it with the changes
+// from the base revision to the selected one
+func (m *Model) refreshHistoryDiff() {
+	width := m.width
+	if width <= 0 {
+		width = defaultViewerWidth
+	}
+	m.historyDiff.Width = width
+
+	if m.historyIdx >= len(m.historyNotes) {
+		m.historyDiff.SetContent("")
+		return
+	}
+
+	old := ""
+	if base := m.historyBaseIdx(); base >= 0 {
+		old = versionText(m.historyNotes[base])
+	}
+	lines := diff.Lines(old, versionText(m.historyNotes[m.historyIdx]))
+
+	if m.historySideBySide {
+		m.historyDiff.SetContent(renderSideBySide(diff.SideBySide(lines), width))
+	} else {
+		m.historyDiff.SetContent(renderHunks(diff.Hunks(lines, historyContext)))
+	}
+
+	// Before the first WindowSizeMsg, show the whole diff
+	if m.height <= 0 {
+		m.historyDiff.Height = m.historyDiff.TotalLineCount()
+		return
+	}
+	listHeight := min(len(m.historyVersions), historyListHeight)
+	m.historyDiff.Height = max(m.height-historyChromeHeight-listHeight, 1)
+}
+
+// versionText is what the diff compares: the title, then the content
+func versionText(note *storage.Note) string {
+	return "# " + note.Title + "\n\n" + note.Content
+}
+
+// renderHunks displays a unified diff
+func renderHunks(hunks []diff.Hunk) string {
+	if len(hunks) == 0 {
+		return ui.DimStyle.Render("No changes")
+	}
+
+	var out []string
+	for _, hunk := range hunks {
+		header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+		out = append(out, ui.DimStyle.Render(header), renderDiff(hunk.Lines, 0))
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderSideBySide displays a diff in two columns, the old text on the left
+func renderSideBySide(rows []diff.Row, width int) string {
+	column := max((width-3)/2, 1)
+
+	cell := func(line *diff.Line) string {
+		if line == nil {
+			return strings.Repeat(" ", column)
+		}
+		text := ansi.Truncate(line.Text, column, "…")
+		text += strings.Repeat(" ", column-ansi.StringWidth(text))
+		switch line.Kind {
+		case diff.Insert:
+			return ui.DiffInsertStyle.Render(text)
+		case diff.Delete:
+			return ui.DiffDeleteStyle.Render(text)
+		}
+		return text
+	}
+
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		sep := " │ "
+		if row.Old != row.New {
+			sep = " ┃ "
+		}
+		out[i] = cell(row.Old) + sep + cell(row.New)
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderHistory displays the revisions of the note and the diff of the selected one
+func (m Model) renderHistory() string {
+	if m.currentNote == nil {
+		return "No note selected"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🕘 History of %s\n\n", m.currentNote.Title))
+
+	if len(m.historyVersions) == 0 {
+		b.WriteString("No saved versions yet.\n")
+		b.WriteString("\nShortcuts: Esc (back to the note)")
+		b.WriteString(m.renderError())
+		return b.String()
+	}
+
+	// Keep the selected revision in the window
+	start := max(min(m.historyIdx-historyListHeight/2, len(m.historyVersions)-historyListHeight), 0)
+	end := min(start+historyListHeight, len(m.historyVersions))
+	base := m.historyBaseIdx()
+	for i := start; i < end; i++ {
+		version := m.historyVersions[i]
+		prefix := "  "
+		if i == m.historyIdx {
+			prefix = "> "
+		}
+		label := version.SavedAt.Format("2006-01-02 15:04:05")
+		if i == 0 {
+			label += " (latest)"
+		}
+		if i == base {
+			label += ui.DimStyle.Render(" [base]")
+		}
+		b.WriteString(fmt.Sprintf("%s%s  %s\n", prefix, label, ui.DimStyle.Render(m.historyNotes[i].Title)))
+	}
+
+	header := "Changes since the version before (-) in this one (+):"
+	if base < 0 {
+		header = "First saved version:"
+	} else if m.historyBase >= 0 && base == m.historyBase {
+		header = "Changes from the base (-) to this version (+):"
+	}
+	b.WriteString("\n" + ui.DimStyle.Render(header) + "\n")
+	b.WriteString(m.historyDiff.View())
+
+	layout := "s (side by side)"
+	if m.historySideBySide {
+		layout = "s (unified)"
+	}
+	b.WriteString(fmt.Sprintf("\n\nShortcuts: j/k (revision), b (set base), %s, pgup/pgdn (scroll), r/Enter (restore), Esc (back to the note)", layout))
+	b.WriteString(m.renderError())
+
+	return b.String()
+}
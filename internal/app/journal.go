@@ -0,0 +1,236 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// journalOpenedMsg is sent when a journal entry is loaded or created
+type journalOpenedMsg struct {
+	note *storage.Note
+	err  error
+}
+
+// journalLoadedMsg is sent when the journal entries of the vault are listed
+// for the calendar
+type journalLoadedMsg struct {
+	entries map[string]string // Titles of the entries, by ID
+	err     error
+}
+
+// WithJournal sets how daily journal entries are created
+func WithJournal(journal storage.Journal) Option {
+	return func(m *Model) {
+		m.journal = journal
+	}
+}
+
+// openJournalCmd loads the journal entry of day, creating it when there is none
+func openJournalCmd(fs storage.FileSystem, journal storage.Journal, day time.Time) tea.Cmd {
+	return func() tea.Msg {
+		note, err := journal.Open(context.Background(), fs, day)
+		return journalOpenedMsg{note: note, err: err}
+	}
+}
+
+// loadJournalCmd lists the journal entries of the vault
+func loadJournalCmd(fs storage.FileSystem) tea.Cmd {
+	return func() tea.Msg {
+		notes, err := fs.ListNotes(context.Background())
+		if err != nil {
+			return journalLoadedMsg{err: err}
+		}
+		entries := map[string]string{}
+		for _, note := range notes {
+			if _, ok := storage.JournalDay(note.ID); ok {
+				entries[note.ID] = note.Title
+			}
+		}
+		return journalLoadedMsg{entries: entries}
+	}
+}
+
+// openDay opens the nearest journal entry before (delta < 0) or after
+// (delta > 0) the viewed one
+// Days without an entry are skipped rather than created
+func (m Model) openDay(delta int) (tea.Model, tea.Cmd) {
+	if m.currentNote == nil {
+		return m, nil
+	}
+	day, ok := storage.JournalDay(m.currentNote.ID)
+	if !ok {
+		m.lastError = "this note is not a journal entry"
+		return m, nil
+	}
+	return m, stepJournalCmd(m.storage, day, delta)
+}
+
+// stepJournalCmd loads the nearest existing journal entry before or after day
+func stepJournalCmd(fs storage.FileSystem, day time.Time, delta int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		notes, err := fs.ListNotes(ctx)
+		if err != nil {
+			return journalOpenedMsg{err: err}
+		}
+
+		nearest := ""
+		for _, note := range notes {
+			entry, ok := storage.JournalDay(note.ID)
+			if !ok {
+				continue
+			}
+			if delta < 0 && entry.Before(day) && (nearest == "" || note.ID > nearest) {
+				nearest = note.ID
+			}
+			if delta > 0 && entry.After(day) && (nearest == "" || note.ID < nearest) {
+				nearest = note.ID
+			}
+		}
+		if nearest == "" {
+			which := "after"
+			if delta < 0 {
+				which = "before"
+			}
+			return journalOpenedMsg{err: fmt.Errorf("no journal entry %s %s", which, storage.JournalID(day))}
+		}
+
+		note, err := fs.GetNote(ctx, nearest)
+		return journalOpenedMsg{note: note, err: err}
+	}
+}
+
+// handleJournalOpened shows the journal entry that was opened
+func (m Model) handleJournalOpened(msg journalOpenedMsg) (tea.Model, tea.Cmd) {
+	if m.mode != ModeList && m.mode != ModeView && m.mode != ModeCalendar {
+		// The user moved on in the meantime
+		return m, nil
+	}
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	m.viewFromSearch = false
+	// The entry may be new
+	return m.enterViewMode(msg.note), loadNotesCmd(m.storage)
+}
+
+// enterCalendarMode shows the month of the selected day with its journal entries
+func (m Model) enterCalendarMode() (tea.Model, tea.Cmd) {
+	m.mode = ModeCalendar
+	m.calendarDay = today()
+	m.journalEntries = nil
+	return m, loadJournalCmd(m.storage)
+}
+
+// handleJournalLoaded marks the days of the calendar that have an entry
+func (m Model) handleJournalLoaded(msg journalLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.mode != ModeCalendar {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+	m.journalEntries = msg.entries
+	return m, nil
+}
+
+// handleCalendarMode handles key presses in ModeCalendar
+func (m Model) handleCalendarMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeList
+		m.journalEntries = nil
+		return m, nil
+
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "h", "left":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, -1)
+	case "l", "right":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, 1)
+	case "k", "up":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, -7)
+	case "j", "down":
+		m.calendarDay = m.calendarDay.AddDate(0, 0, 7)
+	case "[", "pgup":
+		m.calendarDay = addMonths(m.calendarDay, -1)
+	case "]", "pgdown":
+		m.calendarDay = addMonths(m.calendarDay, 1)
+	case "t":
+		m.calendarDay = today()
+
+	case "enter":
+		// Open the entry of the selected day, creating it when there is none
+		return m, openJournalCmd(m.storage, m.journal, m.calendarDay)
+	}
+
+	return m, nil
+}
+
+// today returns the current day, at midnight
+func today() time.Time {
+	y, mo, d := time.Now().Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, time.Local)
+}
+
+// addMonths moves day by n months, keeping it within the month it lands in
+func addMonths(day time.Time, n int) time.Time {
+	y, mo, d := day.Date()
+	first := time.Date(y, mo+time.Month(n), 1, 0, 0, 0, 0, day.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(d, last)-1)
+}
+
+// renderCalendar displays the month of the selected day, marking the days
+// with a journal entry
+func (m Model) renderCalendar() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📅 %s\n\n", m.calendarDay.Format("January 2006")))
+	b.WriteString(" Mo  Tu  We  Th  Fr  Sa  Su\n")
+
+	y, mo, _ := m.calendarDay.Date()
+	first := time.Date(y, mo, 1, 0, 0, 0, 0, m.calendarDay.Location())
+	// Weeks start on Monday
+	column := (int(first.Weekday()) + 6) % 7
+	line := strings.Repeat(" ", 5*column)
+	for day := first; day.Month() == mo; day = day.AddDate(0, 0, 1) {
+		open, shut, mark := " ", " ", " "
+		if day.Equal(m.calendarDay) {
+			open, shut = "[", "]"
+		}
+		if _, ok := m.journalEntries[storage.JournalID(day)]; ok {
+			mark = "•"
+		}
+		line += fmt.Sprintf("%s%2d%s%s", open, day.Day(), shut, mark)
+
+		column++
+		if column == 7 {
+			b.WriteString(strings.TrimRight(line, " ") + "\n")
+			line, column = "", 0
+		}
+	}
+	if line != "" {
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	b.WriteString("\n" + m.calendarDay.Format("Monday, January 2, 2006") + ": ")
+	if title, ok := m.journalEntries[storage.JournalID(m.calendarDay)]; ok {
+		b.WriteString(title + "\n")
+	} else {
+		b.WriteString("no entry\n")
+	}
+
+	b.WriteString("\nShortcuts: h/l (day), j/k (week), [/] (month), t (today), Enter (open or create), Esc (back)")
+	b.WriteString(m.renderError())
+	return b.String()
+}
@@ -0,0 +1,299 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// linkFollowedMsg is sent when the note a link or the navigation history
+// leads to is loaded
+type linkFollowedMsg struct {
+	from   string // ID of the note being viewed
+	nav    string // "follow", "back" or "forward"
+	target string // Link target or note ID that was looked up
+	note   *storage.Note
+	err    error
+}
+
+// backlinksLoadedMsg is sent when the notes linking to a note are loaded
+type backlinksLoadedMsg struct {
+	noteID string
+	notes  []*storage.Note
+	err    error
+}
+
+// viewedLinks returns the [[links]] of the note being viewed
+func (m Model) viewedLinks() []storage.Link {
+	if m.currentNote == nil {
+		return nil
+	}
+	return storage.ParseLinks(m.currentNote.Content)
+}
+
+// selectLink selects the link delta links away from the selected one,
+// wrapping around, and scrolls the viewer to it
+func (m *Model) selectLink(delta int) {
+	links := m.viewedLinks()
+	if len(links) == 0 {
+		m.linkIdx = -1
+		return
+	}
+
+	switch {
+	case m.linkIdx < 0 || m.linkIdx >= len(links):
+		// The first press selects the first or the last link
+		m.linkIdx = 0
+		if delta < 0 {
+			m.linkIdx = len(links) - 1
+		}
+	default:
+		m.linkIdx = (m.linkIdx + delta + len(links)) % len(links)
+	}
+	m.revealLink(links, m.linkIdx)
+}
+
+// revealLink scrolls the viewer to the line showing links[idx], when it is
+// not on screen
+func (m *Model) revealLink(links []storage.Link, idx int) {
+	text := m.currentNote.Content[links[idx].Start:links[idx].End]
+
+	// Earlier links written the same way come first in the viewer too
+	occurrence := 0
+	for _, link := range links[:idx] {
+		if m.currentNote.Content[link.Start:link.End] == text {
+			occurrence++
+		}
+	}
+
+	for i, line := range m.viewerLines {
+		n := strings.Count(line, text)
+		if n <= occurrence {
+			occurrence -= n
+			continue
+		}
+		if i < m.viewer.YOffset || i >= m.viewer.YOffset+m.viewer.Height {
+			m.viewer.SetYOffset(i - m.viewer.Height/3)
+		}
+		return
+	}
+}
+
+// followLink opens the note the selected link leads to
+func (m Model) followLink() (tea.Model, tea.Cmd) {
+	links := m.viewedLinks()
+	if m.currentNote == nil || m.linkIdx < 0 || m.linkIdx >= len(links) {
+		return m, nil
+	}
+	graph, ok := m.storage.(storage.LinkGraph)
+	if !ok {
+		m.lastError = "this vault doesn't follow links"
+		return m, nil
+	}
+	return m, followLinkCmd(graph, m.currentNote.ID, links[m.linkIdx].Target)
+}
+
+// navigate goes back ("back") or forward ("forward") in the notes visited
+// through links
+func (m Model) navigate(nav string) (tea.Model, tea.Cmd) {
+	stack := m.navBack
+	if nav == "forward" {
+		stack = m.navForward
+	}
+	if m.currentNote == nil || len(stack) == 0 {
+		return m, nil
+	}
+	return m, navigateCmd(m.storage, m.currentNote.ID, stack[len(stack)-1], nav)
+}
+
+// followLinkCmd loads the note target names
+func followLinkCmd(graph storage.LinkGraph, from, target string) tea.Cmd {
+	return func() tea.Msg {
+		note, err := graph.ResolveLink(context.Background(), target)
+		return linkFollowedMsg{from: from, nav: "follow", target: target, note: note, err: err}
+	}
+}
+
+// navigateCmd loads note id, visited before
+func navigateCmd(fs storage.FileSystem, from, id, nav string) tea.Cmd {
+	return func() tea.Msg {
+		note, err := fs.GetNote(context.Background(), id)
+		return linkFollowedMsg{from: from, nav: nav, target: id, note: note, err: err}
+	}
+}
+
+// handleLinkFollowed shows the note a link led to and updates the navigation
+// history
+func (m Model) handleLinkFollowed(msg linkFollowedMsg) (tea.Model, tea.Cmd) {
+	if (m.mode != ModeView && m.mode != ModeBacklinks) || m.currentNote == nil || m.currentNote.ID != msg.from {
+		// The user moved on in the meantime
+		return m, nil
+	}
+
+	switch {
+	case msg.nav == "follow" && errors.Is(msg.err, storage.ErrNotFound):
+		m.lastError = fmt.Sprintf("no note named %q", msg.target)
+		return m, nil
+	case errors.Is(msg.err, storage.ErrNotFound):
+		// Deleted since it was visited: forget it
+		m.navBack = dropVisit(m.navBack, msg.target)
+		m.navForward = dropVisit(m.navForward, msg.target)
+		m.lastError = "the note was deleted"
+		return m, nil
+	case msg.err != nil:
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	switch msg.nav {
+	case "back":
+		m.navBack = m.navBack[:len(m.navBack)-1]
+		m.navForward = append(m.navForward, msg.from)
+	case "forward":
+		m.navForward = m.navForward[:len(m.navForward)-1]
+		m.navBack = append(m.navBack, msg.from)
+	default:
+		m.navBack = append(m.navBack, msg.from)
+		m.navForward = nil
+	}
+	return m.visitNote(msg.note), nil
+}
+
+// visitNote opens note in the viewer, keeping the navigation history
+func (m Model) visitNote(note *storage.Note) Model {
+	back, forward := m.navBack, m.navForward
+	m = m.enterViewMode(note)
+	m.navBack, m.navForward = back, forward
+	return m
+}
+
+// dropVisit removes note id from a navigation stack
+func dropVisit(stack []string, id string) []string {
+	kept := stack[:0:0]
+	for _, visited := range stack {
+		if visited != id {
+			kept = append(kept, visited)
+		}
+	}
+	return kept
+}
+
+// enterBacklinksMode lists the notes linking to the note being viewed
+func (m Model) enterBacklinksMode() (tea.Model, tea.Cmd) {
+	graph, ok := m.storage.(storage.LinkGraph)
+	if !ok {
+		m.lastError = "this vault doesn't follow links"
+		return m, nil
+	}
+	if m.currentNote == nil {
+		return m, nil
+	}
+
+	m.mode = ModeBacklinks
+	m.backlinks = nil
+	m.backlinkIdx = 0
+	return m, loadBacklinksCmd(graph, m.currentNote.ID)
+}
+
+// handleBacklinksMode handles key presses in ModeBacklinks
+func (m Model) handleBacklinksMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeView
+		m.backlinks = nil
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "j", "down":
+		if m.backlinkIdx < len(m.backlinks)-1 {
+			m.backlinkIdx++
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.backlinkIdx > 0 {
+			m.backlinkIdx--
+		}
+		return m, nil
+
+	case "enter":
+		// Open the linking note, as if a link led there
+		if m.backlinkIdx >= len(m.backlinks) || m.currentNote == nil {
+			return m, nil
+		}
+		m.navBack = append(m.navBack, m.currentNote.ID)
+		m.navForward = nil
+		note := m.backlinks[m.backlinkIdx]
+		m.backlinks = nil
+		return m.visitNote(note), nil
+	}
+
+	return m, nil
+}
+
+// loadBacklinksCmd loads the notes linking to note id
+func loadBacklinksCmd(graph storage.LinkGraph, id string) tea.Cmd {
+	return func() tea.Msg {
+		notes, err := graph.Backlinks(context.Background(), id)
+		return backlinksLoadedMsg{noteID: id, notes: notes, err: err}
+	}
+}
+
+// handleBacklinksLoaded shows the notes linking to the viewed note
+func (m Model) handleBacklinksLoaded(msg backlinksLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.mode != ModeBacklinks || m.currentNote == nil || m.currentNote.ID != msg.noteID {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	m.backlinks = msg.notes
+	m.backlinkIdx = 0
+	return m, nil
+}
+
+// renderLinkStatus describes the selected link of the viewed note
+func (m Model) renderLinkStatus() string {
+	links := m.viewedLinks()
+	if m.linkIdx < 0 || m.linkIdx >= len(links) {
+		return ""
+	}
+	return fmt.Sprintf("  🔗 %s %s", links[m.linkIdx].Text(), positionIndicator(m.linkIdx+1, len(links)))
+}
+
+// renderBacklinks displays the notes linking to the viewed note
+func (m Model) renderBacklinks() string {
+	if m.currentNote == nil {
+		return "No note selected"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🔗 Notes linking to %s\n\n", m.currentNote.Title))
+
+	if len(m.backlinks) == 0 {
+		b.WriteString("No note links here.\n")
+	} else {
+		for i, note := range m.backlinks {
+			prefix := "  "
+			if i == m.backlinkIdx {
+				prefix = "> "
+			}
+			b.WriteString(prefix + note.Title + "\n")
+		}
+	}
+
+	b.WriteString("\nShortcuts: j/k (move), Enter (open), Esc (back to note)")
+	b.WriteString(m.renderError())
+
+	return b.String()
+}
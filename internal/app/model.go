@@ -1,9 +1,16 @@
 package app
 
 import (
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/config"
+	"github.com/N95Ryan/leaf/internal/fuzzy"
 	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/N95Ryan/leaf/internal/watcher"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -16,6 +23,15 @@ const (
 	ModeEdit
 	ModeSearch
 	ModeCreate
+	ModeVaults
+	ModeQuickOpen
+	ModeConflict
+	ModeTrash
+	ModeHistory
+	ModeFolders
+	ModeTags
+	ModeBacklinks
+	ModeCalendar
 )
 
 // SortMode represents the different ways to sort notes
@@ -38,16 +54,61 @@ type Model struct {
 	// Notes
 	notes       []*storage.Note
 	selectedIdx int
+	listOffset  int // First note shown in ModeList
+	preview     ui.Preview
+	previewKey  string // Note and width the preview was rendered for
 	currentNote *storage.Note
 
 	// Search
-	searchQuery string
+	searchQuery    string
+	searchInput    textinput.Model
+	searchResults  []*storage.Note
+	searchTerms    []string // Words of the last successful query, for highlighting
+	queryError     *storage.ParseError
+	searchIdx      int
+	searchOffset   int    // First result shown in ModeSearch
+	searchFocus    string // "input" or "results"
+	searchSeq      int    // Incremented on every query change to drop stale results
+	viewFromSearch bool   // Esc in ModeView returns to the search results
 
 	// Storage
 	storage storage.FileSystem
 
+	// Vaults
+	vaults       []config.Vault
+	currentVault string
+	vaultIdx     int
+	openStorage  StorageOpener
+	watcher      *watcher.Watcher
+	openWatcher  WatcherOpener
+
+	// Viewer (ModeView)
+	viewer      viewport.Model
+	viewRaw     bool     // Show the raw markdown instead of the rendered note
+	viewerLines []string // Text of the viewer lines, without styles
+
+	// Links (ModeView, ModeBacklinks)
+	linkIdx     int      // Selected [[link]] of the viewed note, -1 for none
+	navBack     []string // IDs of the notes left by following links, most recent last
+	navForward  []string // IDs of the notes left by going back, most recent last
+	backlinks   []*storage.Note
+	backlinkIdx int
+
+	// Journal (ModeCalendar)
+	journal        storage.Journal
+	calendarDay    time.Time         // Selected day, at midnight
+	journalEntries map[string]string // Titles of the journal entries, by ID
+
+	// Quick open
+	quickInput   textinput.Model
+	quickTitles  *fuzzy.Matcher
+	quickIDs     *fuzzy.Matcher
+	quickMatches []quickOpenMatch
+	quickIdx     int
+
 	// Error handling
 	lastError string
+	retry     tea.Cmd // Runs the failed operation again, when it may succeed
 
 	// UI
 	width  int
@@ -58,12 +119,59 @@ type Model struct {
 	contentEditor textarea.Model
 	creatingNote  *storage.Note
 
-	// Edit mode: "title" or "content"
+	// Creation step: "template", "title", "field" or "content"
 	editMode string
 
+	// Templates (ModeCreate)
+	templates      []*storage.Template // Templates of the vault, offered by n
+	templateIdx    int                 // Selected row of the picker, 0 for a blank note
+	template       *storage.Template   // Template the note being created starts from
+	templateTitle  string
+	templateFields []string          // Fields the template prompts for
+	templateValues map[string]string // Values of the fields filled in so far
+	fieldInput     textinput.Model
+
 	// Edit focus: which component has focus in ModeEdit ("title" or "content")
 	editFocus string
 
+	// Note as it was when editing started, the base of a three-way merge
+	editBase *storage.Note
+
+	// Trash (ModeTrash)
+	trashNotes        []*storage.TrashedNote
+	trashIdx          int
+	trashPurgeConfirm bool
+
+	// Note history (ModeHistory)
+	historyVersions   []*storage.Version
+	historyNotes      []*storage.Note // Content of each version
+	historyIdx        int             // Selected revision
+	historyBase       int             // Revision compared with, -1 for the one before
+	historySideBySide bool
+	historyDiff       viewport.Model
+
+	// Folders (ModeFolders)
+	folders         []string        // Every folder of the vault, sorted
+	folderCollapsed map[string]bool // Folders whose subfolders are hidden
+	folderIdx       int             // Selected row of the tree
+	folderFilter    string          // Folder the notes list is scoped to, "" for all
+	folderAction    string          // "", "create", "rename", "move" or "moveNote"
+	folderMoving    string          // Folder or note ID being moved
+	folderFocus     string          // Folder to select once the folders are reloaded
+	folderInput     textinput.Model
+
+	// Tags (ModeTags)
+	tagCounts []storage.TagCount
+	tagIdx    int    // Selected row of the sidebar, 0 for every note
+	tagFilter string // Tag the notes list is limited to, "" for all
+	tagAction string // "add" or "remove" while naming a tag
+	tagInput  textinput.Model
+	marked    map[string]bool // Notes picked for bulk tagging, by ID
+
+	// Save conflict (ModeConflict)
+	conflictMine   *storage.Note
+	conflictTheirs *storage.Note
+
 	// Sort mode for notes list
 	sortMode SortMode
 
@@ -72,33 +180,97 @@ type Model struct {
 	noteToDelete  *storage.Note
 }
 
+// StorageOpener opens the storage backing a vault directory
+type StorageOpener func(path string) (storage.FileSystem, error)
+
+// Option configures a Model built by NewModel
+type Option func(*Model)
+
+// WithStorage uses fs as the note storage instead of the default ~/.leaf/notes/
+func WithStorage(fs storage.FileSystem) Option {
+	return func(m *Model) {
+		m.storage = fs
+	}
+}
+
+// WithVaults sets the vaults offered by the vault switcher and the name of the active one
+func WithVaults(vaults []config.Vault, current string) Option {
+	return func(m *Model) {
+		m.vaults = vaults
+		m.currentVault = current
+	}
+}
+
+// WithStorageOpener sets how the vault switcher opens the storage of a vault
+func WithStorageOpener(open StorageOpener) Option {
+	return func(m *Model) {
+		m.openStorage = open
+	}
+}
+
+// WithWatcher refreshes notes changed outside the app, watching the active
+// vault with watchers started by open
+func WithWatcher(open WatcherOpener) Option {
+	return func(m *Model) {
+		m.openWatcher = open
+	}
+}
+
 // NewModel creates a new model with initial state
-func NewModel() Model {
-	// Initialize the local filesystem storage
-	fs, err := storage.NewLocalFileSystem()
+// Without WithStorage, notes are stored in the default ~/.leaf/notes/ directory
+func NewModel(opts ...Option) Model {
+	m := Model{
+		mode:            ModeList,
+		notes:           []*storage.Note{},
+		selectedIdx:     0,
+		openStorage:     openLocalStorage,
+		titleInput:      newTitleInput(),
+		contentEditor:   newContentEditor(),
+		searchInput:     newSearchInput(),
+		searchFocus:     "input",
+		quickInput:      newQuickOpenInput(),
+		folderInput:     newFolderInput(),
+		folderCollapsed: map[string]bool{},
+		tagInput:        newTagInput(),
+		fieldInput:      newFieldInput(),
+		marked:          map[string]bool{},
+		viewer:          newViewer(),
+		linkIdx:         -1,
+		journal:         storage.Journal{Template: storage.DefaultJournalTemplate},
+		creatingNote:    nil,
+		editMode:        "title",
+		editFocus:       "content",
+		sortMode:        SortByUpdatedDesc,
+		deleteConfirm:   false,
+		noteToDelete:    nil,
+	}
 
-	var lastErr string
-	if err != nil {
-		// Store the error to display in the UI
-		lastErr = err.Error()
-		fs = nil // Ensure storage is nil on error
+	for _, opt := range opts {
+		opt(&m)
 	}
 
-	return Model{
-		mode:          ModeList,
-		notes:         []*storage.Note{},
-		selectedIdx:   0,
-		storage:       fs,
-		lastError:     lastErr,
-		titleInput:    newTitleInput(),
-		contentEditor: newContentEditor(),
-		creatingNote:  nil,
-		editMode:      "title",
-		editFocus:     "content",
-		sortMode:      SortByUpdatedDesc,
-		deleteConfirm: false,
-		noteToDelete:  nil,
+	if m.storage == nil {
+		// Initialize the local filesystem storage
+		fs, err := storage.NewLocalFileSystem()
+		if err != nil {
+			// Store the error to display in the UI
+			m.lastError = err.Error()
+		} else {
+			m.storage = fs
+		}
 	}
+
+	return m
+}
+
+// openLocalStorage is the default StorageOpener, backed by LocalFileSystem
+func openLocalStorage(path string) (storage.FileSystem, error) {
+	fs, err := storage.NewLocalFileSystemAt(path)
+	if err != nil {
+		// Avoid returning a typed nil inside the interface
+		return nil, err
+	}
+	return fs, nil
 }
 
 // newTitleInput creates a new title input component
@@ -128,7 +300,10 @@ func (m Model) Init() tea.Cmd {
 		return nil
 	}
 
-	// Return a command to load notes at startup
+	// Load notes at startup, and watch them for changes made outside the app
+	if vault, ok := m.vault(m.currentVault); ok && m.openWatcher != nil {
+		return tea.Batch(loadNotesCmd(m.storage), startWatchingCmd(m.openWatcher, vault.Path))
+	}
 	return loadNotesCmd(m.storage)
 }
 
@@ -153,3 +328,43 @@ func (m Model) Storage() storage.FileSystem {
 func (m Model) LastError() string {
 	return m.lastError
 }
+
+// CurrentVault returns the name of the active vault
+func (m Model) CurrentVault() string {
+	return m.currentVault
+}
+
+// SearchResults returns the results of the current search
+func (m Model) SearchResults() []*storage.Note {
+	return m.searchResults
+}
+
+// QueryError returns the parse error of the current search query, if any
+func (m Model) QueryError() *storage.ParseError {
+	return m.queryError
+}
+
+// FolderFilter returns the folder the notes list is scoped to, "" for all notes
+func (m Model) FolderFilter() string {
+	return m.folderFilter
+}
+
+// TagFilter returns the tag the notes list is limited to, "" for all notes
+func (m Model) TagFilter() string {
+	return m.tagFilter
+}
+
+// CurrentNote returns the note being viewed or edited
+func (m Model) CurrentNote() *storage.Note {
+	return m.currentNote
+}
+
+// CalendarDay returns the day selected in ModeCalendar
+func (m Model) CalendarDay() time.Time {
+	return m.calendarDay
+}
+
+// Backlinks returns the notes linking to the viewed note, in ModeBacklinks
+func (m Model) Backlinks() []*storage.Note {
+	return m.backlinks
+}
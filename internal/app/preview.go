@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// splitMinWidth is the narrowest terminal showing the preview next to the list
+	splitMinWidth = 80
+
+	// Bounds of the list pane width in split mode
+	minListPaneWidth = 24
+	maxListPaneWidth = 48
+
+	// Bounds of the side pane width, holding the folder tree or the tags
+	minSidePaneWidth = 16
+	maxSidePaneWidth = 32
+
+	// paneSeparator is drawn between the list and the preview
+	paneSeparator = " │ "
+)
+
+// splitPane reports whether ModeList shows the preview next to the list
+func (m Model) splitPane() bool {
+	return m.width >= splitMinWidth
+}
+
+// listPaneWidth returns the width of the list in split mode, a third of the terminal
+func (m Model) listPaneWidth() int {
+	return min(max(m.width/3, minListPaneWidth), maxListPaneWidth)
+}
+
+// previewPaneWidth returns the width of the preview in split mode
+func (m Model) previewPaneWidth() int {
+	width := m.width - m.listPaneWidth() - lipgloss.Width(paneSeparator)
+	if m.sidePane() {
+		width -= m.sidePaneWidth() + lipgloss.Width(paneSeparator)
+	}
+	return width
+}
+
+// sidePane reports whether the folder tree or the tags are shown next to the notes
+func (m Model) sidePane() bool {
+	return m.mode == ModeFolders || m.mode == ModeTags || (m.splitPane() && len(m.folders) > 0)
+}
+
+// sidePaneWidth returns the width of the side pane in split mode
+func (m Model) sidePaneWidth() int {
+	return min(max(m.width/5, minSidePaneWidth), maxSidePaneWidth)
+}
+
+// renderSidePane displays height lines of the tags while browsing them, of
+// the folder tree otherwise
+func (m Model) renderSidePane(height int) string {
+	if m.mode == ModeTags {
+		return m.renderTagList(height)
+	}
+	return m.renderFolderTree(height)
+}
+
+// refreshPreview renders the selected note for the preview pane
+// Rendering is skipped when the note and the pane width didn't change
+func (m *Model) refreshPreview() {
+	if !m.splitPane() || len(m.notes) == 0 {
+		m.preview = ui.Preview{}
+		m.previewKey = ""
+		return
+	}
+
+	note := m.notes[m.selectedIdx]
+	width := m.previewPaneWidth()
+	key := fmt.Sprintf("%s@%d/%d", note.ID, note.UpdatedAt.UnixNano(), width)
+	if key == m.previewKey {
+		return
+	}
+
+	m.preview = ui.NewPreview(note.Content, width)
+	m.previewKey = key
+}
+
+// renderNotePanes displays the notes list, next to the preview on wide terminals
+// and after the side pane when the vault has folders or tags are browsed
+func (m Model) renderNotePanes() string {
+	height := m.listHeight()
+	offset := listWindow(m.listOffset, m.selectedIdx, height, len(m.notes))
+
+	titles := make([]string, len(m.notes))
+	for i, note := range m.notes {
+		titles[i] = note.Title
+		if m.marked[note.ID] {
+			titles[i] = markedPrefix + note.Title
+		}
+	}
+	list := ui.NoteList{
+		Titles:   titles,
+		Selected: m.selectedIdx,
+		Offset:   offset,
+		Height:   height,
+	}
+
+	if !m.splitPane() {
+		if m.mode == ModeFolders || m.mode == ModeTags {
+			// No room for both, the side pane takes the place of the list
+			return m.renderSidePane(height)
+		}
+		return list.View()
+	}
+
+	list.Width = m.listPaneWidth()
+	left := lipgloss.NewStyle().Height(height).Render(list.View())
+
+	preview := m.preview
+	preview.Height = height
+
+	separator := make([]string, height)
+	for i := range separator {
+		separator[i] = paneSeparator
+	}
+
+	divider := ui.DimStyle.Render(lipgloss.JoinVertical(lipgloss.Left, separator...))
+
+	var panes []string
+	if m.sidePane() {
+		side := lipgloss.NewStyle().Height(height).Render(m.renderSidePane(height))
+		panes = append(panes, side, divider)
+	}
+	panes = append(panes, left, divider, preview.View())
+	return lipgloss.JoinHorizontal(lipgloss.Top, panes...)
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/fuzzy"
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxQuickOpenResults is the number of matches shown in the quick open overlay
+const maxQuickOpenResults = 10
+
+// quickOpenMatch is a note matched by the quick open pattern
+type quickOpenMatch struct {
+	noteIdx   int   // Index in m.notes
+	score     int   // Best of the title and ID scores
+	positions []int // Matched runes in the title (or in the ID when byID)
+	byID      bool
+}
+
+// newQuickOpenInput creates a new quick open input component
+func newQuickOpenInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Go to note"
+	ti.Prompt = "› "
+	ti.CharLimit = 100
+	ti.Width = 50
+	return ti
+}
+
+// enterQuickOpen opens the quick open overlay over the notes list
+func (m Model) enterQuickOpen() (tea.Model, tea.Cmd) {
+	titles := make([]string, len(m.notes))
+	ids := make([]string, len(m.notes))
+	for i, note := range m.notes {
+		titles[i] = note.Title
+		ids[i] = note.ID
+	}
+
+	m.mode = ModeQuickOpen
+	m.quickTitles = fuzzy.NewMatcher(titles)
+	m.quickIDs = fuzzy.NewMatcher(ids)
+	m.quickInput.SetValue("")
+	m.quickInput.Focus()
+	m.quickIdx = 0
+	m.quickMatches = m.findQuickOpenMatches("")
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	return m, nil
+}
+
+// handleQuickOpenMode handles key presses in ModeQuickOpen
+func (m Model) handleQuickOpenMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "ctrl+p":
+		// Close the overlay
+		m.mode = ModeList
+		m.quickInput.Blur()
+		return m, nil
+
+	case "down", "ctrl+n", "ctrl+j":
+		if m.quickIdx < len(m.quickMatches)-1 && m.quickIdx < maxQuickOpenResults-1 {
+			m.quickIdx++
+		}
+		return m, nil
+
+	case "up", "ctrl+k":
+		if m.quickIdx > 0 {
+			m.quickIdx--
+		}
+		return m, nil
+
+	case "enter":
+		// Jump to the selected note
+		if len(m.quickMatches) == 0 {
+			return m, nil
+		}
+		m.selectedIdx = m.quickMatches[m.quickIdx].noteIdx
+		m.followSelection()
+		m.quickInput.Blur()
+		return m.enterViewMode(m.notes[m.selectedIdx]), nil
+	}
+
+	// Delegate to textinput and match again when the pattern changed
+	previous := m.quickInput.Value()
+	var cmd tea.Cmd
+	m.quickInput, cmd = m.quickInput.Update(msg)
+	if pattern := m.quickInput.Value(); pattern != previous {
+		m.quickMatches = m.findQuickOpenMatches(pattern)
+		m.quickIdx = 0
+	}
+	return m, cmd
+}
+
+// findQuickOpenMatches matches pattern against note titles and IDs, best first
+func (m Model) findQuickOpenMatches(pattern string) []quickOpenMatch {
+	if m.quickTitles == nil || m.quickIDs == nil {
+		return nil
+	}
+
+	byNote := make(map[int]*quickOpenMatch)
+	var order []int
+
+	for _, match := range m.quickTitles.Find(pattern) {
+		byNote[match.Index] = &quickOpenMatch{noteIdx: match.Index, score: match.Score, positions: match.Positions}
+		order = append(order, match.Index)
+	}
+
+	// An empty pattern lists every note in list order, titles are enough
+	if pattern != "" {
+		for _, match := range m.quickIDs.Find(pattern) {
+			existing, ok := byNote[match.Index]
+			if ok && existing.score >= match.Score {
+				continue
+			}
+			if !ok {
+				order = append(order, match.Index)
+			}
+			byNote[match.Index] = &quickOpenMatch{noteIdx: match.Index, score: match.Score, positions: match.Positions, byID: true}
+		}
+	}
+
+	matches := make([]quickOpenMatch, len(order))
+	for i, idx := range order {
+		matches[i] = *byNote[idx]
+	}
+
+	// Stable sort keeps the list order among equal scores
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
+
+// renderQuickOpen displays the quick open overlay
+func (m Model) renderQuickOpen() string {
+	var b strings.Builder
+
+	b.WriteString(m.quickInput.View())
+	b.WriteString("\n\n")
+
+	if len(m.quickMatches) == 0 {
+		b.WriteString(ui.DimStyle.Render("No matching notes"))
+		b.WriteString("\n")
+	}
+
+	for i, match := range m.quickMatches {
+		if i >= maxQuickOpenResults {
+			break
+		}
+
+		note := m.notes[match.noteIdx]
+		title, id := note.Title, ui.DimStyle.Render(note.ID)
+		if match.byID {
+			id = highlightPositions(note.ID, match.positions)
+		} else {
+			title = highlightPositions(note.Title, match.positions)
+		}
+
+		prefix := "  "
+		if i == m.quickIdx {
+			prefix = ui.SelectedItemStyle.UnsetPaddingLeft().Render("> ")
+		}
+		b.WriteString(fmt.Sprintf("%s%s  %s\n", prefix, title, id))
+	}
+
+	if len(m.quickMatches) > maxQuickOpenResults {
+		b.WriteString(ui.DimStyle.Render(fmt.Sprintf("… %d more", len(m.quickMatches)-maxQuickOpenResults)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nEnter (open), ↑/↓ (move), Esc (close)")
+
+	box := ui.OverlayStyle.Render(b.String())
+	if m.width == 0 || m.height == 0 {
+		return box
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// highlightPositions renders the runes at positions with the fuzzy match style
+func highlightPositions(text string, positions []int) string {
+	if len(positions) == 0 {
+		return text
+	}
+
+	marked := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		marked[pos] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if marked[i] {
+			b.WriteString(ui.FuzzyMatchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// listHeight returns how many notes fit on screen in ModeList
+// Before the first WindowSizeMsg every note is shown
+func (m Model) listHeight() int {
+	if m.height <= 0 {
+		return max(len(m.notes), 1)
+	}
+
+	// The title, a blank line, then the footer, which grows with prompts,
+	// errors and narrow terminals
+	chrome := lipgloss.Height(m.listHeader()) + 1 + lipgloss.Height(m.listFooter())
+	return max(m.height-chrome, 1)
+}
+
+// wrap breaks the lines of text at the terminal width, so that its height on
+// screen is its number of lines
+func (m Model) wrap(text string) string {
+	if m.width <= 0 {
+		return text
+	}
+	return ansi.Wrap(text, m.width, "")
+}
+
+// truncate cuts a line at the terminal width, so that it takes a single line
+// on screen
+func (m Model) truncate(line string) string {
+	if m.width <= 0 {
+		return line
+	}
+	return ansi.Truncate(line, m.width, "…")
+}
+
+// selectNote moves the list selection to idx, clamped to the notes, and
+// scrolls the list so it stays visible
+func (m *Model) selectNote(idx int) {
+	m.selectedIdx = max(min(idx, len(m.notes)-1), 0)
+	m.deleteConfirm = false // Cancel delete confirmation on navigation
+	m.noteToDelete = nil
+	m.followSelection()
+}
+
+// followSelection scrolls the list so the selected note is visible and
+// refreshes its preview
+func (m *Model) followSelection() {
+	m.listOffset = listWindow(m.listOffset, m.selectedIdx, m.listHeight(), len(m.notes))
+	m.refreshPreview()
+}
+
+// listJump returns how far a paging key moves the list selection
+func (m Model) listJump(key string) int {
+	page := m.listHeight()
+	switch key {
+	case "pgdown":
+		return page
+	case "pgup":
+		return -page
+	case "ctrl+d":
+		return max(page/2, 1)
+	case "ctrl+u":
+		return -max(page/2, 1)
+	case "g", "home":
+		return -len(m.notes)
+	case "G", "end":
+		return len(m.notes)
+	}
+	return 0
+}
+
+// listWindow returns the first visible row of a list of total rows showing
+// height rows at a time, moving offset as little as possible to keep selected in view
+func listWindow(offset, selected, height, total int) int {
+	if selected < offset {
+		offset = selected
+	}
+	if selected >= offset+height {
+		offset = selected - height + 1
+	}
+	// Don't leave empty rows at the bottom when the list shrinks
+	offset = min(offset, total-height)
+	return max(offset, 0)
+}
+
+// positionIndicator formats a position in a list or a note, such as "42/1380"
+func positionIndicator(pos, total int) string {
+	return fmt.Sprintf("%d/%d", pos, total)
+}
+
+// viewerPosition returns the last visible line of the note and its line count
+func (m Model) viewerPosition() (int, int) {
+	total := m.viewer.TotalLineCount()
+	return min(m.viewer.YOffset+m.viewer.Height, total), total
+}
@@ -0,0 +1,415 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// searchDebounce is how long typing must pause before a search runs
+const searchDebounce = 150 * time.Millisecond
+
+// snippetWidth is the number of characters shown around a match
+const snippetWidth = 70
+
+// searchResultRows is how many lines a result takes: its title and its snippet
+const searchResultRows = 2
+
+// searchDebounceMsg fires once typing has paused
+type searchDebounceMsg struct {
+	seq   int
+	query string
+}
+
+// SearchResultsMsg is sent when a search completes
+type SearchResultsMsg struct {
+	Seq   int
+	Query string
+	Notes []*storage.Note
+	Err   error
+}
+
+// newSearchInput creates a new search input component
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Search notes"
+	ti.Prompt = "🔍 "
+	ti.CharLimit = 200
+	ti.Width = 50
+	return ti
+}
+
+// enterSearchMode switches to ModeSearch with an empty query
+func (m Model) enterSearchMode() (tea.Model, tea.Cmd) {
+	m.mode = ModeSearch
+	m.searchQuery = ""
+	m.searchResults = nil
+	m.searchTerms = nil
+	m.queryError = nil
+	m.searchIdx = 0
+	m.searchOffset = 0
+	m.searchFocus = "input"
+	m.searchInput.SetValue("")
+	m.searchInput.Focus()
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	return m, nil
+}
+
+// handleSearchMode handles key presses in ModeSearch
+func (m Model) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		// Return to list
+		m.mode = ModeList
+		m.searchQuery = ""
+		m.searchResults = nil
+		m.queryError = nil
+		m.searchInput.Blur()
+		return m, nil
+
+	case "enter":
+		// Open the selected result
+		if len(m.searchResults) == 0 {
+			return m, nil
+		}
+		m = m.enterViewMode(m.searchResults[m.searchIdx])
+		m.viewFromSearch = true
+		// The error belongs to the query, not to the note
+		m.queryError = nil
+		return m, nil
+
+	case "tab":
+		// Toggle focus between the input and the results
+		if m.searchFocus == "input" {
+			m.searchFocus = "results"
+			m.searchInput.Blur()
+		} else {
+			m.searchFocus = "input"
+			m.searchInput.Focus()
+		}
+		return m, nil
+
+	case "down", "ctrl+n", "ctrl+j":
+		m.moveSearchSelection(1)
+		return m, nil
+
+	case "up", "ctrl+p", "ctrl+k":
+		m.moveSearchSelection(-1)
+		return m, nil
+	}
+
+	// j/k navigate only when the results have focus, otherwise they are typed
+	if m.searchFocus == "results" {
+		switch msg.String() {
+		case "j":
+			m.moveSearchSelection(1)
+		case "k":
+			m.moveSearchSelection(-1)
+		}
+		return m, nil
+	}
+
+	// Delegate to textinput and schedule a search when the query changed
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+
+	query := m.searchInput.Value()
+	if query == m.searchQuery {
+		return m, cmd
+	}
+	m.searchQuery = query
+	m.searchSeq++
+
+	if strings.TrimSpace(query) == "" {
+		m.searchResults = nil
+		m.searchIdx = 0
+		m.searchOffset = 0
+		m.queryError = nil
+		return m, cmd
+	}
+
+	return m, tea.Batch(cmd, searchDebounceCmd(m.searchSeq, query))
+}
+
+// moveSearchSelection moves the selected result by delta, staying in range
+func (m *Model) moveSearchSelection(delta int) {
+	idx := m.searchIdx + delta
+	if idx < 0 || idx >= len(m.searchResults) {
+		return
+	}
+	m.searchIdx = idx
+	m.followSearchSelection()
+}
+
+// followSearchSelection scrolls the results so the selected one is visible
+func (m *Model) followSearchSelection() {
+	m.searchOffset = listWindow(m.searchOffset, m.searchIdx, m.searchHeight(), len(m.searchResults))
+}
+
+// searchHeight returns how many results fit on screen in ModeSearch
+// Before the first WindowSizeMsg every result is shown
+func (m Model) searchHeight() int {
+	if m.height <= 0 {
+		return max(len(m.searchResults), 1)
+	}
+
+	// The input and a blank line, the result count and a blank line, then the
+	// footer; every result takes a line for its title and one for its snippet
+	chrome := lipgloss.Height(m.searchHeader()) + 1 + 2 + lipgloss.Height(m.searchFooter())
+	return max((m.height-chrome)/searchResultRows, 1)
+}
+
+// handleSearchDebounce runs the search if no key was typed since the debounce started
+func (m Model) handleSearchDebounce(msg searchDebounceMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.searchSeq || m.mode != ModeSearch || m.storage == nil {
+		return m, nil
+	}
+	return m, searchNotesCmd(m.storage, msg.seq, m.scopedQuery(msg.query))
+}
+
+// handleSearchResults stores the results of the latest search
+func (m Model) handleSearchResults(msg SearchResultsMsg) (tea.Model, tea.Cmd) {
+	// Drop results of queries that were superseded while running
+	if msg.Seq != m.searchSeq {
+		return m, nil
+	}
+
+	// Point at the problem in malformed queries, keeping the previous results
+	var parseErr *storage.ParseError
+	if errors.As(msg.Err, &parseErr) {
+		m.queryError = parseErr
+		return m, nil
+	}
+
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	m.queryError = nil
+	m.searchResults = msg.Notes
+	m.searchTerms = nil
+	if q, err := storage.ParseQuery(msg.Query); err == nil {
+		m.searchTerms = storage.HighlightTerms(q)
+	}
+	m.searchIdx = 0
+	m.searchOffset = 0
+	return m, nil
+}
+
+// scopedQuery restricts a search query to the folder and the tag the list is
+// limited to
+// Malformed queries are left alone so the search reports where they fail
+func (m Model) scopedQuery(query string) string {
+	if m.folderFilter == "" && m.tagFilter == "" {
+		return query
+	}
+	q, err := storage.ParseQuery(query)
+	if err != nil || q == nil {
+		return query
+	}
+
+	var clauses []storage.Query
+	if m.folderFilter != "" {
+		clauses = append(clauses, &storage.FolderQuery{Folder: m.folderFilter})
+	}
+	if m.tagFilter != "" {
+		clauses = append(clauses, &storage.TagQuery{Tag: m.tagFilter})
+	}
+	scoped := &storage.AndQuery{Clauses: append(clauses, q)}
+	return scoped.String()
+}
+
+// searchDebounceCmd waits for the debounce delay before asking for a search
+func searchDebounceCmd(seq int, query string) tea.Cmd {
+	return tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchDebounceMsg{seq: seq, query: query}
+	})
+}
+
+// searchNotesCmd is a command that searches notes in storage
+// It runs asynchronously and returns a SearchResultsMsg
+func searchNotesCmd(fs storage.FileSystem, seq int, query string) tea.Cmd {
+	return func() tea.Msg {
+		// Use background context for searching notes
+		notes, err := fs.SearchNotes(context.Background(), query)
+		return SearchResultsMsg{
+			Seq:   seq,
+			Query: query,
+			Notes: notes,
+			Err:   err,
+		}
+	}
+}
+
+// renderSearch displays the search interface
+func (m Model) renderSearch() string {
+	var b strings.Builder
+
+	b.WriteString(m.searchHeader())
+	b.WriteString("\n\n")
+
+	terms := m.searchTerms
+
+	switch {
+	case strings.TrimSpace(m.searchQuery) == "":
+		b.WriteString("Type to search titles and content.\n")
+		b.WriteString("Syntax: title:word tag:name created:>2026-01-01 updated:<7d \"a phrase\" /regex/ -exclude AND OR NOT\n")
+	case len(m.searchResults) == 0:
+		b.WriteString("No matching notes.\n")
+	default:
+		b.WriteString(fmt.Sprintf("%d result(s)\n\n", len(m.searchResults)))
+		end := min(m.searchOffset+m.searchHeight(), len(m.searchResults))
+		for i := m.searchOffset; i < end; i++ {
+			note := m.searchResults[i]
+			title := highlightMatches(note.Title, terms, ui.HighlightStyle.Render)
+			if i == m.searchIdx {
+				b.WriteString(m.truncate(ui.SelectedItemStyle.Render("> " + title)))
+			} else {
+				b.WriteString(m.truncate(ui.ListItemStyle.Render("  " + title)))
+			}
+			b.WriteString("\n")
+
+			if snippet := searchSnippet(note.Content, terms, snippetWidth); snippet != "" {
+				b.WriteString(m.truncate(ui.SnippetStyle.Render(highlightMatches(snippet, terms, ui.HighlightStyle.Render))))
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	b.WriteString(m.searchFooter())
+
+	return b.String()
+}
+
+// searchHeader renders the query input of ModeSearch, wrapped to the terminal
+func (m Model) searchHeader() string {
+	header := m.searchInput.View()
+	if m.folderFilter != "" {
+		header += ui.DimStyle.Render("  in 📁 " + m.folderFilter)
+	}
+	return m.wrap(header)
+}
+
+// searchFooter renders what follows the results in ModeSearch: position,
+// shortcuts and errors, wrapped to the terminal
+func (m Model) searchFooter() string {
+	var b strings.Builder
+
+	if len(m.searchResults) > 0 {
+		b.WriteString("\n" + positionIndicator(m.searchIdx+1, len(m.searchResults)))
+	}
+	b.WriteString("\nShortcuts: ↑/↓ (move), Tab (focus results, then j/k), Enter (open), Esc (cancel)")
+	b.WriteString(m.renderError())
+
+	return m.wrap(b.String())
+}
+
+// searchSnippet returns a single-line excerpt of content centered on the first term match
+// Content without any match yields its beginning
+func searchSnippet(content string, terms []string, width int) string {
+	// Flatten the content on a single line
+	flat := []rune(strings.Join(strings.Fields(content), " "))
+	if len(flat) == 0 {
+		return ""
+	}
+
+	start := 0
+	if ranges := matchRanges(flat, terms); len(ranges) > 0 {
+		// Keep a bit of leading context before the first match
+		start = ranges[0][0] - width/4
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	end := start + width
+	if end > len(flat) {
+		end = len(flat)
+		start = end - width
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	snippet := string(flat[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(flat) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// highlightMatches wraps every case-insensitive occurrence of a term with render
+func highlightMatches(text string, terms []string, render func(...string) string) string {
+	runes := []rune(text)
+	ranges := matchRanges(runes, terms)
+	if len(ranges) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		b.WriteString(string(runes[last:r[0]]))
+		b.WriteString(render(string(runes[r[0]:r[1]])))
+		last = r[1]
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
+
+// matchRanges returns the sorted, non-overlapping rune ranges where a term occurs in text
+func matchRanges(text []rune, terms []string) [][2]int {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var ranges [][2]int
+	for i := 0; i < len(lower); {
+		matched := 0
+		for _, term := range terms {
+			needle := []rune(strings.ToLower(term))
+			if len(needle) > matched && hasRunePrefix(lower[i:], needle) {
+				matched = len(needle)
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		ranges = append(ranges, [2]int{i, i + matched})
+		i += matched
+	}
+	return ranges
+}
+
+// hasRunePrefix reports whether s starts with prefix
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) == 0 || len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
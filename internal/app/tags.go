@@ -0,0 +1,266 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// markedPrefix is shown before the notes picked for bulk tagging
+const markedPrefix = "● "
+
+// tagsLoadedMsg is sent when the tags of the vault are loaded
+type tagsLoadedMsg struct {
+	tags []storage.TagCount
+	err  error
+}
+
+// NotesTaggedMsg is sent when a tag was added to or removed from notes
+type NotesTaggedMsg struct {
+	Changed int // Notes that were saved
+	Err     error
+}
+
+// newTagInput creates the input used to name the tag of a bulk change
+func newTagInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "tag"
+	ti.CharLimit = 100
+	ti.Width = 30
+	return ti
+}
+
+// enterTagsMode opens the tag sidebar, with the current tag selected
+func (m Model) enterTagsMode() (tea.Model, tea.Cmd) {
+	index, ok := m.storage.(storage.TagIndex)
+	if !ok {
+		m.lastError = "this vault has no tag index"
+		return m, nil
+	}
+
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	m.mode = ModeTags
+	m.followSelection()
+	return m, loadTagsCmd(index)
+}
+
+// handleTagsMode handles key presses in ModeTags
+func (m Model) handleTagsMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.mode = ModeList
+		m.followSelection()
+		return m, nil
+
+	case "j", "down":
+		// Row 0 shows every note, the tags follow
+		if m.tagIdx < len(m.tagCounts) {
+			m.tagIdx++
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.tagIdx > 0 {
+			m.tagIdx--
+		}
+		return m, nil
+
+	case "enter":
+		// Only list the notes carrying the tag
+		m.tagFilter = ""
+		if m.tagIdx > 0 && m.tagIdx <= len(m.tagCounts) {
+			m.tagFilter = m.tagCounts[m.tagIdx-1].Tag
+		}
+		m.mode = ModeList
+		m.selectedIdx = 0
+		m.listOffset = 0
+		return m, loadNotesCmd(m.storage)
+	}
+
+	return m, nil
+}
+
+// startTagging asks for the tag to add to or remove from the marked notes,
+// or the selected one when none is marked
+func (m Model) startTagging(action string) (tea.Model, tea.Cmd) {
+	if len(m.tagTargets()) == 0 {
+		return m, nil
+	}
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	m.tagAction = action
+	m.tagInput.SetValue("")
+	m.tagInput.Focus()
+	return m, textinput.Blink
+}
+
+// handleTagPrompt handles key presses while naming the tag of a bulk change
+func (m Model) handleTagPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.tagAction = ""
+		m.tagInput.Blur()
+		return m, nil
+
+	case "enter":
+		tag := strings.TrimPrefix(strings.TrimSpace(m.tagInput.Value()), "#")
+		if tag == "" {
+			return m, nil
+		}
+		add := m.tagAction == "add"
+		m.tagAction = ""
+		m.tagInput.Blur()
+		return m, tagNotesCmd(m.storage, m.tagTargets(), tag, add)
+	}
+
+	var cmd tea.Cmd
+	m.tagInput, cmd = m.tagInput.Update(msg)
+	return m, cmd
+}
+
+// toggleMark marks or unmarks the selected note for bulk tagging and moves
+// to the next one
+func (m *Model) toggleMark() {
+	id := m.notes[m.selectedIdx].ID
+	if m.marked[id] {
+		delete(m.marked, id)
+	} else {
+		m.marked[id] = true
+	}
+	m.selectNote(m.selectedIdx + 1)
+}
+
+// tagTargets returns the IDs of the marked notes in list order, or the ID of
+// the selected note when none is marked
+func (m Model) tagTargets() []string {
+	var ids []string
+	for _, note := range m.notes {
+		if m.marked[note.ID] {
+			ids = append(ids, note.ID)
+		}
+	}
+	if len(ids) == 0 && m.selectedIdx < len(m.notes) {
+		ids = append(ids, m.notes[m.selectedIdx].ID)
+	}
+	return ids
+}
+
+// loadTagsCmd loads the tags of the vault with their counts
+func loadTagsCmd(index storage.TagIndex) tea.Cmd {
+	return func() tea.Msg {
+		tags, err := index.ListTags(context.Background())
+		return tagsLoadedMsg{tags: tags, err: err}
+	}
+}
+
+// tagNotesCmd adds tag to the notes ids, or removes it from them
+// Notes are read again first so changes made since the list was loaded are kept
+func tagNotesCmd(fs storage.FileSystem, ids []string, tag string, add bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		changed := 0
+		for _, id := range ids {
+			note, err := fs.GetNote(ctx, id)
+			if err != nil {
+				return NotesTaggedMsg{Changed: changed, Err: err}
+			}
+
+			updated := note.RemoveTag(tag)
+			if add {
+				updated = note.AddTag(tag)
+			}
+			if !updated {
+				continue
+			}
+			if err := fs.SaveNote(ctx, note); err != nil {
+				return NotesTaggedMsg{Changed: changed, Err: err}
+			}
+			changed++
+		}
+		return NotesTaggedMsg{Changed: changed}
+	}
+}
+
+// handleTagsLoaded shows the tags in the sidebar
+func (m Model) handleTagsLoaded(msg tagsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	m.tagCounts = msg.tags
+	m.tagIdx = 0
+	for i, tag := range m.tagCounts {
+		if strings.EqualFold(tag.Tag, m.tagFilter) {
+			m.tagIdx = i + 1
+			break
+		}
+	}
+	return m, nil
+}
+
+// handleNotesTagged clears the marks and reloads the notes and their tags
+func (m Model) handleNotesTagged(msg NotesTaggedMsg) (tea.Model, tea.Cmd) {
+	cmds := []tea.Cmd{loadNotesCmd(m.storage)}
+	if index, ok := m.storage.(storage.TagIndex); ok && m.mode == ModeTags {
+		cmds = append(cmds, loadTagsCmd(index))
+	}
+
+	if msg.Err != nil {
+		// Some notes may have changed before the failure
+		m.lastError = msg.Err.Error()
+		return m, tea.Batch(cmds...)
+	}
+
+	m.lastError = ""
+	clear(m.marked)
+	return m, tea.Batch(cmds...)
+}
+
+// renderTagList displays height lines of the tag sidebar
+func (m Model) renderTagList(height int) string {
+	labels := make([]string, 0, len(m.tagCounts)+1)
+	labels = append(labels, "All notes")
+	for _, tag := range m.tagCounts {
+		labels = append(labels, fmt.Sprintf("#%s %s", tag.Tag, ui.DimStyle.Render(fmt.Sprintf("(%d)", tag.Count))))
+	}
+
+	list := ui.NoteList{
+		Titles:   labels,
+		Selected: m.tagIdx,
+		Offset:   listWindow(0, m.tagIdx, height, len(labels)),
+		Height:   height,
+	}
+	if m.splitPane() {
+		list.Width = m.sidePaneWidth()
+	}
+	return list.View()
+}
+
+// renderTagPrompt displays the input naming the tag of a bulk change
+func (m Model) renderTagPrompt() string {
+	if m.tagAction == "" {
+		return ""
+	}
+	count := len(m.tagTargets())
+	notes := "note"
+	if count > 1 {
+		notes = "notes"
+	}
+	if m.tagAction == "add" {
+		return fmt.Sprintf("\nAdd tag to %d %s: %s", count, notes, m.tagInput.View())
+	}
+	return fmt.Sprintf("\nRemove tag from %d %s: %s", count, notes, m.tagInput.View())
+}
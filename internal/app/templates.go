@@ -0,0 +1,227 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// templatesLoadedMsg is sent when the templates of the vault are loaded
+type templatesLoadedMsg struct {
+	templates []*storage.Template
+	err       error
+}
+
+// newFieldInput creates the input of the template fields prompted for
+func newFieldInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 50
+	return ti
+}
+
+// loadTemplatesCmd loads the templates of the vault, when it offers some
+func loadTemplatesCmd(fs storage.FileSystem) tea.Cmd {
+	templates, ok := fs.(storage.Templates)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		list, err := templates.ListTemplates(context.Background())
+		return templatesLoadedMsg{templates: list, err: err}
+	}
+}
+
+// handleTemplatesLoaded keeps the templates offered when creating a note
+func (m Model) handleTemplatesLoaded(msg templatesLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+	m.templates = msg.templates
+	return m, nil
+}
+
+// handleTemplatePicker handles key presses while picking the template of a
+// new note, the first row being a blank note
+func (m Model) handleTemplatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeList
+		m.editMode = "title"
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "j", "down":
+		if m.templateIdx < len(m.templates) {
+			m.templateIdx++
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.templateIdx > 0 {
+			m.templateIdx--
+		}
+		return m, nil
+
+	case "enter":
+		m.template = nil
+		m.titleInput.SetValue("")
+		if m.templateIdx > 0 && m.templateIdx <= len(m.templates) {
+			tmpl := m.templates[m.templateIdx-1]
+			// The template may suggest a title, such as "Standup {{.Date}}"
+			note, _, err := tmpl.Render(storage.NewTemplateData(""))
+			if err != nil {
+				m.lastError = err.Error()
+				return m, nil
+			}
+			m.template = tmpl
+			m.titleInput.SetValue(note.Title)
+			m.titleInput.CursorEnd()
+		}
+		m.lastError = ""
+		m.editMode = "title"
+		m.titleInput.Focus()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// startTemplate asks for the fields the template of the new note prompts
+// for, then fills it in
+func (m Model) startTemplate(title string) (tea.Model, tea.Cmd) {
+	fields, err := m.template.Fields()
+	if err != nil {
+		m.lastError = err.Error()
+		return m, nil
+	}
+
+	m.templateTitle = title
+	m.templateFields = fields
+	m.templateValues = map[string]string{}
+	if len(fields) == 0 {
+		return m.fillTemplate()
+	}
+
+	m.editMode = "field"
+	m.titleInput.Blur()
+	m.promptField(fields[0])
+	return m, nil
+}
+
+// promptField asks for the value of a template field
+func (m *Model) promptField(name string) {
+	m.fieldInput.SetValue(m.templateValues[name])
+	m.fieldInput.Placeholder = name
+	m.fieldInput.Focus()
+}
+
+// handleFieldPrompt handles key presses while filling in the fields of a template
+func (m Model) handleFieldPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Back to the title
+		m.editMode = "title"
+		m.fieldInput.Blur()
+		m.titleInput.Focus()
+		return m, nil
+
+	case "enter":
+		field := m.templateFields[len(m.templateValues)]
+		m.templateValues[field] = m.fieldInput.Value()
+		if len(m.templateValues) < len(m.templateFields) {
+			m.promptField(m.templateFields[len(m.templateValues)])
+			return m, nil
+		}
+		m.fieldInput.Blur()
+		return m.fillTemplate()
+
+	default:
+		var cmd tea.Cmd
+		m.fieldInput, cmd = m.fieldInput.Update(msg)
+		return m, cmd
+	}
+}
+
+// fillTemplate renders the template into the note being created and moves
+// on to its content, with the cursor where the template puts it
+func (m Model) fillTemplate() (tea.Model, tea.Cmd) {
+	data := storage.NewTemplateData(m.templateTitle)
+	data.Fields = m.templateValues
+	note, cursor, err := m.template.Render(data)
+	if err != nil {
+		m.lastError = err.Error()
+		m.editMode = "title"
+		m.titleInput.Focus()
+		return m, nil
+	}
+
+	// New notes go to the folder shown, with the tag shown
+	note.Folder = m.folderFilter
+	if m.tagFilter != "" {
+		note.AddTag(m.tagFilter)
+	}
+	m.creatingNote = note
+	m.lastError = ""
+
+	m.editMode = "content"
+	m.titleInput.Blur()
+	m.contentEditor.SetValue(note.Content)
+	m.moveEditorCursor(note.Content, cursor)
+	m.contentEditor.Focus()
+	return m, nil
+}
+
+// moveEditorCursor puts the cursor of the content editor, which holds
+// content, at byte offset, leaving it at the end when offset is negative
+func (m *Model) moveEditorCursor(content string, offset int) {
+	if offset < 0 || offset > len(content) {
+		return
+	}
+	before := content[:offset]
+	row := strings.Count(before, "\n")
+	col := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:])
+
+	for m.contentEditor.Line() > row {
+		m.contentEditor.CursorUp()
+	}
+	m.contentEditor.SetCursor(col)
+}
+
+// renderTemplatePicker displays the templates a new note can start from
+func (m Model) renderTemplatePicker() string {
+	var b strings.Builder
+	b.WriteString("Template:\n")
+	for i := 0; i <= len(m.templates); i++ {
+		prefix := "  "
+		if i == m.templateIdx {
+			prefix = "> "
+		}
+		name := "Blank note"
+		if i > 0 {
+			name = m.templates[i-1].Name
+		}
+		b.WriteString(prefix + name + "\n")
+	}
+	b.WriteString("\nShortcuts: j/k (move), Enter (choose), Esc (cancel)")
+	return b.String()
+}
+
+// renderFieldPrompt displays the template field being filled in
+func (m Model) renderFieldPrompt() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Title: %s\n\n", m.templateTitle))
+	field := m.templateFields[len(m.templateValues)]
+	b.WriteString(fmt.Sprintf("%s: %s\n", field, positionIndicator(len(m.templateValues)+1, len(m.templateFields))))
+	b.WriteString(m.fieldInput.View())
+	b.WriteString("\n\nShortcuts: Enter (next), Esc (back to title)")
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trashLoadedMsg is sent when the trashed notes are loaded
+type trashLoadedMsg struct {
+	notes []*storage.TrashedNote
+	err   error
+}
+
+// NoteRestoredMsg is sent when a note is restored from the trash
+type NoteRestoredMsg struct {
+	Note *storage.Note
+	Err  error
+}
+
+// notePurgedMsg is sent when a trashed note is removed for good
+type notePurgedMsg struct {
+	trashID string
+	err     error
+}
+
+// enterTrashMode opens the trash of the current vault
+func (m Model) enterTrashMode() (tea.Model, tea.Cmd) {
+	trash, ok := m.storage.(storage.Trash)
+	if !ok {
+		m.lastError = "this vault has no trash"
+		return m, nil
+	}
+
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	m.mode = ModeTrash
+	m.trashIdx = 0
+	m.trashPurgeConfirm = false
+	return m, loadTrashCmd(trash)
+}
+
+// handleTrashMode handles key presses in ModeTrash
+func (m Model) handleTrashMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	trash, _ := m.storage.(storage.Trash)
+	key := msg.String()
+
+	// Any other key cancels a purge confirmation
+	confirming := m.trashPurgeConfirm
+	m.trashPurgeConfirm = false
+
+	switch key {
+	case "esc":
+		if confirming {
+			return m, nil
+		}
+		m.mode = ModeList
+		m.trashNotes = nil
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "j", "down":
+		if m.trashIdx < len(m.trashNotes)-1 {
+			m.trashIdx++
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.trashIdx > 0 {
+			m.trashIdx--
+		}
+		return m, nil
+	}
+
+	if m.trashIdx >= len(m.trashNotes) || trash == nil {
+		return m, nil
+	}
+	selected := m.trashNotes[m.trashIdx]
+
+	switch key {
+	case "r", "enter":
+		// Put the note back in the list
+		return m, restoreNoteCmd(trash, selected.TrashID)
+
+	case "x":
+		// Purge for good, after a second press
+		if !confirming {
+			m.trashPurgeConfirm = true
+			return m, nil
+		}
+		return m, purgeNoteCmd(trash, selected.TrashID)
+	}
+
+	return m, nil
+}
+
+// loadTrashCmd loads the trashed notes
+func loadTrashCmd(trash storage.Trash) tea.Cmd {
+	return func() tea.Msg {
+		notes, err := trash.ListTrash(context.Background())
+		return trashLoadedMsg{notes: notes, err: err}
+	}
+}
+
+// restoreNoteCmd puts a trashed note back
+func restoreNoteCmd(trash storage.Trash, trashID string) tea.Cmd {
+	return func() tea.Msg {
+		note, err := trash.RestoreNote(context.Background(), trashID)
+		return NoteRestoredMsg{Note: note, Err: err}
+	}
+}
+
+// purgeNoteCmd removes a trashed note for good
+func purgeNoteCmd(trash storage.Trash, trashID string) tea.Cmd {
+	return func() tea.Msg {
+		err := trash.PurgeNote(context.Background(), trashID)
+		return notePurgedMsg{trashID: trashID, err: err}
+	}
+}
+
+// handleTrashLoaded shows the trashed notes
+func (m Model) handleTrashLoaded(msg trashLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+	m.trashNotes = msg.notes
+	m.trashIdx = max(min(m.trashIdx, len(m.trashNotes)-1), 0)
+	return m, nil
+}
+
+// handleNoteRestored refreshes the trash and the notes list
+func (m Model) handleNoteRestored(msg NoteRestoredMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	cmds := []tea.Cmd{loadNotesCmd(m.storage)}
+	if trash, ok := m.storage.(storage.Trash); ok {
+		cmds = append(cmds, loadTrashCmd(trash))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleNotePurged refreshes the trash
+func (m Model) handleNotePurged(msg notePurgedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.lastError = msg.err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	trash, ok := m.storage.(storage.Trash)
+	if !ok {
+		return m, nil
+	}
+	return m, loadTrashCmd(trash)
+}
+
+// renderTrash displays the trashed notes
+func (m Model) renderTrash() string {
+	var b strings.Builder
+
+	b.WriteString("🗑️  Trash\n\n")
+
+	if len(m.trashNotes) == 0 {
+		b.WriteString("The trash is empty.\n")
+	} else {
+		for i, note := range m.trashNotes {
+			prefix := "  "
+			if i == m.trashIdx {
+				prefix = "> "
+			}
+			deleted := ui.DimStyle.Render("deleted " + note.DeletedAt.Format("2006-01-02 15:04"))
+			b.WriteString(fmt.Sprintf("%s%s  %s\n", prefix, note.Title, deleted))
+		}
+	}
+
+	b.WriteString("\nShortcuts: j/k (move), r/Enter (restore), x (purge), Esc (back to list)")
+	if m.trashPurgeConfirm && m.trashIdx < len(m.trashNotes) {
+		b.WriteString(fmt.Sprintf("\n⚠️  Press 'x' again to delete '%s' for good (Esc to cancel)", m.trashNotes[m.trashIdx].Title))
+	}
+	b.WriteString(m.renderError())
+
+	return b.String()
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"strings"
 
@@ -45,42 +46,145 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		// Rewrap the note being viewed for the new width
+		m.refreshViewer()
+		if m.mode == ModeHistory {
+			m.refreshHistoryDiff()
+		}
+		m.followSelection()
+		m.followSearchSelection()
 		return m, nil
 
 	case NoteLoadedMsg:
 		if msg.Err != nil {
 			// Store error message to display in view
-			m.lastError = msg.Err.Error()
+			m.storageFailed(msg.Err, loadNotesCmd(m.storage))
 			return m, nil
 		}
 		// Clear any previous error and store loaded notes
 		m.lastError = ""
-		m.notes = msg.Notes
+		m.retry = nil
+		m.notes = make([]*storage.Note, 0, len(msg.Notes))
+		for _, note := range msg.Notes {
+			if m.listed(note) {
+				m.notes = append(m.notes, note)
+			}
+		}
 		m.sortNotes() // Apply current sort mode
-		return m, nil
+		// Keep the selection on a note when the list shrinks
+		m.selectedIdx = max(min(m.selectedIdx, len(m.notes)-1), 0)
+		m.followSelection()
+		// Notes may have been added to new folders, templates too
+		return m, tea.Batch(loadFoldersCmd(m.storage), loadTemplatesCmd(m.storage))
 
 	case NoteSavedMsg:
+		if errors.Is(msg.Err, storage.ErrConflict) {
+			// Someone else changed the note, let the user decide what to keep
+			return m, loadConflictCmd(m.storage, msg.Note)
+		}
 		if msg.Err != nil {
 			// Store error message to display in view
-			m.lastError = msg.Err.Error()
+			m.storageFailed(msg.Err, saveNoteCmd(m.storage, msg.Note))
 			return m, nil
 		}
 		// Clear any previous error and reload notes to show the new one
 		m.lastError = ""
+		m.retry = nil
 		m.creatingNote = nil
+		if m.editBase != nil && m.editBase.ID == msg.Note.ID {
+			m.editBase = nil
+		}
 		m.sortNotes() // Apply current sort mode after saving
 		return m, loadNotesCmd(m.storage)
 
+	case searchDebounceMsg:
+		return m.handleSearchDebounce(msg)
+
+	case SearchResultsMsg:
+		return m.handleSearchResults(msg)
+
+	case VaultOpenedMsg:
+		return m.handleVaultOpened(msg)
+
+	case NoteChangedMsg:
+		return m.handleNoteChanged(msg)
+
+	case NoteRemovedMsg:
+		return m.handleNoteRemoved(msg)
+
+	case watcherStartedMsg:
+		return m.handleWatcherStarted(msg)
+
+	case notesRescanMsg:
+		return m, tea.Batch(loadNotesCmd(m.storage), watchCmd(m.watcher, m.storage))
+
+	case trashLoadedMsg:
+		return m.handleTrashLoaded(msg)
+
+	case NoteRestoredMsg:
+		return m.handleNoteRestored(msg)
+
+	case notePurgedMsg:
+		return m.handleNotePurged(msg)
+
+	case tagsLoadedMsg:
+		return m.handleTagsLoaded(msg)
+
+	case NotesTaggedMsg:
+		return m.handleNotesTagged(msg)
+
+	case foldersLoadedMsg:
+		return m.handleFoldersLoaded(msg)
+
+	case templatesLoadedMsg:
+		return m.handleTemplatesLoaded(msg)
+
+	case folderChangedMsg:
+		return m.handleFolderChanged(msg)
+
+	case NoteMovedMsg:
+		return m.handleNoteMoved(msg)
+
+	case linkFollowedMsg:
+		return m.handleLinkFollowed(msg)
+
+	case backlinksLoadedMsg:
+		return m.handleBacklinksLoaded(msg)
+
+	case journalOpenedMsg:
+		return m.handleJournalOpened(msg)
+
+	case journalLoadedMsg:
+		return m.handleJournalLoaded(msg)
+
+	case historyLoadedMsg:
+		return m.handleHistoryLoaded(msg)
+
+	case conflictMsg:
+		return m.handleConflict(msg)
+
+	case editorClosedMsg:
+		return m.handleEditorClosed(msg)
+
+	case ExternalEditMsg:
+		return m.handleExternalEdit(msg)
+
 	case NoteDeletedMsg:
+		m.deleteConfirm = false
+		m.noteToDelete = nil
+		if errors.Is(msg.Err, storage.ErrNotFound) {
+			// Already gone, which is what the user wanted
+			m.dropNote(msg.NoteID)
+			return m, nil
+		}
 		if msg.Err != nil {
 			// Store error message to display in view
-			m.lastError = msg.Err.Error()
-			m.deleteConfirm = false
-			m.noteToDelete = nil
+			m.storageFailed(msg.Err, deleteNoteCmd(m.storage, msg.NoteID))
 			return m, nil
 		}
 		// Clear any previous error and reload notes
 		m.lastError = ""
+		m.retry = nil
 		m.deleteConfirm = false
 		m.noteToDelete = nil
 		return m, loadNotesCmd(m.storage)
@@ -107,10 +211,71 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleViewMode(msg)
 	}
 
+	// Special handling for ModeSearch: incremental search
+	if m.mode == ModeSearch {
+		return m.handleSearchMode(msg)
+	}
+
+	// Special handling for ModeVaults: vault switcher
+	if m.mode == ModeVaults {
+		return m.handleVaultsMode(msg)
+	}
+
+	// Special handling for ModeQuickOpen: fuzzy note finder
+	if m.mode == ModeQuickOpen {
+		return m.handleQuickOpenMode(msg)
+	}
+
+	// Special handling for ModeConflict: save conflict resolution
+	if m.mode == ModeConflict {
+		return m.handleConflictMode(msg)
+	}
+
+	// Special handling for ModeTrash: deleted notes
+	if m.mode == ModeTrash {
+		return m.handleTrashMode(msg)
+	}
+
+	// Special handling for ModeHistory: versions of a note
+	if m.mode == ModeHistory {
+		return m.handleHistoryMode(msg)
+	}
+
+	// Special handling for ModeFolders: folder tree
+	if m.mode == ModeFolders {
+		return m.handleFoldersMode(msg)
+	}
+
+	// Special handling for ModeBacklinks: notes linking to the viewed one
+	if m.mode == ModeBacklinks {
+		return m.handleBacklinksMode(msg)
+	}
+
+	// Special handling for ModeCalendar: journal entries by day
+	if m.mode == ModeCalendar {
+		return m.handleCalendarMode(msg)
+	}
+
+	// Special handling for ModeTags: tag sidebar
+	if m.mode == ModeTags {
+		return m.handleTagsMode(msg)
+	}
+
+	// Naming the tag to add to or remove from notes
+	if m.tagAction != "" {
+		return m.handleTagPrompt(msg)
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "q":
 		return m, tea.Quit
 
+	case "R":
+		// Retry the storage operation that failed
+		if m.mode == ModeList && m.retry != nil {
+			return m.retryFailed()
+		}
+
 	case "n":
 		// Create a new note
 		if m.mode == ModeList {
@@ -121,45 +286,116 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.contentEditor.SetValue("") // Reset content
 			m.contentEditor.Blur()       // Blur content editor
 			m.creatingNote = nil         // Clear any previous note
+			m.template = nil
+			if len(m.templates) > 0 {
+				// Pick a template before naming the note
+				m.editMode = "template"
+				m.templateIdx = 0
+				m.titleInput.Blur()
+			}
 			return m, nil
 		}
 
 	case "r":
 		// View selected note (read-only)
 		if m.mode == ModeList && len(m.notes) > 0 {
-			m.mode = ModeView
-			m.currentNote = m.notes[m.selectedIdx]
-			return m, nil
+			return m.enterViewMode(m.notes[m.selectedIdx]), nil
 		}
 
 	case "e":
 		// Edit selected note
 		if m.mode == ModeList && len(m.notes) > 0 {
-			m.mode = ModeEdit
-			m.currentNote = m.notes[m.selectedIdx]
-			// Load note title and content into editors
-			m.titleInput.SetValue(m.currentNote.Title)
-			m.contentEditor.SetValue(m.currentNote.Content)
-			// Start with content focused
-			m.editFocus = "content"
-			m.titleInput.Blur()
-			m.contentEditor.Focus()
+			m.startEditing(m.notes[m.selectedIdx])
 			return m, nil
 		}
 
+	case "E":
+		// Edit selected note in $VISUAL/$EDITOR
+		if m.mode == ModeList && len(m.notes) > 0 {
+			return m.openInEditor(m.notes[m.selectedIdx])
+		}
+
 	case "/":
 		// Activate search
 		if m.mode == ModeList {
-			m.mode = ModeSearch
-			m.searchQuery = ""
+			return m.enterSearchMode()
+		}
+
+	case "ctrl+p":
+		// Open the quick open overlay
+		if m.mode == ModeList {
+			return m.enterQuickOpen()
+		}
+
+	case "v":
+		// Open the vault switcher
+		if m.mode == ModeList {
+			m.deleteConfirm = false // Cancel delete confirmation
+			m.noteToDelete = nil
+			return m.enterVaultsMode()
+		}
+
+	case "f":
+		// Browse the folders
+		if m.mode == ModeList {
+			return m.enterFoldersMode("")
+		}
+
+	case "M":
+		// Move the selected note to another folder
+		if m.mode == ModeList && len(m.notes) > 0 {
+			return m.enterFoldersMode("moveNote")
+		}
+
+	case "#":
+		// Browse the tags
+		if m.mode == ModeList {
+			return m.enterTagsMode()
+		}
+
+	case " ":
+		// Mark the selected note for bulk tagging
+		if m.mode == ModeList && len(m.notes) > 0 {
+			m.toggleMark()
 			return m, nil
 		}
 
+	case "+":
+		// Tag the marked notes
+		if m.mode == ModeList {
+			return m.startTagging("add")
+		}
+
+	case "-":
+		// Untag the marked notes
+		if m.mode == ModeList {
+			return m.startTagging("remove")
+		}
+
+	case "J":
+		// Open today's journal entry, creating it if needed
+		if m.mode == ModeList {
+			return m, openJournalCmd(m.storage, m.journal, today())
+		}
+
+	case "c":
+		// Browse the journal by day
+		if m.mode == ModeList {
+			return m.enterCalendarMode()
+		}
+
+	case "T":
+		// Browse the deleted notes
+		if m.mode == ModeList {
+			return m.enterTrashMode()
+		}
+
 	case "t":
 		// Cycle through sort modes
 		if m.mode == ModeList {
 			m.sortMode = (m.sortMode + 1) % 6 // Cycle through 6 sort modes
 			m.sortNotes()
+			m.followSelection()
 			m.deleteConfirm = false // Cancel delete confirmation
 			m.noteToDelete = nil
 			return m, nil
@@ -191,6 +427,11 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.noteToDelete = nil
 			return m, nil
 		}
+		// Clear the marks
+		if m.mode == ModeList && len(m.marked) > 0 {
+			clear(m.marked)
+			return m, nil
+		}
 		// Return to list
 		if m.mode == ModeView || m.mode == ModeEdit || m.mode == ModeSearch || m.mode == ModeCreate {
 			m.mode = ModeList
@@ -202,18 +443,21 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "j", "down":
 		// Navigate down in list
 		if m.mode == ModeList && m.selectedIdx < len(m.notes)-1 {
-			m.selectedIdx++
-			m.deleteConfirm = false // Cancel delete confirmation on navigation
-			m.noteToDelete = nil
+			m.selectNote(m.selectedIdx + 1)
 			return m, nil
 		}
 
 	case "k", "up":
 		// Navigate up in list
 		if m.mode == ModeList && m.selectedIdx > 0 {
-			m.selectedIdx--
-			m.deleteConfirm = false // Cancel delete confirmation on navigation
-			m.noteToDelete = nil
+			m.selectNote(m.selectedIdx - 1)
+			return m, nil
+		}
+
+	case "pgdown", "pgup", "ctrl+d", "ctrl+u", "g", "home", "G", "end":
+		// Page through the list
+		if m.mode == ModeList && len(m.notes) > 0 {
+			m.selectNote(m.selectedIdx + m.listJump(msg.String()))
 			return m, nil
 		}
 	}
@@ -223,6 +467,13 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleCreateMode handles key presses in ModeCreate
 func (m Model) handleCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.editMode {
+	case "template":
+		return m.handleTemplatePicker(msg)
+	case "field":
+		return m.handleFieldPrompt(msg)
+	}
+
 	// If we're editing title
 	if m.editMode == "title" {
 		switch msg.String() {
@@ -243,11 +494,24 @@ func (m Model) handleCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+			if m.creatingNote != nil {
+				// Back from the content: keep what the note was given
+				m.creatingNote.Title = title
+			} else if m.template != nil {
+				return m.startTemplate(title)
+			} else {
+				m.creatingNote = storage.NewNote(title, "")
+				// New notes go to the folder shown, with the tag shown
+				m.creatingNote.Folder = m.folderFilter
+				if m.tagFilter != "" {
+					m.creatingNote.Tags = []string{m.tagFilter}
+				}
+			}
+
 			// Switch to content editing mode
 			m.editMode = "content"
 			m.titleInput.Blur()
 			m.contentEditor.Focus()
-			m.creatingNote = storage.NewNote(title, "")
 			return m, nil
 
 		default:
@@ -301,6 +565,13 @@ func (m Model) handleCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleViewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
+		// Return to the search results if the note was opened from there
+		if m.viewFromSearch {
+			m.viewFromSearch = false
+			m.mode = ModeSearch
+			m.currentNote = nil
+			return m, nil
+		}
 		// Return to list
 		m.mode = ModeList
 		m.currentNote = nil
@@ -311,18 +582,71 @@ func (m Model) handleViewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.currentNote == nil {
 			return m, nil
 		}
-		m.mode = ModeEdit
-		// Load note title and content into editors
-		m.titleInput.SetValue(m.currentNote.Title)
-		m.contentEditor.SetValue(m.currentNote.Content)
-		// Start with content focused
-		m.editFocus = "content"
-		m.titleInput.Blur()
-		m.contentEditor.Focus()
+		m.viewFromSearch = false
+		m.startEditing(m.currentNote)
+		return m, nil
+
+	case "E":
+		// Edit in $VISUAL/$EDITOR
+		if m.currentNote == nil {
+			return m, nil
+		}
+		return m.openInEditor(m.currentNote)
+
+	case "H":
+		// Browse the previous versions of the note
+		return m.enterHistoryMode()
+
+	case "tab":
+		// Select the next [[link]]
+		m.selectLink(1)
+		return m, nil
+
+	case "shift+tab":
+		m.selectLink(-1)
+		return m, nil
+
+	case "enter":
+		// Open the note the selected link leads to
+		return m.followLink()
+
+	case "[":
+		// Back to the note the last link was followed from
+		return m.navigate("back")
+
+	case "]":
+		return m.navigate("forward")
+
+	case "B":
+		// List the notes linking here
+		return m.enterBacklinksMode()
+
+	case "<":
+		// The journal entry of the day before
+		return m.openDay(-1)
+
+	case ">":
+		return m.openDay(1)
+
+	case "g", "home":
+		m.viewer.GotoTop()
+		return m, nil
+
+	case "G", "end":
+		m.viewer.GotoBottom()
+		return m, nil
+
+	case "m":
+		// Toggle between the rendered and the raw markdown
+		m.viewRaw = !m.viewRaw
+		offset := m.viewer.YOffset
+		m.refreshViewer()
+		m.viewer.SetYOffset(offset)
 		return m, nil
 	}
 
-	return m, nil
+	// Anything else scrolls the note
+	return m.scrollViewer(msg)
 }
 
 // handleEditMode handles key presses in ModeEdit
@@ -433,6 +757,12 @@ func deleteNoteCmd(fs storage.FileSystem, noteID string) tea.Cmd {
 	}
 }
 
+// listed reports whether note belongs in the list, given the folder and the
+// tag it is limited to
+func (m Model) listed(note *storage.Note) bool {
+	return inFolder(note.Folder, m.folderFilter) && (m.tagFilter == "" || note.HasTag(m.tagFilter))
+}
+
 // sortNotes sorts the notes list according to the current sort mode
 func (m *Model) sortNotes() {
 	switch m.sortMode {
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/config"
+	"github.com/N95Ryan/leaf/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// VaultOpenedMsg is sent when the vault switcher has opened a vault
+type VaultOpenedMsg struct {
+	Vault   config.Vault
+	Storage storage.FileSystem
+	Err     error
+}
+
+// handleVaultsMode handles key presses in ModeVaults
+func (m Model) handleVaultsMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q":
+		// Return to list without switching
+		m.mode = ModeList
+		return m, nil
+
+	case "j", "down":
+		if m.vaultIdx < len(m.vaults)-1 {
+			m.vaultIdx++
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.vaultIdx > 0 {
+			m.vaultIdx--
+		}
+		return m, nil
+
+	case "enter":
+		if len(m.vaults) == 0 {
+			return m, nil
+		}
+		vault := m.vaults[m.vaultIdx]
+		if vault.Name == m.currentVault && m.storage != nil {
+			// Already open: nothing to rebuild
+			m.mode = ModeList
+			return m, nil
+		}
+		return m, openVaultCmd(m.openStorage, vault)
+	}
+
+	return m, nil
+}
+
+// enterVaultsMode opens the vault switcher with the active vault selected
+func (m Model) enterVaultsMode() (tea.Model, tea.Cmd) {
+	m.mode = ModeVaults
+	m.vaultIdx = 0
+	for i, v := range m.vaults {
+		if v.Name == m.currentVault {
+			m.vaultIdx = i
+			break
+		}
+	}
+	return m, nil
+}
+
+// handleVaultOpened swaps the storage for the newly opened vault and reloads the notes
+func (m Model) handleVaultOpened(msg VaultOpenedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		// Stay on the current vault
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+
+	m.lastError = ""
+	previous := m.storage
+	m.storage = msg.Storage
+	m.currentVault = msg.Vault.Name
+	m.notes = []*storage.Note{}
+	m.selectedIdx = 0
+	m.currentNote = nil
+	m.folders = nil
+	m.folderFilter = ""
+	m.folderIdx = 0
+	clear(m.folderCollapsed)
+	m.tagCounts = nil
+	m.tagFilter = ""
+	clear(m.marked)
+	m.deleteConfirm = false
+	m.noteToDelete = nil
+	m.mode = ModeList
+
+	// The watcher of the vault left goes with it
+	m.stopWatching()
+	return m, tea.Batch(loadNotesCmd(m.storage), closeStorageCmd(previous), startWatchingCmd(m.openWatcher, msg.Vault.Path))
+}
+
+// closeStorageCmd releases the storage of the vault left, e.g. committing the
+// changes a git vault still has pending
+func closeStorageCmd(fs storage.FileSystem) tea.Cmd {
+	closer, ok := fs.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		_ = closer.Close()
+		return nil
+	}
+}
+
+// openVaultCmd is a command that opens the storage of a vault
+// It runs asynchronously and returns a VaultOpenedMsg
+func openVaultCmd(open StorageOpener, vault config.Vault) tea.Cmd {
+	return func() tea.Msg {
+		fs, err := open(vault.Path)
+		return VaultOpenedMsg{
+			Vault:   vault,
+			Storage: fs,
+			Err:     err,
+		}
+	}
+}
+
+// renderVaults displays the vault switcher
+func (m Model) renderVaults() string {
+	var b strings.Builder
+
+	b.WriteString("🗄️  Switch vault\n\n")
+
+	if len(m.vaults) == 0 {
+		b.WriteString("No vaults configured. Add them to ~/.leaf/config.yaml.\n")
+	} else {
+		for i, vault := range m.vaults {
+			prefix := "  "
+			if i == m.vaultIdx {
+				prefix = "> "
+			}
+			current := ""
+			if vault.Name == m.currentVault {
+				current = " (current)"
+			}
+			b.WriteString(fmt.Sprintf("%s%s%s  %s\n", prefix, vault.Name, current, vault.Path))
+		}
+	}
+
+	b.WriteString("\nShortcuts: j/k (move), Enter (open), Esc (back)")
+	b.WriteString(m.renderError())
+
+	return b.String()
+}
+
+// vault returns the vault named name from the switcher list
+func (m Model) vault(name string) (config.Vault, bool) {
+	for _, v := range m.vaults {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return config.Vault{}, false
+}
@@ -3,6 +3,9 @@ package app
 import (
 	"fmt"
 	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // View renders the user interface based on model state (Elm Pattern)
@@ -19,6 +22,22 @@ func (m Model) View() string {
 		return m.renderSearch()
 	case ModeCreate:
 		return m.renderCreate()
+	case ModeVaults:
+		return m.renderVaults()
+	case ModeQuickOpen:
+		return m.renderQuickOpen()
+	case ModeConflict:
+		return m.renderConflict()
+	case ModeTrash:
+		return m.renderTrash()
+	case ModeHistory:
+		return m.renderHistory()
+	case ModeBacklinks:
+		return m.renderBacklinks()
+	case ModeCalendar:
+		return m.renderCalendar()
+	case ModeFolders, ModeTags:
+		return m.renderList()
 	default:
 		return "Unknown mode"
 	}
@@ -28,26 +47,60 @@ func (m Model) View() string {
 func (m Model) renderList() string {
 	var b strings.Builder
 
-	b.WriteString("🌱 Leaf - Note Manager\n\n")
+	b.WriteString(m.listHeader())
+	b.WriteString("\n\n")
 
-	if len(m.notes) == 0 {
+	if len(m.notes) == 0 && !m.sidePane() {
 		b.WriteString("No notes. Press 'n' to create a note.\n")
 	} else {
-		for i, note := range m.notes {
-			prefix := "  "
-			if i == m.selectedIdx {
-				prefix = "> "
-			}
-			b.WriteString(fmt.Sprintf("%s%s\n", prefix, note.Title))
-		}
+		b.WriteString(m.renderNotePanes())
+		b.WriteString("\n")
 	}
 
-	b.WriteString("\nShortcuts: n (new), r (read), e (edit), t (sort), d (delete), q (quit)")
+	b.WriteString(m.listFooter())
+	return b.String()
+}
+
+// listHeader renders the title line of ModeList, wrapped to the terminal
+func (m Model) listHeader() string {
+	header := "🌱 Leaf - Note Manager"
+	if m.currentVault != "" {
+		header += fmt.Sprintf(" [%s]", m.currentVault)
+	}
+	if m.folderFilter != "" {
+		header += fmt.Sprintf(" 📁 %s", m.folderFilter)
+	}
+	if m.tagFilter != "" {
+		header += fmt.Sprintf(" #%s", m.tagFilter)
+	}
+	return m.wrap(header)
+}
+
+// listFooter renders what follows the notes in ModeList: prompts, shortcuts,
+// sort order, confirmation and errors, wrapped to the terminal
+func (m Model) listFooter() string {
+	var b strings.Builder
+
+	switch {
+	case m.mode == ModeFolders:
+		b.WriteString(m.renderFolderPrompt())
+		b.WriteString("\n" + m.folderShortcuts())
+	case m.mode == ModeTags:
+		b.WriteString("\nShortcuts: j/k (move), Enter (show notes), Esc (back)")
+	case m.tagAction != "":
+		b.WriteString(m.renderTagPrompt())
+		b.WriteString("\nShortcuts: Enter (confirm), Esc (cancel)")
+	default:
+		b.WriteString("\nShortcuts: n (new), r (read), e (edit), E ($EDITOR), / (search), t (sort), d (delete), f (folders), M (move), # (tags), space (mark), +/- (tag), J (today), c (calendar), T (trash), v (vaults), ctrl+p (open), g/G/pgup/pgdn (jump), q (quit)")
+	}
 	b.WriteString(m.renderSortIndicator())
+	if len(m.notes) > 0 {
+		b.WriteString(" " + positionIndicator(m.selectedIdx+1, len(m.notes)))
+	}
 	b.WriteString(m.renderDeleteConfirm())
 	b.WriteString(m.renderError())
 
-	return b.String()
+	return m.wrap(b.String())
 }
 
 // renderView displays the note in read-only mode
@@ -57,14 +110,42 @@ func (m Model) renderView() string {
 	}
 
 	var b strings.Builder
-	b.WriteString(fmt.Sprintf("📖 %s\n\n", m.currentNote.Title))
-	b.WriteString(m.currentNote.Content)
-	b.WriteString("\n\nShortcuts: i/e (edit), Esc (back to list)")
-	b.WriteString(m.renderError())
+	b.WriteString(m.viewHeader())
+	b.WriteString("\n\n")
+	b.WriteString(m.viewer.View())
+	b.WriteString("\n")
+	b.WriteString(m.viewFooter())
 
 	return b.String()
 }
 
+// viewHeader renders the title line of ModeView, wrapped to the terminal
+func (m Model) viewHeader() string {
+	return m.wrap(fmt.Sprintf("📖 %s", m.currentNote.Title))
+}
+
+// viewFooter renders what follows the note in ModeView: position, selected
+// link, shortcuts and errors, wrapped to the terminal
+func (m Model) viewFooter() string {
+	var b strings.Builder
+
+	toggle := "m (raw)"
+	if m.viewRaw {
+		toggle = "m (rendered)"
+	}
+	line, total := m.viewerPosition()
+	b.WriteString("\n" + positionIndicator(line, total))
+	b.WriteString(m.renderLinkStatus())
+	days := ""
+	if _, ok := storage.JournalDay(m.currentNote.ID); ok {
+		days = "</> (previous/next entry), "
+	}
+	b.WriteString(fmt.Sprintf("\nShortcuts: j/k (scroll), u/d (half page), g/G (top/bottom), tab (next link), Enter (follow), [/] (back/forward), %sB (backlinks), i/e (edit), E ($EDITOR), H (history), %s, Esc (back to list)", days, toggle))
+	b.WriteString(m.renderError())
+
+	return m.wrap(b.String())
+}
+
 // renderEdit displays the note editor
 func (m Model) renderEdit() string {
 	if m.currentNote == nil {
@@ -97,31 +178,28 @@ func (m Model) renderEdit() string {
 	return b.String()
 }
 
-// renderSearch displays the search interface
-func (m Model) renderSearch() string {
-	var b strings.Builder
-	b.WriteString("Search: ")
-	b.WriteString(m.searchQuery)
-	b.WriteString("_\n\n")
-	b.WriteString("Type your search and press 'esc' to cancel")
-	b.WriteString(m.renderError())
-
-	return b.String()
-}
-
 // renderCreate displays the note creation interface
 func (m Model) renderCreate() string {
 	var b strings.Builder
 
 	b.WriteString("🌱 Create a new note\n\n")
 
-	// Show title input or content editor based on editMode
-	if m.editMode == "title" {
+	// Show the template picker, title input, field prompt or content editor
+	// based on editMode
+	switch {
+	case m.editMode == "template":
+		b.WriteString(m.renderTemplatePicker())
+	case m.editMode == "field":
+		b.WriteString(m.renderFieldPrompt())
+	case m.editMode == "title":
+		if m.template != nil {
+			b.WriteString(fmt.Sprintf("Template: %s\n\n", m.template.Name))
+		}
 		b.WriteString("Title:\n")
 		b.WriteString(m.titleInput.View())
 		b.WriteString("\n\n")
 		b.WriteString("Shortcuts: Enter (next), Esc (cancel)")
-	} else {
+	default:
 		// Show title as read-only and content editor
 		if m.creatingNote != nil {
 			b.WriteString(fmt.Sprintf("Title: %s\n\n", m.creatingNote.Title))
@@ -142,7 +220,7 @@ func (m Model) renderDeleteConfirm() string {
 	if !m.deleteConfirm || m.noteToDelete == nil {
 		return ""
 	}
-	return fmt.Sprintf("\n⚠️  Press 'd' again to confirm deletion of '%s' (Esc to cancel)", m.noteToDelete.Title)
+	return fmt.Sprintf("\n⚠️  Press 'd' again to move '%s' to the trash (Esc to cancel)", m.noteToDelete.Title)
 }
 
 // renderSortIndicator displays the current sort mode
@@ -166,10 +244,20 @@ func (m Model) renderSortIndicator() string {
 }
 
 // renderError displays error messages if any
+// Query parse errors show the query with a caret under the problem
 func (m Model) renderError() string {
+	if m.queryError != nil {
+		query := m.queryError.Query
+		column := lipgloss.Width(string([]rune(query)[:m.queryError.Column()]))
+		return fmt.Sprintf("\n❌ Error: %s\n   %s\n   %s^\n", m.queryError.Msg, query, strings.Repeat(" ", column))
+	}
+
 	if m.lastError == "" {
 		return ""
 	}
+	if m.retry != nil && m.mode == ModeList {
+		return fmt.Sprintf("\n❌ Error: %s (R to retry)\n", m.lastError)
+	}
 	return fmt.Sprintf("\n❌ Error: %s\n", m.lastError)
 }
 
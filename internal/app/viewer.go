@@ -0,0 +1,81 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/ui"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// defaultViewerWidth is used to wrap notes before the terminal size is known
+	defaultViewerWidth = 80
+)
+
+// newViewer creates the viewport displaying notes in ModeView
+func newViewer() viewport.Model {
+	return viewport.New(defaultViewerWidth, 0)
+}
+
+// enterViewMode opens note read-only in the viewer, with a fresh link
+// navigation history
+func (m Model) enterViewMode(note *storage.Note) Model {
+	m.mode = ModeView
+	m.currentNote = note
+	m.linkIdx = -1
+	m.navBack = nil
+	m.navForward = nil
+	m.refreshViewer()
+	m.viewer.GotoTop()
+	return m
+}
+
+// refreshViewer lays out the viewer for the terminal size and fills it with the
+// current note, rendered as markdown unless the raw view is on
+func (m *Model) refreshViewer() {
+	width := m.width
+	if width <= 0 {
+		width = defaultViewerWidth
+	}
+	m.viewer.Width = width
+
+	if m.currentNote == nil {
+		m.viewer.SetContent("")
+		m.viewerLines = nil
+		return
+	}
+
+	content := m.currentNote.Content
+	if !m.viewRaw {
+		rendered, err := ui.RenderMarkdown(content, width)
+		if err != nil {
+			// Fall back to the raw markdown, still readable
+			m.lastError = "could not render note: " + err.Error()
+		} else {
+			content = rendered
+		}
+	}
+	m.viewer.SetContent(content)
+	m.viewerLines = strings.Split(ansi.Strip(content), "\n")
+
+	// Before the first WindowSizeMsg, show the whole note
+	if m.height <= 0 {
+		m.viewer.Height = m.viewer.TotalLineCount()
+		return
+	}
+	// The title, a blank line, then the footer, which grows with errors and
+	// narrow terminals
+	chrome := lipgloss.Height(m.viewHeader()) + 1 + lipgloss.Height(m.viewFooter())
+	m.viewer.Height = max(m.height-chrome, 1)
+}
+
+// scrollViewer delegates a key press to the viewer (j/k, pgup/pgdn, u/d, ...)
+func (m Model) scrollViewer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.viewer, cmd = m.viewer.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/watcher"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// NoteChangedMsg is sent when a note was created or modified outside the app
+type NoteChangedMsg struct {
+	Note *storage.Note
+	Err  error
+}
+
+// NoteRemovedMsg is sent when a note file was removed outside the app
+type NoteRemovedMsg struct {
+	ID string
+}
+
+// notesRescanMsg is sent when the watcher lost events and every note must be reloaded
+type notesRescanMsg struct{}
+
+// WatcherOpener starts watching the notes directory of a vault
+type WatcherOpener func(dir string) (*watcher.Watcher, error)
+
+// watcherStartedMsg is sent when the watcher of a vault is started
+type watcherStartedMsg struct {
+	dir     string
+	watcher *watcher.Watcher
+	err     error
+}
+
+// startWatchingCmd starts watching the notes directory of a vault
+// It runs asynchronously and returns a watcherStartedMsg
+func startWatchingCmd(open WatcherOpener, dir string) tea.Cmd {
+	if open == nil || dir == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		w, err := open(dir)
+		return watcherStartedMsg{dir: dir, watcher: w, err: err}
+	}
+}
+
+// handleWatcherStarted starts listening to the changes the new watcher sees
+func (m Model) handleWatcherStarted(msg watcherStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		// The app works without it, notes just won't refresh by themselves
+		m.lastError = "could not watch notes: " + msg.err.Error()
+		return m, nil
+	}
+
+	vault, ok := m.vault(m.currentVault)
+	if !ok || vault.Path != msg.dir || m.watcher != nil {
+		// The user switched vaults in the meantime
+		msg.watcher.Close()
+		return m, nil
+	}
+	m.watcher = msg.watcher
+	return m, watchCmd(m.watcher, m.storage)
+}
+
+// stopWatching closes the watcher of the vault being left
+func (m *Model) stopWatching() {
+	if m.watcher != nil {
+		m.watcher.Close()
+		m.watcher = nil
+	}
+}
+
+// watchCmd waits for the next change in the notes directory
+// It returns nil once the watcher is closed
+func watchCmd(w *watcher.Watcher, fs storage.FileSystem) tea.Cmd {
+	if w == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ev, ok := <-w.Events()
+		if !ok {
+			return nil
+		}
+
+		// The search index only looks again at the files it is told about
+		index, indexed := fs.(storage.SearchIndex)
+		if ev.Op == watcher.Rescan {
+			if indexed {
+				index.Invalidate("")
+			}
+			return notesRescanMsg{}
+		}
+		if indexed {
+			index.Invalidate(ev.Path)
+		}
+
+		// Files named after the title don't tell the ID of their note
+		id := ev.ID
+		if index, ok := fs.(storage.PathIndex); ok {
+			if known, ok := index.NoteIDAt(ev.Path); ok {
+				id = known
+			}
+		}
+
+		// A note moved to another folder or renamed is removed from one file
+		// and still exists
+		note, err := fs.GetNote(context.Background(), id)
+		if errors.Is(err, storage.ErrNotFound) || (ev.Op == watcher.Removed && err != nil) {
+			// Gone again before we could read it
+			return NoteRemovedMsg{ID: id}
+		}
+		if err != nil {
+			return NoteChangedMsg{Err: err}
+		}
+		return NoteChangedMsg{Note: note}
+	}
+}
+
+// handleNoteChanged updates or adds a single note, keeping the selection
+func (m Model) handleNoteChanged(msg NoteChangedMsg) (tea.Model, tea.Cmd) {
+	next := watchCmd(m.watcher, m.storage)
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, next
+	}
+
+	// A note moved out of the folder shown leaves the list
+	inScope := m.listed(msg.Note)
+	m.keepSelection(func() {
+		notes := make([]*storage.Note, 0, len(m.notes)+1)
+		found := false
+		for _, note := range m.notes {
+			if note.ID == msg.Note.ID {
+				found = true
+				if !inScope {
+					continue
+				}
+				note = msg.Note
+			}
+			notes = append(notes, note)
+		}
+		if !found && inScope {
+			notes = append(notes, msg.Note)
+		}
+		m.notes = notes
+		m.sortNotes()
+	})
+
+	if m.mode == ModeView && m.currentNote != nil && m.currentNote.ID == msg.Note.ID {
+		offset := m.viewer.YOffset
+		m.currentNote = msg.Note
+		m.refreshViewer()
+		m.viewer.SetYOffset(offset)
+	}
+	return m, next
+}
+
+// handleNoteRemoved drops a single note, keeping the selection
+func (m Model) handleNoteRemoved(msg NoteRemovedMsg) (tea.Model, tea.Cmd) {
+	m.dropNote(msg.ID)
+	return m, watchCmd(m.watcher, m.storage)
+}
+
+// dropNote removes a note that no longer exists from the list, keeping the
+// selection, and leaves the viewer if it was showing it
+func (m *Model) dropNote(id string) {
+	m.keepSelection(func() {
+		notes := make([]*storage.Note, 0, len(m.notes))
+		for _, note := range m.notes {
+			if note.ID != id {
+				notes = append(notes, note)
+			}
+		}
+		m.notes = notes
+	})
+
+	if m.mode == ModeView && m.currentNote != nil && m.currentNote.ID == id {
+		m.mode = ModeList
+		m.currentNote = nil
+		m.viewFromSearch = false
+		m.lastError = "the note was deleted outside leaf"
+	}
+}
+
+// keepSelection runs update on the notes list, then selects the same note as
+// before, or the note now at the same position if it is gone
+func (m *Model) keepSelection(update func()) {
+	selectedID := ""
+	if m.selectedIdx < len(m.notes) {
+		selectedID = m.notes[m.selectedIdx].ID
+	}
+
+	update()
+
+	idx := m.selectedIdx
+	for i, note := range m.notes {
+		if note.ID == selectedID {
+			idx = i
+			break
+		}
+	}
+	m.selectedIdx = max(min(idx, len(m.notes)-1), 0)
+	m.followSelection()
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK       = 0 // Success
+	ExitError    = 1 // The command failed
+	ExitUsage    = 2 // Invalid arguments or query
+	ExitNotFound = 3 // The note doesn't exist
+)
+
+// Env holds the storage and the streams a command works with
+type Env struct {
+	Storage storage.FileSystem
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+
+	// StdinIsTerminal is true when nothing is piped in, so commands don't wait for content
+	StdinIsTerminal bool
+
+	// Journal creates the daily journal entries of today
+	Journal storage.Journal
+}
+
+// command is a non-interactive subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(env *Env, args []string) int
+}
+
+// commands returns the available subcommands
+func commands() []command {
+	return []command{
+		{name: "new", usage: "new [--template name] <title>", summary: "Create a note, reading its content from stdin", run: runNew},
+		{name: "today", usage: "today [--print]", summary: "Open today's journal entry in $VISUAL/$EDITOR, creating it if needed", run: runToday},
+		{name: "list", usage: "list", summary: "List notes, most recently updated first", run: runList},
+		{name: "show", usage: "show <id|title>", summary: "Print the content of a note", run: runShow},
+		{name: "search", usage: "search <query>", summary: "Search notes (same syntax as the TUI)", run: runSearch},
+		{name: "edit", usage: "edit <id|title>", summary: "Open a note in $VISUAL/$EDITOR", run: runEdit},
+		{name: "mv", usage: "mv [--folder path] <id|title> [title]", summary: "Rename a note or move it to another folder", run: runMove},
+		{name: "tag", usage: "tag <id|title> [+tag|-tag]...", summary: "Show, add or remove the tags of a note", run: runTag},
+		{name: "tags", usage: "tags [tag]", summary: "List tags with their number of notes, or the notes with a tag", run: runTags},
+		{name: "backlinks", usage: "backlinks <id|title>", summary: "List the notes linking to a note with [[links]]", run: runBacklinks},
+		{name: "rm", usage: "rm <id|title>...", summary: "Move notes to the trash", run: runRemove},
+		{name: "trash", usage: "trash [list|restore|empty]", summary: "List, restore (by ID or title) or purge deleted notes", run: runTrash},
+		{name: "log", usage: "log <id|title>", summary: "List the commits that changed a note (git vaults)", run: runLog},
+		{name: "blame", usage: "blame <id|title>", summary: "Show who last changed each line of a note (git vaults)", run: runBlame},
+	}
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code
+func Run(env *Env, args []string) int {
+	if len(args) == 0 {
+		printUsage(env.Stderr)
+		return ExitUsage
+	}
+
+	cmd, ok := lookup(args[0])
+	if !ok {
+		fmt.Fprintf(env.Stderr, "leaf: unknown command %q\n\n", args[0])
+		printUsage(env.Stderr)
+		return ExitUsage
+	}
+
+	return cmd.run(env, args[1:])
+}
+
+// lookup finds a subcommand by name
+func lookup(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// printUsage lists the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: leaf [--dir path | --vault name] [command]")
+	fmt.Fprintln(w, "\nWithout a command, leaf starts the interactive interface.")
+	fmt.Fprintln(w, "Commands printing notes accept --json before their arguments.")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-38s %s\n", cmd.usage, cmd.summary)
+	}
+}
+
+// newFlagSet creates the flag set of a subcommand, reporting errors on stderr
+func newFlagSet(env *Env, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(env.Stderr)
+	return flags
+}
+
+// fail prints err and returns the matching exit code
+func fail(env *Env, err error) int {
+	errorf(env, "%v", err)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, storage.ErrInvalidID):
+		return ExitUsage
+	}
+	return ExitError
+}
+
+// errorf prints an error message prefixed with the program name
+func errorf(env *Env, format string, args ...interface{}) {
+	fmt.Fprintf(env.Stderr, "leaf: "+format+"\n", args...)
+}
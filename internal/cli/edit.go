@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/editor"
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// runEdit opens a note in $VISUAL/$EDITOR and saves it when it changed
+func runEdit(env *Env, args []string) int {
+	flags := newFlagSet(env, "edit")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	ref := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(ref) == "" {
+		errorf(env, "usage: leaf edit <id|title>")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	before, err := resolveNote(ctx, env.Storage, ref)
+	if err != nil {
+		return fail(env, err)
+	}
+	return editNote(ctx, env, before)
+}
+
+// editNote opens before in $VISUAL/$EDITOR and saves it when it changed
+func editNote(ctx context.Context, env *Env, before *storage.Note) int {
+	cmd, err := editor.Command(before.FilePath)
+	if err != nil {
+		errorf(env, "could not open %s: %v", before.Title, err)
+		return ExitError
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = env.Stdin, env.Stdout, env.Stderr
+	if err := cmd.Run(); err != nil {
+		errorf(env, "editor failed: %v", err)
+		return ExitError
+	}
+
+	// Re-parse the file and save it so the updated time and the index follow
+	after, err := env.Storage.GetNote(ctx, before.ID)
+	if err != nil {
+		return fail(env, err)
+	}
+	if after.SameContent(before) {
+		return ExitOK
+	}
+	if err := env.Storage.SaveNote(ctx, after); err != nil {
+		return fail(env, err)
+	}
+	return ExitOK
+}
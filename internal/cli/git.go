@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// errNoVersionControl is returned when the vault is not kept in git
+var errNoVersionControl = errors.New("this vault is not under version control, set git: true on it")
+
+// commitJSON is the JSON form of a commit
+type commitJSON struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// blameJSON is the JSON form of a blamed line
+type blameJSON struct {
+	Line   int         `json:"line"`
+	Text   string      `json:"text"`
+	Commit *commitJSON `json:"commit"`
+}
+
+// toCommitJSON converts a commit for JSON output, nil staying nil
+func toCommitJSON(commit *storage.Commit) *commitJSON {
+	if commit == nil {
+		return nil
+	}
+	return &commitJSON{
+		Hash:    commit.Hash,
+		Author:  commit.Author,
+		Email:   commit.Email,
+		Date:    commit.Date,
+		Subject: commit.Subject,
+	}
+}
+
+// runLog prints the commits that changed a note
+func runLog(env *Env, args []string) int {
+	vc, ok := env.Storage.(storage.VersionControl)
+	if !ok {
+		return fail(env, errNoVersionControl)
+	}
+
+	flags := newFlagSet(env, "log")
+	asJSON := flags.Bool("json", false, "print the commits as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	ref := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(ref) == "" {
+		errorf(env, "usage: leaf log <id|title>")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	note, err := resolveNote(ctx, env.Storage, ref)
+	if err != nil {
+		return fail(env, err)
+	}
+	commits, err := vc.Log(ctx, note.ID)
+	if err != nil {
+		return fail(env, err)
+	}
+
+	if *asJSON {
+		out := make([]*commitJSON, len(commits))
+		for i, commit := range commits {
+			out[i] = toCommitJSON(commit)
+		}
+		return writeJSON(env, out)
+	}
+
+	for _, commit := range commits {
+		fmt.Fprintf(env.Stdout, "%s\t%s\t%s\t%s\n", shortHash(commit.Hash), commit.Date.Format(time.RFC3339), commit.Author, commit.Subject)
+	}
+	return ExitOK
+}
+
+// runBlame prints the lines of a note with the commit that last changed each
+func runBlame(env *Env, args []string) int {
+	vc, ok := env.Storage.(storage.VersionControl)
+	if !ok {
+		return fail(env, errNoVersionControl)
+	}
+
+	flags := newFlagSet(env, "blame")
+	asJSON := flags.Bool("json", false, "print the lines as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	ref := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(ref) == "" {
+		errorf(env, "usage: leaf blame <id|title>")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	note, err := resolveNote(ctx, env.Storage, ref)
+	if err != nil {
+		return fail(env, err)
+	}
+	lines, err := vc.Blame(ctx, note.ID)
+	if err != nil {
+		return fail(env, err)
+	}
+
+	if *asJSON {
+		out := make([]blameJSON, len(lines))
+		for i, line := range lines {
+			out[i] = blameJSON{Line: line.Line, Text: line.Text, Commit: toCommitJSON(line.Commit)}
+		}
+		return writeJSON(env, out)
+	}
+
+	for _, line := range lines {
+		hash, date := "uncommitted", ""
+		if line.Commit != nil {
+			hash, date = shortHash(line.Commit.Hash), line.Commit.Date.Format("2006-01-02")
+		}
+		fmt.Fprintf(env.Stdout, "%-11s %-10s %4d| %s\n", hash, date, line.Line, line.Text)
+	}
+	return ExitOK
+}
+
+// shortHash abbreviates a commit hash the way git does by default
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
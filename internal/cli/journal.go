@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"time"
+)
+
+// runToday opens today's journal entry in $VISUAL/$EDITOR, or prints it,
+// creating it first when there is none
+func runToday(env *Env, args []string) int {
+	flags := newFlagSet(env, "today")
+	printOnly := flags.Bool("print", false, "print the ID of the entry instead of opening it")
+	asJSON := flags.Bool("json", false, "print the entry as JSON instead of opening it")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 {
+		errorf(env, "usage: leaf today [--print]")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	note, err := env.Journal.Open(ctx, env.Storage, time.Now())
+	if err != nil {
+		return fail(env, err)
+	}
+	if *printOnly || *asJSON {
+		return printNote(env, note, *asJSON)
+	}
+	return editNote(ctx, env, note)
+}
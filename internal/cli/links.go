@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// errNoLinkGraph is returned when the storage doesn't follow links between notes
+var errNoLinkGraph = errors.New("this vault has no link graph")
+
+// runBacklinks prints the notes linking to a note
+func runBacklinks(env *Env, args []string) int {
+	flags := newFlagSet(env, "backlinks")
+	asJSON := flags.Bool("json", false, "print the notes as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	ref := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(ref) == "" {
+		errorf(env, "usage: leaf backlinks <id|title>")
+		return ExitUsage
+	}
+
+	graph, ok := env.Storage.(storage.LinkGraph)
+	if !ok {
+		return fail(env, errNoLinkGraph)
+	}
+
+	ctx := context.Background()
+	note, err := resolveNote(ctx, env.Storage, ref)
+	if err != nil {
+		return fail(env, err)
+	}
+	notes, err := graph.Backlinks(ctx, note.ID)
+	if err != nil {
+		return fail(env, err)
+	}
+	return printNotes(env, notes, *asJSON)
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+var errNoFolders = errors.New("this vault has no folders")
+
+// runNew creates a note titled by the arguments, with piped stdin as content
+// With --template, the note starts from a template of the vault and stdin
+// goes where the template puts the cursor
+func runNew(env *Env, args []string) int {
+	flags := newFlagSet(env, "new")
+	asJSON := flags.Bool("json", false, "print the note as JSON")
+	templateName := flags.String("template", "", "start from a template of the vault's .templates folder")
+	fields := fieldValues{}
+	flags.Var(fields, "field", "value of a field the template prompts for, as name=value (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	title := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if title == "" && *templateName == "" {
+		errorf(env, "usage: leaf new [--template name [--field name=value]...] <title>")
+		return ExitUsage
+	}
+
+	content := ""
+	if !env.StdinIsTerminal {
+		data, err := io.ReadAll(env.Stdin)
+		if err != nil {
+			return fail(env, fmt.Errorf("could not read stdin: %w", err))
+		}
+		content = strings.TrimRight(string(data), "\n")
+	}
+
+	ctx := context.Background()
+	note := storage.NewNote(title, content)
+	if *templateName != "" {
+		var missing []string
+		var err error
+		note, missing, err = newFromTemplate(ctx, env.Storage, *templateName, title, fields, content)
+		if err != nil {
+			return fail(env, err)
+		}
+		if len(missing) > 0 {
+			errorf(env, "template %s prompts for %s: set them with --field name=value", *templateName, strings.Join(missing, ", "))
+			return ExitUsage
+		}
+		if strings.TrimSpace(note.Title) == "" {
+			errorf(env, "template %s gives no title: usage: leaf new --template name <title>", *templateName)
+			return ExitUsage
+		}
+	}
+
+	if err := env.Storage.SaveNote(ctx, note); err != nil {
+		return fail(env, err)
+	}
+	return printNote(env, note, *asJSON)
+}
+
+// runList prints every note, most recently updated first
+func runList(env *Env, args []string) int {
+	flags := newFlagSet(env, "list")
+	asJSON := flags.Bool("json", false, "print the notes as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 {
+		errorf(env, "usage: leaf list")
+		return ExitUsage
+	}
+
+	notes, err := env.Storage.ListNotes(context.Background())
+	if err != nil {
+		return fail(env, err)
+	}
+	return printNotes(env, notes, *asJSON)
+}
+
+// runShow prints the content of a note
+func runShow(env *Env, args []string) int {
+	flags := newFlagSet(env, "show")
+	asJSON := flags.Bool("json", false, "print the note as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	ref := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(ref) == "" {
+		errorf(env, "usage: leaf show <id|title>")
+		return ExitUsage
+	}
+
+	note, err := resolveNote(context.Background(), env.Storage, ref)
+	if err != nil {
+		return fail(env, err)
+	}
+
+	if *asJSON {
+		return writeJSON(env, toJSON(note, true))
+	}
+	fmt.Fprintln(env.Stdout, note.Content)
+	return ExitOK
+}
+
+// runMove renames a note, moves it to another folder with --folder, or both
+func runMove(env *Env, args []string) int {
+	flags := newFlagSet(env, "mv")
+	asJSON := flags.Bool("json", false, "print the note as JSON")
+	folder := flags.String("folder", "", `move the note to this folder, "/" for the root of the vault`)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	moving := false
+	flags.Visit(func(f *flag.Flag) {
+		moving = moving || f.Name == "folder"
+	})
+
+	renaming := flags.NArg() == 2
+	if flags.NArg() == 0 || flags.NArg() > 2 || (!moving && !renaming) || (renaming && strings.TrimSpace(flags.Arg(1)) == "") {
+		errorf(env, "usage: leaf mv [--folder path] <id|title> [title] (quote titles with spaces)")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	note, err := resolveNote(ctx, env.Storage, flags.Arg(0))
+	if err != nil {
+		return fail(env, err)
+	}
+
+	if moving {
+		folders, ok := env.Storage.(storage.Folders)
+		if !ok {
+			return fail(env, errNoFolders)
+		}
+		if note, err = folders.MoveNote(ctx, note.ID, *folder); err != nil {
+			return fail(env, err)
+		}
+	}
+
+	if renaming {
+		note.Title = strings.TrimSpace(flags.Arg(1))
+		if err := env.Storage.SaveNote(ctx, note); err != nil {
+			return fail(env, err)
+		}
+	}
+	return printNote(env, note, *asJSON)
+}
+
+// runRemove moves notes to the trash, going on with the others when one fails
+func runRemove(env *Env, args []string) int {
+	flags := newFlagSet(env, "rm")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() == 0 {
+		errorf(env, "usage: leaf rm <id|title>...")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	code := ExitOK
+	for _, ref := range flags.Args() {
+		note, err := resolveNote(ctx, env.Storage, ref)
+		if err == nil {
+			err = env.Storage.DeleteNote(ctx, note.ID)
+		}
+		if err != nil {
+			code = fail(env, err)
+		}
+	}
+	return code
+}
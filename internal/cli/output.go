@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// noteJSON is the JSON representation of a note
+// Tags include the #hashtags of the content
+// Content is only included by commands printing a single note
+type noteJSON struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Tags    []string  `json:"tags"`
+	Aliases []string  `json:"aliases,omitempty"`
+	Folder  string    `json:"folder,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	Path    string    `json:"path,omitempty"`
+	Content *string   `json:"content,omitempty"`
+}
+
+// toJSON converts a note for JSON output
+func toJSON(note *storage.Note, withContent bool) noteJSON {
+	out := noteJSON{
+		ID:      note.ID,
+		Title:   note.Title,
+		Tags:    note.AllTags(),
+		Aliases: note.Aliases,
+		Folder:  note.Folder,
+		Created: note.CreatedAt,
+		Updated: note.UpdatedAt,
+		Path:    note.FilePath,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if withContent {
+		content := note.Content
+		out.Content = &content
+	}
+	return out
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(env *Env, v interface{}) int {
+	enc := json.NewEncoder(env.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fail(env, fmt.Errorf("could not encode JSON: %w", err))
+	}
+	return ExitOK
+}
+
+// printNotes prints notes as "id<TAB>title" lines, or as a JSON array
+func printNotes(env *Env, notes []*storage.Note, asJSON bool) int {
+	if asJSON {
+		out := make([]noteJSON, len(notes))
+		for i, note := range notes {
+			out[i] = toJSON(note, false)
+		}
+		return writeJSON(env, out)
+	}
+
+	for _, note := range notes {
+		fmt.Fprintf(env.Stdout, "%s\t%s\n", note.ID, note.Title)
+	}
+	return ExitOK
+}
+
+// printNote prints a single note as JSON, or its ID
+func printNote(env *Env, note *storage.Note, asJSON bool) int {
+	if asJSON {
+		return writeJSON(env, toJSON(note, true))
+	}
+	fmt.Fprintln(env.Stdout, note.ID)
+	return ExitOK
+}
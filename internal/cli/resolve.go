@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// resolveNote finds a note by ID, or else by its title (case-insensitive), or
+// else by one of its aliases
+func resolveNote(ctx context.Context, fs storage.FileSystem, ref string) (*storage.Note, error) {
+	note, err := fs.GetNote(ctx, ref)
+	if err == nil {
+		return note, nil
+	}
+	// A title is not necessarily a valid ID; any other failure is real
+	if !errors.Is(err, storage.ErrNotFound) && !errors.Is(err, storage.ErrInvalidID) {
+		return nil, err
+	}
+
+	notes, err := fs.ListNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*storage.Note
+	for _, note := range notes {
+		if strings.EqualFold(note.Title, ref) {
+			matches = append(matches, note)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if graph, ok := fs.(storage.LinkGraph); ok {
+			if note, err := graph.ResolveLink(ctx, ref); err == nil {
+				return note, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: no note with ID, title or alias %q", storage.ErrNotFound, ref)
+	case 1:
+		return matches[0], nil
+	}
+
+	ids := make([]string, len(matches))
+	for i, note := range matches {
+		ids[i] = note.ID
+	}
+	return nil, fmt.Errorf("%q matches several notes, use an ID: %s", ref, strings.Join(ids, ", "))
+}
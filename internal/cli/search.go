@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// runSearch prints the notes matching a query, best matches first
+func runSearch(env *Env, args []string) int {
+	flags := newFlagSet(env, "search")
+	asJSON := flags.Bool("json", false, "print the notes as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	query := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		errorf(env, "usage: leaf search <query>")
+		return ExitUsage
+	}
+
+	notes, err := env.Storage.SearchNotes(context.Background(), query)
+
+	var parseErr *storage.ParseError
+	if errors.As(err, &parseErr) {
+		printParseError(env.Stderr, parseErr)
+		return ExitUsage
+	}
+	if err != nil {
+		return fail(env, err)
+	}
+
+	return printNotes(env, notes, *asJSON)
+}
+
+// printParseError shows the query with a caret under the problem
+func printParseError(w io.Writer, err *storage.ParseError) {
+	fmt.Fprintf(w, "leaf: %s\n", err.Msg)
+	fmt.Fprintf(w, "  %s\n", err.Query)
+	fmt.Fprintf(w, "  %s^\n", strings.Repeat(" ", err.Column()))
+}
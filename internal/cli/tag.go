@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// errNoTagIndex is returned when the storage can't list tags
+var errNoTagIndex = errors.New("this vault has no tag index")
+
+// tagCountJSON is the JSON representation of a tag listed by "leaf tags"
+type tagCountJSON struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// runTag prints the tags of a note, after adding "+tag" (or "tag") and removing "-tag"
+// Removing a tag also turns its #hashtags in the content into plain words
+func runTag(env *Env, args []string) int {
+	flags := newFlagSet(env, "tag")
+	asJSON := flags.Bool("json", false, "print the note as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() == 0 {
+		errorf(env, "usage: leaf tag <id|title> [+tag|-tag]...")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	note, err := resolveNote(ctx, env.Storage, flags.Arg(0))
+	if err != nil {
+		return fail(env, err)
+	}
+
+	changed := false
+	for _, op := range flags.Args()[1:] {
+		if tag, ok := strings.CutPrefix(op, "-"); ok {
+			changed = note.RemoveTag(tag) || changed
+			continue
+		}
+		changed = note.AddTag(strings.TrimPrefix(op, "+")) || changed
+	}
+
+	if changed {
+		if err := env.Storage.SaveNote(ctx, note); err != nil {
+			return fail(env, err)
+		}
+	}
+
+	if *asJSON {
+		return writeJSON(env, toJSON(note, false))
+	}
+	for _, tag := range note.AllTags() {
+		fmt.Fprintln(env.Stdout, tag)
+	}
+	return ExitOK
+}
+
+// runTags prints every tag with its number of notes, or the notes carrying a tag
+func runTags(env *Env, args []string) int {
+	flags := newFlagSet(env, "tags")
+	asJSON := flags.Bool("json", false, "print the tags or the notes as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 1 {
+		errorf(env, "usage: leaf tags [tag]")
+		return ExitUsage
+	}
+
+	index, ok := env.Storage.(storage.TagIndex)
+	if !ok {
+		return fail(env, errNoTagIndex)
+	}
+
+	ctx := context.Background()
+	if flags.NArg() == 1 {
+		notes, err := index.NotesByTag(ctx, flags.Arg(0))
+		if err != nil {
+			return fail(env, err)
+		}
+		return printNotes(env, notes, *asJSON)
+	}
+
+	tags, err := index.ListTags(ctx)
+	if err != nil {
+		return fail(env, err)
+	}
+	if *asJSON {
+		out := make([]tagCountJSON, len(tags))
+		for i, tag := range tags {
+			out[i] = tagCountJSON{Tag: tag.Tag, Count: tag.Count}
+		}
+		return writeJSON(env, out)
+	}
+	for _, tag := range tags {
+		fmt.Fprintf(env.Stdout, "%s\t%d\n", tag.Tag, tag.Count)
+	}
+	return ExitOK
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// errNoTemplates is returned when the storage offers no note templates
+var errNoTemplates = errors.New("this vault has no templates")
+
+// fieldValues collects the repeated --field name=value flags of new
+type fieldValues map[string]string
+
+// String implements flag.Value
+func (f fieldValues) String() string {
+	pairs := make([]string, 0, len(f))
+	for name, value := range f {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set implements flag.Value
+func (f fieldValues) Set(pair string) error {
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected name=value, got %q", pair)
+	}
+	f[strings.TrimSpace(name)] = value
+	return nil
+}
+
+// newFromTemplate renders the template called name into a new note titled
+// title, with content inserted at the cursor of the template
+// It returns the prompted fields missing from fields instead, if any
+func newFromTemplate(ctx context.Context, fs storage.FileSystem, name, title string, fields fieldValues, content string) (*storage.Note, []string, error) {
+	templates, ok := fs.(storage.Templates)
+	if !ok {
+		return nil, nil, errNoTemplates
+	}
+	tmpl, err := templates.GetTemplate(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names, err := tmpl.Fields()
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []string
+	for _, field := range names {
+		if _, ok := fields[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, missing, nil
+	}
+
+	data := storage.NewTemplateData(title)
+	data.Fields = fields
+	note, cursor, err := tmpl.Render(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case content == "":
+	case cursor >= 0:
+		note.Content = note.Content[:cursor] + content + note.Content[cursor:]
+	case strings.TrimSpace(note.Content) == "":
+		note.Content = content
+	default:
+		note.Content = strings.TrimRight(note.Content, "\n") + "\n\n" + content
+	}
+	return note, nil, nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// errNoTrash is returned when the storage deletes notes for good
+var errNoTrash = errors.New("this vault has no trash")
+
+// trashJSON is the JSON form of a trashed note
+type trashJSON struct {
+	TrashID      string    `json:"trash_id"`
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	OriginalPath string    `json:"original_path"`
+	Deleted      time.Time `json:"deleted"`
+}
+
+// runTrash lists, restores or purges deleted notes
+func runTrash(env *Env, args []string) int {
+	trash, ok := env.Storage.(storage.Trash)
+	if !ok {
+		return fail(env, errNoTrash)
+	}
+
+	action, rest := "list", args
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, rest = args[0], args[1:]
+	}
+
+	switch action {
+	case "list":
+		return runTrashList(env, trash, rest)
+	case "restore":
+		return runTrashRestore(env, trash, rest)
+	case "empty":
+		return runTrashEmpty(env, trash, rest)
+	}
+	errorf(env, "usage: leaf trash [list|restore <id|title>...|empty]")
+	return ExitUsage
+}
+
+// runTrashList prints the trashed notes, most recently deleted first
+func runTrashList(env *Env, trash storage.Trash, args []string) int {
+	flags := newFlagSet(env, "trash list")
+	asJSON := flags.Bool("json", false, "print the notes as JSON")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	notes, err := trash.ListTrash(context.Background())
+	if err != nil {
+		return fail(env, err)
+	}
+
+	if *asJSON {
+		out := make([]trashJSON, len(notes))
+		for i, note := range notes {
+			out[i] = trashJSON{
+				TrashID:      note.TrashID,
+				ID:           note.ID,
+				Title:        note.Title,
+				OriginalPath: note.OriginalPath,
+				Deleted:      note.DeletedAt,
+			}
+		}
+		return writeJSON(env, out)
+	}
+
+	for _, note := range notes {
+		fmt.Fprintf(env.Stdout, "%s\t%s\t%s\n", note.TrashID, note.DeletedAt.Format(time.RFC3339), note.Title)
+	}
+	return ExitOK
+}
+
+// runTrashRestore puts trashed notes back, going on with the others when one fails
+func runTrashRestore(env *Env, trash storage.Trash, args []string) int {
+	flags := newFlagSet(env, "trash restore")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() == 0 {
+		errorf(env, "usage: leaf trash restore <id|title>...")
+		return ExitUsage
+	}
+
+	ctx := context.Background()
+	code := ExitOK
+	for _, ref := range flags.Args() {
+		trashed, err := resolveTrashed(ctx, trash, ref)
+		var note *storage.Note
+		if err == nil {
+			note, err = trash.RestoreNote(ctx, trashed.TrashID)
+		}
+		if err != nil {
+			code = fail(env, err)
+			continue
+		}
+		fmt.Fprintln(env.Stdout, note.ID)
+	}
+	return code
+}
+
+// runTrashEmpty purges every trashed note
+func runTrashEmpty(env *Env, trash storage.Trash, args []string) int {
+	flags := newFlagSet(env, "trash empty")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 {
+		errorf(env, "usage: leaf trash empty")
+		return ExitUsage
+	}
+
+	if err := trash.EmptyTrash(context.Background()); err != nil {
+		return fail(env, err)
+	}
+	return ExitOK
+}
+
+// resolveTrashed finds a trashed note by trash ID, note ID or title
+// When a note was deleted several times, the most recent deletion wins
+func resolveTrashed(ctx context.Context, trash storage.Trash, ref string) (*storage.TrashedNote, error) {
+	notes, err := trash.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The list is sorted most recent first
+	for _, match := range []func(*storage.TrashedNote) bool{
+		func(n *storage.TrashedNote) bool { return n.TrashID == ref },
+		func(n *storage.TrashedNote) bool { return n.ID == ref },
+		func(n *storage.TrashedNote) bool { return strings.EqualFold(n.Title, ref) },
+	} {
+		for _, note := range notes {
+			if match(note) {
+				return note, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: no note with ID or title %q in the trash", storage.ErrNotFound, ref)
+}
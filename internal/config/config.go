@@ -0,0 +1,291 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvDir is the environment variable that overrides the notes directory
+const EnvDir = "LEAF_DIR"
+
+// Vault is a named notes directory
+type Vault struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+
+	// Git commits every change to the notes of the vault
+	Git bool `yaml:"git"`
+}
+
+// Config holds the user configuration read from ~/.leaf/config.yaml
+type Config struct {
+	// Vaults lists the known notes directories
+	Vaults []Vault `yaml:"vaults"`
+
+	// DefaultVault is the name of the vault opened when no directory is given
+	DefaultVault string `yaml:"default_vault"`
+
+	// FileMode is the octal permission of the files leaf writes, e.g. "0600"
+	FileMode string `yaml:"file_mode"`
+
+	// TrashRetention is how long deleted notes are kept, e.g. "30d" or "12h"
+	// "0" keeps them until the trash is emptied
+	TrashRetention string `yaml:"trash_retention"`
+
+	// HistoryMaxVersions is how many versions of each note are kept
+	// 0 means storage.DefaultHistoryVersions, a negative value lifts the limit
+	HistoryMaxVersions int `yaml:"history_max_versions"`
+
+	// HistoryMaxAge is how long versions of a note are kept, e.g. "90d"
+	// Unset or "0" keeps them regardless of age
+	HistoryMaxAge string `yaml:"history_max_age"`
+
+	// Filenames is how note files are named: "uuid" (the default), "slug"
+	// for a slug of the title, or "date" for the creation date and the slug
+	Filenames string `yaml:"filenames"`
+
+	// JournalTemplate is the template of .templates daily journal entries
+	// start from, "journal" by default; entries start empty without it
+	JournalTemplate string `yaml:"journal_template"`
+
+	// JournalFolder is the folder new journal entries are created in
+	JournalFolder string `yaml:"journal_folder"`
+
+	// GitCommitDelay is how long git vaults wait for more changes before
+	// committing, e.g. "10s"; "0" commits every change on its own
+	GitCommitDelay string `yaml:"git_commit_delay"`
+}
+
+// DefaultPath returns the path of the configuration file ~/.leaf/config.yaml
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".leaf", "config.yaml"), nil
+}
+
+// Load reads the configuration file at path
+// A missing file is not an error and yields an empty configuration
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+
+	if _, err := cfg.NoteFileMode(); err != nil {
+		return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	if _, err := cfg.TrashRetentionDuration(); err != nil {
+		return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	if _, _, err := cfg.HistoryRetention(); err != nil {
+		return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	if _, err := cfg.CommitDelay(); err != nil {
+		return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	if _, err := cfg.FilenameStrategy(); err != nil {
+		return nil, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+
+	// Expand ~ in vault paths
+	for i := range cfg.Vaults {
+		cfg.Vaults[i].Path = ExpandHome(cfg.Vaults[i].Path)
+	}
+
+	return cfg, nil
+}
+
+// Vault returns the vault with the given name
+func (c *Config) Vault(name string) (Vault, bool) {
+	for _, v := range c.Vaults {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Vault{}, false
+}
+
+// NoteFileMode returns the permission of the files leaf writes
+// It defaults to storage.DefaultFileMode when file_mode is not set
+func (c *Config) NoteFileMode() (os.FileMode, error) {
+	if c.FileMode == "" {
+		return storage.DefaultFileMode, nil
+	}
+
+	mode, err := strconv.ParseUint(c.FileMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid file_mode %q: expected an octal permission such as 0600", c.FileMode)
+	}
+	if mode&0600 != 0600 {
+		return 0, fmt.Errorf("invalid file_mode %q: the owner must be able to read and write", c.FileMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// TrashRetentionDuration returns how long deleted notes are kept
+// It defaults to storage.DefaultTrashRetention when trash_retention is not set
+func (c *Config) TrashRetentionDuration() (time.Duration, error) {
+	value := strings.TrimSpace(c.TrashRetention)
+	if value == "" {
+		return storage.DefaultTrashRetention, nil
+	}
+	d, err := parseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trash_retention %q: expected a duration such as 30d or 12h", c.TrashRetention)
+	}
+	return d, nil
+}
+
+// HistoryRetention returns how many versions of each note are kept and for
+// how long, 0 meaning no limit
+func (c *Config) HistoryRetention() (maxVersions int, maxAge time.Duration, err error) {
+	maxVersions = c.HistoryMaxVersions
+	switch {
+	case maxVersions == 0:
+		maxVersions = storage.DefaultHistoryVersions
+	case maxVersions < 0:
+		maxVersions = 0
+	}
+
+	value := strings.TrimSpace(c.HistoryMaxAge)
+	if value == "" {
+		return maxVersions, 0, nil
+	}
+	maxAge, err = parseDuration(value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid history_max_age %q: expected a duration such as 90d or 12h", c.HistoryMaxAge)
+	}
+	return maxVersions, maxAge, nil
+}
+
+// CommitDelay returns how long git vaults wait for more changes before committing
+// It defaults to storage.DefaultCommitDelay when git_commit_delay is not set
+func (c *Config) CommitDelay() (time.Duration, error) {
+	value := strings.TrimSpace(c.GitCommitDelay)
+	if value == "" {
+		return storage.DefaultCommitDelay, nil
+	}
+	d, err := parseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid git_commit_delay %q: expected a duration such as 10s", c.GitCommitDelay)
+	}
+	return d, nil
+}
+
+// FilenameStrategy returns how note files are named
+// It defaults to storage.FilenamesUUID when filenames is not set
+func (c *Config) FilenameStrategy() (storage.FilenameStrategy, error) {
+	strategy, err := storage.ParseFilenameStrategy(c.Filenames)
+	if err != nil {
+		return "", fmt.Errorf("invalid filenames %q: expected uuid, slug or date", c.Filenames)
+	}
+	return strategy, nil
+}
+
+// Journal returns how daily journal entries are created
+func (c *Config) Journal() storage.Journal {
+	template := strings.TrimSpace(c.JournalTemplate)
+	if template == "" {
+		template = storage.DefaultJournalTemplate
+	}
+	return storage.Journal{Template: template, Folder: strings.Trim(strings.TrimSpace(c.JournalFolder), "/")}
+}
+
+// parseDuration parses a duration such as "30d", "12h", "10s" or "0"
+func parseDuration(value string) (time.Duration, error) {
+	if value == "0" {
+		return 0, nil
+	}
+
+	// time.ParseDuration doesn't know days
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, errors.New("invalid duration")
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
+// ResolveVault picks the vault to open
+// Precedence: dir flag, vault flag, $LEAF_DIR, default vault, ~/.leaf/notes/
+func (c *Config) ResolveVault(dir, vaultName string) (Vault, error) {
+	if dir != "" {
+		return vaultForDir(c, ExpandHome(dir)), nil
+	}
+
+	if vaultName != "" {
+		v, ok := c.Vault(vaultName)
+		if !ok {
+			return Vault{}, fmt.Errorf("unknown vault: %s", vaultName)
+		}
+		return v, nil
+	}
+
+	if envDir := os.Getenv(EnvDir); envDir != "" {
+		return vaultForDir(c, ExpandHome(envDir)), nil
+	}
+
+	if c.DefaultVault != "" {
+		v, ok := c.Vault(c.DefaultVault)
+		if !ok {
+			return Vault{}, fmt.Errorf("unknown default vault: %s", c.DefaultVault)
+		}
+		return v, nil
+	}
+
+	notesDir, err := storage.DefaultNotesDir()
+	if err != nil {
+		return Vault{}, err
+	}
+	return vaultForDir(c, notesDir), nil
+}
+
+// vaultForDir returns the configured vault pointing at dir, or an ad-hoc one named after it
+func vaultForDir(c *Config, dir string) Vault {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+
+	for _, v := range c.Vaults {
+		if vAbs, err := filepath.Abs(v.Path); err == nil && vAbs == abs {
+			return v
+		}
+	}
+	return Vault{Name: filepath.Base(abs), Path: abs}
+}
@@ -0,0 +1,199 @@
+package diff
+
+import "strings"
+
+// Kind tells how a line differs between two texts
+type Kind int
+
+const (
+	Equal  Kind = iota // The line is in both texts
+	Delete             // The line is only in the old text
+	Insert             // The line is only in the new text
+)
+
+// Line is a line of a diff
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Lines compares two texts line by line and returns the edit script turning
+// old into new, built from their longest common subsequence
+func Lines(old, new string) []Line {
+	a, b := SplitLines(old), SplitLines(new)
+	match := lcs(a, b)
+
+	var lines []Line
+	j := 0
+	for i, line := range a {
+		if match[i] < 0 {
+			lines = append(lines, Line{Kind: Delete, Text: line})
+			continue
+		}
+		for ; j < match[i]; j++ {
+			lines = append(lines, Line{Kind: Insert, Text: b[j]})
+		}
+		lines = append(lines, Line{Kind: Equal, Text: line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Kind: Insert, Text: b[j]})
+	}
+	return lines
+}
+
+// SplitLines splits text into lines without their line endings
+// An empty text has no lines
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// maxCost bounds the edit distance searched for between two blocks of lines
+// Blocks further apart than that are replaced whole, which keeps diffing
+// unrelated texts fast at the cost of a longer script
+const maxCost = 4096
+
+// lcs matches the lines of a and b along a longest common subsequence, found
+// with Myers' algorithm in linear space
+// match[i] is the index in b of the line matched with a[i], or -1
+func lcs(a, b []string) []int {
+	// Lines are compared as numbers
+	ids := map[string]int{}
+	d := &differ{a: lineIDs(a, ids), b: lineIDs(b, ids), match: make([]int, len(a))}
+	for i := range d.match {
+		d.match[i] = -1
+	}
+	d.compare(0, len(a), 0, len(b))
+	return d.match
+}
+
+// lineIDs numbers lines, giving equal lines the same number
+func lineIDs(lines []string, ids map[string]int) []int {
+	out := make([]int, len(lines))
+	for i, line := range lines {
+		id, ok := ids[line]
+		if !ok {
+			id = len(ids)
+			ids[line] = id
+		}
+		out[i] = id
+	}
+	return out
+}
+
+// differ holds the lines being compared and the matches found so far
+type differ struct {
+	a, b  []int
+	match []int
+	// Furthest x reached on each diagonal, forward and backward
+	forward, backward []int
+}
+
+// compare matches the lines of a[aLo:aHi] and b[bLo:bHi]
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// Common prefix and suffix
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.match[aLo] = bLo
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+		d.match[aHi] = bHi
+	}
+	if aLo == aHi || bLo == bHi {
+		return
+	}
+
+	x, y, ok := d.bisect(aLo, aHi, bLo, bHi)
+	if !ok {
+		// Nothing in common, or too far apart: the block is replaced
+		return
+	}
+	d.compare(aLo, x, bLo, y)
+	d.compare(x, aHi, y, bHi)
+}
+
+// bisect finds where a shortest edit script of a[aLo:aHi] into b[bLo:bHi]
+// crosses its middle, searching from both ends at once
+// It reports false when the blocks have no line in common or are more than
+// maxCost edits apart
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := min((n+m+1)/2, maxCost)
+	offset := maxD + 1
+	size := 2*maxD + 3
+	if cap(d.forward) < size {
+		d.forward, d.backward = make([]int, size), make([]int, size)
+	}
+	forward, backward := d.forward[:size], d.backward[:size]
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// With an odd delta the paths meet while going forward, else backward
+	odd := delta%2 != 0
+	// Diagonals that left the grid are no longer searched
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for e := 0; e < maxD; e++ {
+		for k := -e + fStart; k <= e-fEnd; k += 2 {
+			var x int
+			if k == -e || (k != e && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if i := offset + delta - k; i >= 0 && i < size && backward[i] != -1 && x >= n-backward[i] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		for k := -e + bStart; k <= e-bEnd; k += 2 {
+			var x int
+			if k == -e || (k != e && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aHi-x-1] == d.b[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if i := offset + delta - k; i >= 0 && i < size && forward[i] != -1 {
+					fx := forward[i]
+					if fx >= n-x {
+						return aLo + fx, bLo + fx - (i - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
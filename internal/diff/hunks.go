@@ -0,0 +1,112 @@
+package diff
+
+// Hunk is a group of changes with the unchanged lines around them, as in a
+// unified diff
+type Hunk struct {
+	OldStart, OldLines int // 1-based first line and line count in the old text
+	NewStart, NewLines int // Same in the new text
+	Lines              []Line
+}
+
+// Hunks groups the changes of a diff, keeping context unchanged lines around
+// each of them; changes closer than twice the context share a hunk
+func Hunks(lines []Line, context int) []Hunk {
+	var hunks []Hunk
+	var current *Hunk
+	oldLine, newLine := 1, 1 // Line numbers before lines[i]
+	lastChange := -1
+
+	for i, line := range lines {
+		if line.Kind != Equal {
+			if current == nil || i-lastChange > 2*context {
+				// Start a new hunk with the context before this change
+				start := max(i-context, lastChange+1, 0)
+				if current != nil {
+					hunks = append(hunks, *current)
+				}
+				current = &Hunk{
+					OldStart: oldLine - (i - start),
+					NewStart: newLine - (i - start),
+				}
+				for _, ctx := range lines[start:i] {
+					current.add(ctx)
+				}
+			} else {
+				// Bridge the unchanged lines since the previous change, past
+				// those already added as its trailing context
+				for _, ctx := range lines[min(lastChange+1+context, i):i] {
+					current.add(ctx)
+				}
+			}
+			current.add(line)
+			lastChange = i
+		} else if current != nil && i-lastChange <= context {
+			current.add(line)
+		}
+
+		if line.Kind != Insert {
+			oldLine++
+		}
+		if line.Kind != Delete {
+			newLine++
+		}
+	}
+
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+	return hunks
+}
+
+// add appends a line to the hunk and counts it
+func (h *Hunk) add(line Line) {
+	h.Lines = append(h.Lines, line)
+	if line.Kind != Insert {
+		h.OldLines++
+	}
+	if line.Kind != Delete {
+		h.NewLines++
+	}
+}
+
+// Row is a line of a side-by-side diff; Old or New is nil when the line only
+// exists on the other side
+type Row struct {
+	Old, New *Line
+}
+
+// SideBySide lays a diff out in two columns, pairing the lines deleted from
+// the old text with the lines inserted in their place
+func SideBySide(lines []Line) []Row {
+	var rows []Row
+	var deleted, inserted []*Line
+
+	flush := func() {
+		for i := 0; i < max(len(deleted), len(inserted)); i++ {
+			var row Row
+			if i < len(deleted) {
+				row.Old = deleted[i]
+			}
+			if i < len(inserted) {
+				row.New = inserted[i]
+			}
+			rows = append(rows, row)
+		}
+		deleted, inserted = nil, nil
+	}
+
+	for i := range lines {
+		line := &lines[i]
+		switch line.Kind {
+		case Delete:
+			deleted = append(deleted, line)
+		case Insert:
+			inserted = append(inserted, line)
+		default:
+			flush()
+			rows = append(rows, Row{Old: line, New: line})
+		}
+	}
+	flush()
+	return rows
+}
@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict markers written around the lines both sides changed differently
+const (
+	MarkerOurs   = "<<<<<<< yours"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>> on disk"
+)
+
+// Merge3 merges the changes made to base in ours and in theirs, line by line
+// Changes made on one side only are applied; lines changed differently on
+// both sides are kept with conflict markers, and conflicts counts them
+func Merge3(base, ours, theirs string) (merged string, conflicts int) {
+	o, a, b := SplitLines(base), SplitLines(ours), SplitLines(theirs)
+	matchA, matchB := lcs(o, a), lcs(o, b)
+
+	var out []string
+	i, ia, ib := 0, 0, 0
+	for i < len(o) || ia < len(a) || ib < len(b) {
+		// Lines unchanged on both sides are copied as is
+		if i < len(o) && matchA[i] == ia && matchB[i] == ib {
+			out = append(out, o[i])
+			i, ia, ib = i+1, ia+1, ib+1
+			continue
+		}
+
+		// Otherwise the chunk runs up to the next base line kept on both sides
+		end, endA, endB := len(o), len(a), len(b)
+		for j := i; j < len(o); j++ {
+			if matchA[j] >= 0 && matchB[j] >= 0 {
+				end, endA, endB = j, matchA[j], matchB[j]
+				break
+			}
+		}
+
+		chunkO, chunkA, chunkB := o[i:end], a[ia:endA], b[ib:endB]
+		switch {
+		case slices.Equal(chunkA, chunkO):
+			out = append(out, chunkB...)
+		case slices.Equal(chunkB, chunkO), slices.Equal(chunkA, chunkB):
+			out = append(out, chunkA...)
+		default:
+			out = append(out, MarkerOurs)
+			out = append(out, chunkA...)
+			out = append(out, MarkerSep)
+			out = append(out, chunkB...)
+			out = append(out, MarkerTheirs)
+			conflicts++
+		}
+		i, ia, ib = end, endA, endB
+	}
+
+	merged = strings.Join(out, "\n")
+	if merged != "" && strings.HasSuffix(ours, "\n") {
+		merged += "\n"
+	}
+	return merged, conflicts
+}
@@ -0,0 +1,36 @@
+package editor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// fallbackEditor is used when neither $VISUAL nor $EDITOR is set
+const fallbackEditor = "vi"
+
+// ErrNoFile is returned when a note has no file to open
+var ErrNoFile = errors.New("note has no file")
+
+// Name returns the user's editor command line: $VISUAL, then $EDITOR, then vi
+func Name() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return fallbackEditor
+}
+
+// Command builds the command opening path in the user's editor
+// The editor may include arguments, such as "code --wait"
+func Command(path string) (*exec.Cmd, error) {
+	if path == "" {
+		return nil, ErrNoFile
+	}
+
+	fields := strings.Fields(Name())
+	args := append(fields[1:], path)
+	return exec.Command(fields[0], args...), nil
+}
@@ -0,0 +1,237 @@
+package fuzzy
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Scoring weights
+const (
+	scoreMatch       = 16 // Every matched character
+	bonusWordStart   = 10 // Match at the start of a word
+	bonusFirstChar   = 6  // Extra bonus when the match is the first character
+	bonusConsecutive = 12 // Match right after the previous one (beats a word start)
+	penaltyGap       = 1  // Per skipped character between two matches
+	penaltyLeading   = 1  // Per character before the first match (capped)
+	maxLeadingGap    = 8
+)
+
+// Match is a candidate matched by a pattern
+type Match struct {
+	Index     int   // Index of the candidate in the input slice
+	Score     int   // Higher is better
+	Positions []int // Rune indexes of the matched characters in the candidate
+}
+
+// Matcher scores candidates against patterns
+// It keeps a lowercased copy of the candidates so repeated searches don't redo that work
+// A Matcher is not safe for concurrent use
+type Matcher struct {
+	candidates [][]rune
+	lowered    [][]rune
+	scratch    scratch
+}
+
+// scratch holds the dynamic programming tables, reused across candidates
+type scratch struct {
+	score []int
+	from  []int
+}
+
+// tables returns score and from tables of n rows of m columns
+func (s *scratch) tables(n, m int) ([][]int, [][]int) {
+	size := n * m
+	if cap(s.score) < size {
+		s.score = make([]int, size)
+		s.from = make([]int, size)
+	}
+
+	score := make([][]int, n)
+	from := make([][]int, n)
+	for i := 0; i < n; i++ {
+		score[i] = s.score[i*m : (i+1)*m]
+		from[i] = s.from[i*m : (i+1)*m]
+	}
+	return score, from
+}
+
+// NewMatcher prepares candidates for matching
+func NewMatcher(candidates []string) *Matcher {
+	m := &Matcher{
+		candidates: make([][]rune, len(candidates)),
+		lowered:    make([][]rune, len(candidates)),
+	}
+	for i, candidate := range candidates {
+		runes := []rune(candidate)
+		m.candidates[i] = runes
+		m.lowered[i] = lowerRunes(runes)
+	}
+	return m
+}
+
+// Find returns the candidates matching pattern, best first
+// Ties keep the candidates' original order; an empty pattern matches everything
+func (m *Matcher) Find(pattern string) []Match {
+	needle := lowerRunes([]rune(pattern))
+
+	var matches []Match
+	for i := range m.candidates {
+		if len(needle) == 0 {
+			matches = append(matches, Match{Index: i})
+			continue
+		}
+		if score, positions, ok := match(needle, m.candidates[i], m.lowered[i], &m.scratch); ok {
+			matches = append(matches, Match{Index: i, Score: score, Positions: positions})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].Score > matches[b].Score
+	})
+	return matches
+}
+
+// Find matches pattern against candidates, best first
+func Find(pattern string, candidates []string) []Match {
+	return NewMatcher(candidates).Find(pattern)
+}
+
+// MatchString scores a single candidate
+func MatchString(pattern, candidate string) (Match, bool) {
+	runes := []rune(candidate)
+	score, positions, ok := match(lowerRunes([]rune(pattern)), runes, lowerRunes(runes), &scratch{})
+	if !ok {
+		return Match{}, false
+	}
+	return Match{Score: score, Positions: positions}, true
+}
+
+// match finds the best alignment of needle as a subsequence of the candidate
+// It runs a dynamic program over (needle index, candidate index) in O(len(needle)*len(candidate))
+func match(needle, original, lowered []rune, buf *scratch) (int, []int, bool) {
+	n, m := len(needle), len(lowered)
+	if n == 0 {
+		return 0, nil, true
+	}
+	if n > m || !isSubsequence(needle, lowered) {
+		return 0, nil, false
+	}
+
+	const none = -1 << 30
+
+	// score[i][j]: best score with needle[i] matched at lowered[j]
+	// from[i][j]: where needle[i-1] was matched on that best path
+	score, from := buf.tables(n, m)
+
+	for j := 0; j < m; j++ {
+		score[0][j] = none
+		if lowered[j] == needle[0] {
+			leading := j
+			if leading > maxLeadingGap {
+				leading = maxLeadingGap
+			}
+			score[0][j] = scoreMatch + bonusAt(original, j) - leading*penaltyLeading
+			if j == 0 {
+				score[0][j] += bonusFirstChar
+			}
+		}
+	}
+
+	for i := 1; i < n; i++ {
+		// best tracks max(score[i-1][k] + k*penaltyGap) over k <= j-2,
+		// so a gap from k to j costs (j-k-1)*penaltyGap in O(1)
+		best, bestK := none, -1
+
+		for j := 0; j < m; j++ {
+			score[i][j] = none
+
+			if j >= 2 && score[i-1][j-2] != none {
+				if v := score[i-1][j-2] + (j-2)*penaltyGap; v > best {
+					best, bestK = v, j-2
+				}
+			}
+
+			if lowered[j] != needle[i] || j < i {
+				continue
+			}
+
+			candidate, parent := none, -1
+			if best != none {
+				candidate, parent = best-(j-1)*penaltyGap, bestK
+			}
+			if j >= 1 && score[i-1][j-1] != none {
+				if v := score[i-1][j-1] + bonusConsecutive; v >= candidate {
+					candidate, parent = v, j-1
+				}
+			}
+			if candidate == none {
+				continue
+			}
+
+			score[i][j] = candidate + scoreMatch + bonusAt(original, j)
+			from[i][j] = parent
+		}
+	}
+
+	// Pick the best end position and walk back
+	bestScore, end := none, -1
+	for j := 0; j < m; j++ {
+		if score[n-1][j] > bestScore {
+			bestScore, end = score[n-1][j], j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		positions[i] = end
+		end = from[i][end]
+	}
+
+	return bestScore, positions, true
+}
+
+// bonusAt returns the word-start bonus for a match at index j
+func bonusAt(runes []rune, j int) int {
+	if j == 0 {
+		return bonusWordStart
+	}
+
+	prev, cur := runes[j-1], runes[j]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur)):
+		// After a separator: "foo bar", "foo-bar", "foo/bar"
+		return bonusWordStart
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		// camelCase
+		return bonusWordStart
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return bonusWordStart / 2
+	}
+	return 0
+}
+
+// isSubsequence reports whether needle appears in order in haystack
+func isSubsequence(needle, haystack []rune) bool {
+	i := 0
+	for _, r := range haystack {
+		if r == needle[i] {
+			i++
+			if i == len(needle) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lowerRunes returns a lowercased copy of runes, keeping one rune per rune
+func lowerRunes(runes []rune) []rune {
+	lowered := make([]rune, len(runes))
+	for i, r := range runes {
+		lowered[i] = unicode.ToLower(r)
+	}
+	return lowered
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultFileMode is the permission of note files unless WithFileMode says otherwise
+	DefaultFileMode os.FileMode = 0644
+
+	// tempMarker is part of every temporary file name, so leftovers can be found
+	// Temporary files are also hidden, so they never look like notes
+	tempMarker = ".tmp-"
+
+	// staleTempAge is how old a temporary file must be before Recover removes it,
+	// so a write in progress in another leaf process is left alone
+	staleTempAge = time.Minute
+)
+
+// LocalOption configures a LocalFileSystem
+type LocalOption func(*LocalFileSystem)
+
+// WithFileMode sets the permission of the files written in the vault
+// Directories get the matching mode with execute bits, e.g. 0600 gives 0700
+func WithFileMode(mode os.FileMode) LocalOption {
+	return func(fs *LocalFileSystem) {
+		fs.fileMode = mode.Perm()
+	}
+}
+
+// dirMode returns the directory permission matching a file permission
+func dirMode(fileMode os.FileMode) os.FileMode {
+	return fileMode | (fileMode&0444)>>2
+}
+
+// writeFileAtomic replaces path with data so that readers, and the file after
+// a crash, see either the old or the new content, never a mix
+// The data goes to a temporary file in the same directory, which is synced to
+// disk and renamed over path, then the directory itself is synced
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+tempMarker+"*")
+	if err != nil {
+		return err
+	}
+	// Harmless once the rename succeeded
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes a directory entry change (such as a rename) to disk
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		// Directories can't be opened for syncing; NTFS journals renames
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// isTempFile reports whether name is a temporary file left by writeFileAtomic
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.Contains(name, tempMarker)
+}
+
+// Recover removes the temporary files left behind by interrupted writes and
+// returns their paths
+// The notes themselves are intact: a write only replaces a note once complete
+func (fs *LocalFileSystem) Recover() ([]string, error) {
+	var removed []string
+	var removeErr error
+
+	// Temporary files sit next to the file being written, which may be in
+	// any folder of the vault, in the history of a note under .leaf, or in .trash
+	err := filepath.WalkDir(fs.notesDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if path == fs.notesDir {
+				return err
+			}
+			// An unreadable folder holds nothing we could remove
+			return nil
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isTempFile(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < staleTempAge {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			removeErr = fmt.Errorf("could not remove %s: %w", path, err)
+			return filepath.SkipAll
+		}
+		removed = append(removed, path)
+		return nil
+	})
+	if removeErr != nil {
+		return removed, removeErr
+	}
+	if os.IsNotExist(err) {
+		return removed, nil
+	}
+	if err != nil {
+		return removed, fmt.Errorf("could not scan %s: %w", fs.notesDir, err)
+	}
+
+	return removed, nil
+}
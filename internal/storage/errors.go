@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// Errors returned by FileSystem implementations, wrapped with the details of
+// the failing operation; check for them with errors.Is
+var (
+	// ErrNotFound is returned when no note has the requested ID
+	ErrNotFound = errors.New("note not found")
+
+	// ErrConflict is returned when a note changed on disk since it was loaded
+	ErrConflict = errors.New("note changed on disk")
+
+	// ErrInvalidID is returned when a note ID can't name a note file
+	ErrInvalidID = errors.New("invalid note ID")
+
+	// ErrReadOnly is returned when the notes can't be written
+	ErrReadOnly = errors.New("notes are read-only")
+
+	// ErrCorrupt is returned when a note file can't be read as a note
+	ErrCorrupt = errors.New("note file is corrupt")
+)
+
+// readError attaches ErrNotFound to the error of reading a missing note file
+// The path is left out, the caller already names the note
+func readError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// writeError attaches ErrReadOnly to the error of writing where we may not
+func writeError(err error) error {
+	if errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS) {
+		return fmt.Errorf("%w: %w", ErrReadOnly, err)
+	}
+	return err
+}
+
+// revisionOf returns the revision token of a note file's content
+func revisionOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
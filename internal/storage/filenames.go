@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSlugLength keeps slugged file names short enough to read
+const maxSlugLength = 80
+
+// FilenameStrategy decides how the files of new and renamed notes are named
+// Whatever the file name, the ID of a note is kept in its frontmatter
+type FilenameStrategy string
+
+const (
+	FilenamesUUID FilenameStrategy = "uuid" // {id}.md, the default
+	FilenamesSlug FilenameStrategy = "slug" // {title-slug}.md
+	FilenamesDate FilenameStrategy = "date" // {yyyy-mm-dd}-{title-slug}.md, from the creation date
+)
+
+// ParseFilenameStrategy returns the strategy named s, FilenamesUUID for ""
+func ParseFilenameStrategy(s string) (FilenameStrategy, error) {
+	switch strategy := FilenameStrategy(strings.ToLower(strings.TrimSpace(s))); strategy {
+	case "":
+		return FilenamesUUID, nil
+	case FilenamesUUID, FilenamesSlug, FilenamesDate:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown filename strategy %q: expected uuid, slug or date", s)
+}
+
+// WithFilenames sets how the files of new and renamed notes are named
+func WithFilenames(strategy FilenameStrategy) LocalOption {
+	return func(fs *LocalFileSystem) {
+		fs.filenames = strategy
+	}
+}
+
+// PathIndex is implemented by storages whose note files are not necessarily
+// named after the note ID
+type PathIndex interface {
+	// NoteIDAt returns the ID of the note stored at path, or last seen there
+	// when the file is gone
+	NoteIDAt(path string) (string, bool)
+}
+
+// cachedID is the ID read from a note file, valid while the file is unchanged
+type cachedID struct {
+	id      string
+	modTime int64
+	size    int64
+}
+
+// Slugify turns a title into a file name: lowercase words without accents,
+// joined by dashes, "untitled" when nothing is left
+func Slugify(title string) string {
+	slug := ""
+	for _, word := range tokenize(title) {
+		if slug != "" && len(slug)+1+len(word) > maxSlugLength {
+			break
+		}
+		if slug != "" {
+			slug += "-"
+		}
+		slug += word
+	}
+	if len(slug) > maxSlugLength {
+		// A single long word: cut it on a character boundary
+		slug = strings.ToValidUTF8(slug[:maxSlugLength], "")
+	}
+	if slug == "" {
+		return "untitled"
+	}
+	return slug
+}
+
+// fileName returns the name, without extension, the filename strategy gives note
+func (fs *LocalFileSystem) fileName(note *Note) string {
+	switch fs.filenames {
+	case FilenamesSlug:
+		return Slugify(note.Title)
+	case FilenamesDate:
+		created := note.CreatedAt
+		if created.IsZero() {
+			created = note.UpdatedAt
+		}
+		return created.Format("2006-01-02") + "-" + Slugify(note.Title)
+	}
+	return note.ID
+}
+
+// freePath returns the path of a file named name in folder, adding -2, -3, ...
+// to the name while it is taken by another file than current
+func (fs *LocalFileSystem) freePath(folder, name, current string) (string, error) {
+	dir, err := fs.folderDir(folder)
+	if err != nil {
+		return "", err
+	}
+
+	for n := 1; ; n++ {
+		candidate := name
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", name, n)
+		}
+		if err := validateID(candidate); err != nil {
+			return "", err
+		}
+		path := filepath.Join(dir, candidate+".md")
+		if filepath.Dir(path) != dir {
+			return "", fmt.Errorf("%w: %q escapes the notes directory", ErrInvalidID, candidate)
+		}
+		if path == current {
+			return path, fs.checkWithin(path)
+		}
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			return path, fs.checkWithin(path)
+		}
+	}
+}
+
+// fileID returns the ID of the note in the file at path: the one of its
+// frontmatter, or else the file name
+// IDs are cached until the file changes, so walking the vault again is cheap
+func (fs *LocalFileSystem) fileID(path string, info os.FileInfo) string {
+	modTime, size := info.ModTime().UnixNano(), info.Size()
+
+	fs.locMu.Lock()
+	cached, ok := fs.fileIDs[path]
+	fs.locMu.Unlock()
+	if ok && cached.modTime == modTime && cached.size == size {
+		return cached.id
+	}
+
+	id := strings.TrimSuffix(filepath.Base(path), ".md")
+	if data, err := os.ReadFile(path); err == nil {
+		id = frontmatterID(string(data), id)
+	}
+
+	fs.locMu.Lock()
+	if fs.fileIDs == nil {
+		fs.fileIDs = make(map[string]cachedID)
+	}
+	fs.fileIDs[path] = cachedID{id: id, modTime: modTime, size: size}
+	fs.locMu.Unlock()
+	return id
+}
+
+// cacheID remembers that the file at path holds note id, as just written
+func (fs *LocalFileSystem) cacheID(path, id string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	fs.locMu.Lock()
+	if fs.fileIDs == nil {
+		fs.fileIDs = make(map[string]cachedID)
+	}
+	fs.fileIDs[path] = cachedID{id: id, modTime: info.ModTime().UnixNano(), size: info.Size()}
+	fs.locMu.Unlock()
+}
+
+// frontmatterID returns the ID in the frontmatter of content, or fallback
+// when there is none or it can't name a note
+func frontmatterID(content, fallback string) string {
+	block, _, ok := splitFrontmatter(content)
+	if !ok {
+		return fallback
+	}
+	fm, _, err := decodeFrontmatter(block)
+	if err != nil || validateID(fm.ID) != nil {
+		return fallback
+	}
+	return fm.ID
+}
+
+// NoteIDAt returns the ID of the note stored at path, or last seen there
+func (fs *LocalFileSystem) NoteIDAt(path string) (string, bool) {
+	if info, err := os.Stat(path); err == nil {
+		if !strings.HasSuffix(path, ".md") || !isWithin(fs.notesDir, path) {
+			return "", false
+		}
+		return fs.fileID(path, info), true
+	}
+
+	fs.locMu.Lock()
+	defer fs.locMu.Unlock()
+	cached, ok := fs.fileIDs[path]
+	return cached.id, ok
+}
+
+// indexedPath returns where the search index last saw note id
+func (fs *LocalFileSystem) indexedPath(id string) (string, bool) {
+	fs.indexMu.Lock()
+	defer fs.indexMu.Unlock()
+
+	doc, ok := fs.loadIndex().Docs[id]
+	if !ok {
+		return "", false
+	}
+	return doc.Path, true
+}
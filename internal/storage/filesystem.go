@@ -5,17 +5,21 @@ import (
 )
 
 // FileSystem defines the interface for note storage operations
+// Implementations wrap ErrNotFound, ErrConflict, ErrInvalidID, ErrReadOnly
+// and ErrCorrupt in the errors they return
 type FileSystem interface {
 	// ListNotes returns the list of all notes
 	ListNotes(ctx context.Context) ([]*Note, error)
 
-	// GetNote retrieves a note by its ID
+	// GetNote retrieves a note by its ID, failing with ErrNotFound if there is none
 	GetNote(ctx context.Context, id string) (*Note, error)
 
 	// SaveNote saves a note (create or update)
+	// It fails with ErrConflict if the note changed since it was loaded
 	SaveNote(ctx context.Context, note *Note) error
 
 	// DeleteNote deletes a note by its ID
+	// Storages implementing Trash keep it in the trash
 	DeleteNote(ctx context.Context, id string) error
 
 	// SearchNotes searches notes by title or content
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Folders is implemented by storages that organize notes in nested folders
+// Folder paths use forward slashes and are relative to the vault, "" being its root
+type Folders interface {
+	// ListFolders returns every folder of the vault, empty ones included, sorted
+	ListFolders(ctx context.Context) ([]string, error)
+
+	// CreateFolder creates a folder and its missing parents
+	CreateFolder(ctx context.Context, folder string) error
+
+	// RenameFolder renames or moves a folder with the notes it holds
+	// It fails with ErrConflict if to exists already
+	RenameFolder(ctx context.Context, from, to string) error
+
+	// MoveNote moves a note to another folder, keeping its ID
+	MoveNote(ctx context.Context, id, folder string) (*Note, error)
+}
+
+// ListFolders returns every folder of the vault, empty ones included, sorted
+func (fs *LocalFileSystem) ListFolders(ctx context.Context) ([]string, error) {
+	var folders []string
+	err := filepath.WalkDir(fs.notesDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if path == fs.notesDir {
+				return err
+			}
+			return nil
+		}
+		if !entry.IsDir() || path == fs.notesDir {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(fs.notesDir, path)
+		if err != nil {
+			return nil
+		}
+		folder := filepath.ToSlash(rel)
+		if _, err := cleanFolder(folder); err != nil {
+			// Not a name a note could be saved under
+			return filepath.SkipDir
+		}
+		folders = append(folders, folder)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list folders: %w", err)
+	}
+
+	sort.Strings(folders)
+	return folders, nil
+}
+
+// CreateFolder creates a folder and its missing parents
+func (fs *LocalFileSystem) CreateFolder(ctx context.Context, folder string) error {
+	dir, err := fs.folderDir(folder)
+	if err != nil {
+		return fmt.Errorf("could not create folder: %w", err)
+	}
+	if err := os.MkdirAll(dir, dirMode(fs.fileMode)); err != nil {
+		return fmt.Errorf("could not create folder %s: %w", folder, writeError(err))
+	}
+	return nil
+}
+
+// RenameFolder renames or moves a folder with the notes it holds
+// The notes keep their IDs, so their history and links follow them
+func (fs *LocalFileSystem) RenameFolder(ctx context.Context, from, to string) error {
+	from, err := cleanFolder(from)
+	if err == nil && from == "" {
+		err = fmt.Errorf("%w: the root of the vault can't be renamed", ErrInvalidID)
+	}
+	if err != nil {
+		return fmt.Errorf("could not rename folder: %w", err)
+	}
+	to, err = cleanFolder(to)
+	if err == nil && to == "" {
+		err = fmt.Errorf("%w: empty folder name", ErrInvalidID)
+	}
+	if err != nil {
+		return fmt.Errorf("could not rename folder %s: %w", from, err)
+	}
+	if to == from || strings.HasPrefix(to, from+"/") {
+		return fmt.Errorf("could not move folder %s into itself: %w", from, ErrInvalidID)
+	}
+
+	source, err := fs.folderDir(from)
+	if err != nil {
+		return fmt.Errorf("could not rename folder %s: %w", from, err)
+	}
+	target, err := fs.folderDir(to)
+	if err != nil {
+		return fmt.Errorf("could not rename folder %s: %w", from, err)
+	}
+
+	fs.saveMu.Lock()
+	defer fs.saveMu.Unlock()
+
+	if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		return fmt.Errorf("could not rename folder %s: %w", from, ErrNotFound)
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("could not rename folder %s: %s exists: %w", from, to, ErrConflict)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), dirMode(fs.fileMode)); err != nil {
+		return fmt.Errorf("could not rename folder %s: %w", from, writeError(err))
+	}
+	if err := os.Rename(source, target); err != nil {
+		return fmt.Errorf("could not rename folder %s: %w", from, writeError(err))
+	}
+	if err := syncDir(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("could not rename folder %s: %w", from, err)
+	}
+
+	// The notes moved: find them again, and have the next search reindex them
+	_, _ = fs.noteFiles()
+	fs.Invalidate("")
+	return nil
+}
+
+// MoveNote moves a note to another folder, keeping its ID, its content and
+// its file name unless another note has it there
+func (fs *LocalFileSystem) MoveNote(ctx context.Context, id, folder string) (*Note, error) {
+	source, err := fs.notePath(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not move note: %w", err)
+	}
+
+	fs.saveMu.Lock()
+	defer fs.saveMu.Unlock()
+
+	if _, err := os.Lstat(source); err != nil {
+		return nil, fmt.Errorf("could not move note %s: %w", id, readError(err))
+	}
+	target, err := fs.freePath(folder, strings.TrimSuffix(filepath.Base(source), ".md"), source)
+	if err != nil {
+		return nil, fmt.Errorf("could not move note %s: %w", id, err)
+	}
+	if source != target {
+		if err := os.MkdirAll(filepath.Dir(target), dirMode(fs.fileMode)); err != nil {
+			return nil, fmt.Errorf("could not move note %s: %w", id, writeError(err))
+		}
+		if err := os.Rename(source, target); err != nil {
+			return nil, fmt.Errorf("could not move note %s: %w", id, writeError(err))
+		}
+		if err := syncDir(filepath.Dir(target)); err != nil {
+			return nil, fmt.Errorf("could not move note %s: %w", id, err)
+		}
+		fs.setLocation(id, target)
+	}
+
+	note, err := fs.parseNote(target)
+	if err != nil {
+		return nil, fmt.Errorf("could not load moved note %s: %w", id, readError(err))
+	}
+
+	// Keep the search index in sync
+	_ = fs.indexNote(note)
+
+	return note, nil
+}
+
+// restoreFolder returns the folder a trashed note goes back to: the one it
+// was deleted from, or the root if that path is no longer a valid folder
+func (fs *LocalFileSystem) restoreFolder(trashed *TrashedNote) string {
+	if trashed.OriginalPath == "" || !isWithin(fs.notesDir, trashed.OriginalPath) {
+		return ""
+	}
+	folder := fs.folderOf(trashed.OriginalPath)
+	if _, err := fs.folderDir(folder); err != nil {
+		return ""
+	}
+	return folder
+}
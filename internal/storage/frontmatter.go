@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatterDelimiter opens and closes a YAML frontmatter block
+const frontmatterDelimiter = "---"
+
+// frontmatter holds the well-known metadata fields stored at the top of a note file
+type frontmatter struct {
+	ID      string    `yaml:"id,omitempty"`
+	Title   string    `yaml:"title,omitempty"`
+	Created time.Time `yaml:"created,omitempty"`
+	Updated time.Time `yaml:"updated,omitempty"`
+	Tags    []string  `yaml:"tags,omitempty"`
+	Aliases []string  `yaml:"aliases,omitempty"`
+}
+
+// reservedFrontmatterKeys are the keys handled by the frontmatter struct
+// Any other key ends up in Note.Metadata
+var reservedFrontmatterKeys = []string{"id", "title", "created", "updated", "tags", "aliases"}
+
+// splitFrontmatter separates a leading YAML frontmatter block from the rest of the content
+// It returns ok=false when the content doesn't start with a complete block
+func splitFrontmatter(content string) (block string, body string, ok bool) {
+	original := content
+
+	// Normalize Windows line endings so the delimiters are found reliably
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	if !strings.HasPrefix(content, frontmatterDelimiter+"\n") {
+		return "", original, false
+	}
+
+	rest := content[len(frontmatterDelimiter)+1:]
+
+	// The block may be empty ("---\n---\n")
+	if strings.HasPrefix(rest, frontmatterDelimiter+"\n") || rest == frontmatterDelimiter {
+		return "", strings.TrimPrefix(strings.TrimPrefix(rest, frontmatterDelimiter), "\n"), true
+	}
+
+	end := strings.Index(rest, "\n"+frontmatterDelimiter+"\n")
+	if end == -1 {
+		// Allow the closing delimiter to be the last line of the file
+		if strings.HasSuffix(rest, "\n"+frontmatterDelimiter) {
+			return rest[:len(rest)-len(frontmatterDelimiter)-1], "", true
+		}
+		return "", original, false
+	}
+
+	block = rest[:end]
+	body = rest[end+len(frontmatterDelimiter)+2:]
+	return block, body, true
+}
+
+// decodeFrontmatter parses a frontmatter block into the well-known fields
+// and a map with every remaining custom field
+func decodeFrontmatter(block string) (*frontmatter, map[string]interface{}, error) {
+	fm := &frontmatter{}
+	if err := yaml.Unmarshal([]byte(block), fm); err != nil {
+		return nil, nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	var custom map[string]interface{}
+	if err := yaml.Unmarshal([]byte(block), &custom); err != nil {
+		return nil, nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	for _, key := range reservedFrontmatterKeys {
+		delete(custom, key)
+	}
+	if len(custom) == 0 {
+		custom = nil
+	}
+
+	return fm, custom, nil
+}
+
+// encodeFrontmatter renders the note metadata as a delimited YAML block
+// Well-known fields come first in a fixed order, custom fields follow sorted by key
+func encodeFrontmatter(note *Note) (string, error) {
+	fm := frontmatter{
+		ID:      note.ID,
+		Title:   note.Title,
+		Created: note.CreatedAt,
+		Updated: note.UpdatedAt,
+		Tags:    note.Tags,
+		Aliases: note.Aliases,
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontmatterDelimiter + "\n")
+
+	known, err := yaml.Marshal(&fm)
+	if err != nil {
+		return "", fmt.Errorf("could not encode frontmatter: %w", err)
+	}
+	buf.Write(known)
+
+	// Drop custom keys that would shadow a well-known field
+	custom := make(map[string]interface{}, len(note.Metadata))
+	for key, value := range note.Metadata {
+		custom[key] = value
+	}
+	for _, key := range reservedFrontmatterKeys {
+		delete(custom, key)
+	}
+
+	if len(custom) > 0 {
+		extra, err := yaml.Marshal(custom)
+		if err != nil {
+			return "", fmt.Errorf("could not encode frontmatter: %w", err)
+		}
+		buf.Write(extra)
+	}
+
+	buf.WriteString(frontmatterDelimiter + "\n")
+	return buf.String(), nil
+}
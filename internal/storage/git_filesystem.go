@@ -0,0 +1,467 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCommitDelay is how long GitFileSystem waits for more changes before
+// committing, unless WithCommitDelay says otherwise
+const DefaultCommitDelay = 5 * time.Second
+
+// gitIgnored lists the vault directories that never go in the repository
+var gitIgnored = []string{dataDirName + "/", trashDirName + "/"}
+
+// GitFileSystem stores notes like LocalFileSystem in a directory that is a git
+// repository, and commits every change to the notes
+// Changes made within the commit delay of each other share a commit; call
+// Flush or Close to commit pending changes at once
+type GitFileSystem struct {
+	*LocalFileSystem
+
+	delay time.Duration
+
+	// Environment giving an identity to commits when git has none configured
+	identity []string
+
+	// Pending changes, guarded by mu
+	mu      sync.Mutex
+	pending []*gitChange
+	paths   map[string]bool // Note files and folders to commit, relative to the notes directory
+	timer   *time.Timer
+	err     error // Last failed commit, returned by the next Flush
+
+	// Serializes git commands that write to the repository
+	commitMu sync.Mutex
+}
+
+// gitChange is a change to a note or a folder waiting to be committed
+type gitChange struct {
+	id       string // Note ID, or "/" and the path of a folder
+	action   string // "Add", "Update", "Rename", "Move", "Delete" or "Restore"
+	title    string // Title of the note, or path of the folder
+	oldTitle string // Title or path before a rename
+	folder   string // Where the note moved
+}
+
+// GitOption configures a GitFileSystem
+type GitOption func(*GitFileSystem)
+
+// WithCommitDelay sets how long to wait for more changes before committing;
+// 0 commits every change as it is made
+func WithCommitDelay(delay time.Duration) GitOption {
+	return func(g *GitFileSystem) {
+		g.delay = delay
+	}
+}
+
+// NewGitFileSystem commits the changes made to the notes of local
+// The notes directory is made a git repository if it is not in one already
+func NewGitFileSystem(local *LocalFileSystem, opts ...GitOption) (*GitFileSystem, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("could not open git repository: %w", err)
+	}
+
+	g := &GitFileSystem{
+		LocalFileSystem: local,
+		delay:           DefaultCommitDelay,
+		paths:           map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	ctx := context.Background()
+	if _, err := g.git(ctx, "rev-parse", "--git-dir"); err != nil {
+		if _, err := g.git(ctx, "init", "--quiet"); err != nil {
+			return nil, fmt.Errorf("could not create git repository in %s: %w", local.NotesDir(), err)
+		}
+	}
+	if err := g.ignoreDataDirs(ctx); err != nil {
+		return nil, fmt.Errorf("could not open git repository in %s: %w", local.NotesDir(), err)
+	}
+
+	// Commit anyway on machines where git doesn't know who we are
+	name, _ := g.git(ctx, "config", "user.name")
+	email, _ := g.git(ctx, "config", "user.email")
+	if strings.TrimSpace(name) == "" || strings.TrimSpace(email) == "" {
+		g.identity = []string{
+			"GIT_AUTHOR_NAME=leaf", "GIT_AUTHOR_EMAIL=leaf@localhost",
+			"GIT_COMMITTER_NAME=leaf", "GIT_COMMITTER_EMAIL=leaf@localhost",
+		}
+	}
+
+	return g, nil
+}
+
+// ignoreDataDirs keeps the search index, the history and the trash out of git
+// They go in the repository's exclude file, which is not versioned
+func (g *GitFileSystem) ignoreDataDirs(ctx context.Context) error {
+	out, err := g.git(ctx, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return err
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.NotesDir(), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+
+	var missing []string
+	for _, pattern := range gitIgnored {
+		if !containsLine(lines, pattern) {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	data = append(data, "# Added by leaf\n"+strings.Join(missing, "\n")+"\n"...)
+	if err := os.MkdirAll(filepath.Dir(path), dirMode(g.fileMode)); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, g.fileMode)
+}
+
+// containsLine reports whether lines holds line, ignoring surrounding spaces
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveNote saves a note and commits it
+func (g *GitFileSystem) SaveNote(ctx context.Context, note *Note) error {
+	change := &gitChange{id: note.ID, action: "Add", title: note.Title}
+	previous, err := g.LocalFileSystem.GetNote(ctx, note.ID)
+	if err == nil {
+		change.action = "Update"
+		if previous.Title != note.Title {
+			change.action = "Rename"
+			change.oldTitle = previous.Title
+		}
+	}
+
+	if _, err := g.LocalFileSystem.saveNote(note); err != nil {
+		return err
+	}
+	if previous != nil && previous.FilePath != note.FilePath {
+		if change.action == "Update" {
+			change.action = "Move"
+			change.folder = note.Folder
+		}
+		g.stage(change, previous.FilePath, note.FilePath)
+	} else {
+		g.stage(change, note.FilePath)
+	}
+
+	// The notes whose links followed the rename go in the same commit
+	if change.action == "Rename" {
+		linking, _ := g.LocalFileSystem.rewriteLinks(ctx, note, change.oldTitle)
+		for _, source := range linking {
+			g.stage(&gitChange{id: source.ID, action: "Update", title: source.Title}, source.FilePath)
+		}
+	}
+	g.schedule()
+	return nil
+}
+
+// MoveNote moves a note to another folder and commits the move
+func (g *GitFileSystem) MoveNote(ctx context.Context, id, folder string) (*Note, error) {
+	source, err := g.notePath(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not move note: %w", err)
+	}
+	note, err := g.LocalFileSystem.MoveNote(ctx, id, folder)
+	if err != nil {
+		return nil, err
+	}
+	if source != note.FilePath {
+		g.record(&gitChange{id: id, action: "Move", title: note.Title, folder: note.Folder}, source, note.FilePath)
+	}
+	return note, nil
+}
+
+// RenameFolder renames or moves a folder and commits the notes it holds
+func (g *GitFileSystem) RenameFolder(ctx context.Context, from, to string) error {
+	if err := g.LocalFileSystem.RenameFolder(ctx, from, to); err != nil {
+		return err
+	}
+	from, _ = cleanFolder(from)
+	to, _ = cleanFolder(to)
+	g.record(&gitChange{id: "/" + to, action: "Rename", title: to, oldTitle: from},
+		filepath.Join(g.NotesDir(), filepath.FromSlash(from)),
+		filepath.Join(g.NotesDir(), filepath.FromSlash(to)))
+	return nil
+}
+
+// DeleteNote moves a note to the trash and commits its removal
+func (g *GitFileSystem) DeleteNote(ctx context.Context, id string) error {
+	change := &gitChange{id: id, action: "Delete", title: id}
+	if note, err := g.LocalFileSystem.GetNote(ctx, id); err == nil {
+		change.title = note.Title
+	}
+	path, err := g.notePath(id)
+	if err != nil {
+		return fmt.Errorf("could not delete note: %w", err)
+	}
+
+	if err := g.LocalFileSystem.DeleteNote(ctx, id); err != nil {
+		return err
+	}
+	g.record(change, path)
+	return nil
+}
+
+// RestoreNote puts a trashed note back and commits it
+func (g *GitFileSystem) RestoreNote(ctx context.Context, trashID string) (*Note, error) {
+	note, err := g.LocalFileSystem.RestoreNote(ctx, trashID)
+	if err != nil {
+		return nil, err
+	}
+	g.record(&gitChange{id: note.ID, action: "Restore", title: note.Title}, note.FilePath)
+	return note, nil
+}
+
+// record queues a change to the files or folders at paths for the next commit
+func (g *GitFileSystem) record(change *gitChange, paths ...string) {
+	g.stage(change, paths...)
+	g.schedule()
+}
+
+// stage adds a change to the pending ones without scheduling a commit, for
+// changes that must share one
+func (g *GitFileSystem) stage(change *gitChange, paths ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, path := range paths {
+		if rel, err := filepath.Rel(g.NotesDir(), path); err == nil {
+			g.paths[filepath.ToSlash(rel)] = true
+		}
+	}
+	g.pending = mergeChange(g.pending, change)
+}
+
+// schedule commits the pending changes once the commit delay has passed, or
+// at once without delay
+func (g *GitFileSystem) schedule() {
+	g.mu.Lock()
+	if g.delay > 0 {
+		// Wait for the edits to settle
+		if g.timer != nil {
+			g.timer.Stop()
+		}
+		g.timer = time.AfterFunc(g.delay, func() { _ = g.commitPending() })
+		g.mu.Unlock()
+		return
+	}
+	g.mu.Unlock()
+	_ = g.commitPending()
+}
+
+// mergeChange adds change to the pending ones, folding it into an earlier
+// change of the same note
+func mergeChange(pending []*gitChange, change *gitChange) []*gitChange {
+	for i, prev := range pending {
+		if prev.id != change.id {
+			continue
+		}
+
+		merged := *change
+		switch {
+		case change.action == "Delete" && (prev.action == "Add" || prev.action == "Restore"):
+			// Nothing happened as far as the repository knows
+			return append(pending[:i:i], pending[i+1:]...)
+		case change.action == "Delete":
+		case prev.action == "Delete":
+			merged.action = "Update"
+		case prev.action == "Add" || prev.action == "Restore":
+			merged.action = prev.action
+		case prev.action == "Move" && change.action == "Update":
+			merged.action = "Move"
+		case prev.action == "Rename" || change.action == "Rename":
+			merged.oldTitle = prev.oldTitle
+			if prev.action != "Rename" {
+				merged.oldTitle = prev.title
+			}
+			merged.action = "Rename"
+			if merged.oldTitle == merged.title {
+				merged.action = "Update"
+			}
+		}
+		pending[i] = &merged
+		return pending
+	}
+	return append(pending, change)
+}
+
+// commitMessage describes the pending changes
+func commitMessage(changes []*gitChange) string {
+	describe := func(c *gitChange) string {
+		kind := "note"
+		if strings.HasPrefix(c.id, "/") {
+			kind = "folder"
+		}
+		switch c.action {
+		case "Rename":
+			return fmt.Sprintf("Rename %s: %s -> %s", kind, c.oldTitle, c.title)
+		case "Move":
+			if c.folder == "" {
+				return fmt.Sprintf("Move note: %s to the root", c.title)
+			}
+			return fmt.Sprintf("Move note: %s to %s", c.title, c.folder)
+		}
+		return fmt.Sprintf("%s %s: %s", c.action, kind, c.title)
+	}
+
+	switch len(changes) {
+	case 0:
+		return "Update notes"
+	case 1:
+		return describe(changes[0])
+	}
+
+	lines := []string{fmt.Sprintf("Update %d notes", len(changes)), ""}
+	for _, change := range changes {
+		lines = append(lines, "- "+describe(change))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Flush commits the pending changes at once
+// It returns the error of the last commit that failed, if any
+func (g *GitFileSystem) Flush() error {
+	err := g.commitPending()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err == nil {
+		err = g.err
+	}
+	g.err = nil
+	return err
+}
+
+// Close commits the pending changes and writes the search index
+func (g *GitFileSystem) Close() error {
+	err := g.Flush()
+	if closeErr := g.LocalFileSystem.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// commitPending commits the pending changes, keeping them for the next try
+// when the commit fails
+func (g *GitFileSystem) commitPending() error {
+	g.commitMu.Lock()
+	defer g.commitMu.Unlock()
+
+	g.mu.Lock()
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	changes, paths := g.pending, g.paths
+	g.pending, g.paths = nil, map[string]bool{}
+	g.mu.Unlock()
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	err := g.commit(context.Background(), changes, paths)
+	if err != nil {
+		g.mu.Lock()
+		for _, change := range g.pending {
+			changes = mergeChange(changes, change)
+		}
+		for path := range g.paths {
+			paths[path] = true
+		}
+		g.pending, g.paths = changes, paths
+		g.err = err
+		g.mu.Unlock()
+	}
+	return err
+}
+
+// commit stages the note files and folders at paths and commits them, leaving alone
+// whatever else the user staged
+func (g *GitFileSystem) commit(ctx context.Context, changes []*gitChange, paths map[string]bool) error {
+	var present, gone []string
+	for path := range paths {
+		if _, err := os.Lstat(filepath.Join(g.NotesDir(), filepath.FromSlash(path))); err == nil {
+			present = append(present, path)
+		} else {
+			gone = append(gone, path)
+		}
+	}
+
+	if len(present) > 0 {
+		if _, err := g.git(ctx, append([]string{"add", "--"}, present...)...); err != nil {
+			return fmt.Errorf("could not commit notes: %w", err)
+		}
+	}
+	if len(gone) > 0 {
+		if _, err := g.git(ctx, append([]string{"rm", "-r", "--quiet", "--cached", "--ignore-unmatch", "--"}, gone...)...); err != nil {
+			return fmt.Errorf("could not commit notes: %w", err)
+		}
+	}
+
+	// Files saved without changes, or created then deleted, have nothing to commit
+	// Without rename detection both sides of a move are listed
+	out, err := g.git(ctx, append([]string{"diff", "--cached", "--no-renames", "--name-only", "-z", "--"}, append(present, gone...)...)...)
+	if err != nil {
+		return fmt.Errorf("could not commit notes: %w", err)
+	}
+	staged := strings.Split(strings.TrimRight(out, "\x00"), "\x00")
+	if len(staged) == 0 || staged[0] == "" {
+		return nil
+	}
+
+	args := append([]string{"commit", "--quiet", "--no-verify", "-m", commitMessage(changes), "--"}, staged...)
+	if _, err := g.git(ctx, args...); err != nil {
+		return fmt.Errorf("could not commit notes: %w", err)
+	}
+	return nil
+}
+
+// git runs a git command in the notes directory and returns its output
+func (g *GitFileSystem) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", g.NotesDir()}, args...)...)
+	// Note paths may hold *, ? or [, which must not be taken for globs
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C", "GIT_LITERAL_PATHSPECS=1")
+	cmd.Env = append(cmd.Env, g.identity...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
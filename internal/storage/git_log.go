@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uncommittedHash is the hash git blame gives to lines not committed yet
+const uncommittedHash = "0000000000000000000000000000000000000000"
+
+// Commit describes a commit touching a note
+type Commit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Date    time.Time
+	Subject string
+}
+
+// BlameLine is a line of a note with the commit that last changed it
+// Commit is nil for lines not committed yet
+type BlameLine struct {
+	Line   int // 1-based line number in the note file
+	Text   string
+	Commit *Commit
+}
+
+// VersionControl is implemented by storages that keep notes under version control
+type VersionControl interface {
+	// Log returns the commits that changed a note, most recent first
+	Log(ctx context.Context, id string) ([]*Commit, error)
+
+	// Blame returns the lines of a note file with the commit that last changed each
+	Blame(ctx context.Context, id string) ([]*BlameLine, error)
+}
+
+// trackedPath returns the path of note id relative to the notes directory,
+// after committing the pending changes so git knows about the latest save
+func (g *GitFileSystem) trackedPath(id string) (string, error) {
+	path, err := g.notePath(id)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return "", readError(err)
+	}
+	if err := g.Flush(); err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(g.NotesDir(), path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Log returns the commits that changed a note, most recent first, following
+// the note across renames of its file
+func (g *GitFileSystem) Log(ctx context.Context, id string) ([]*Commit, error) {
+	path, err := g.trackedPath(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the log of %s: %w", id, err)
+	}
+
+	// Fields are separated by the unit separator, commits by the record separator
+	out, err := g.git(ctx, "log", "--follow", "--format=%H%x1f%an%x1f%ae%x1f%aI%x1f%s%x1e", "--", path)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read the log of %s: %w", id, err)
+	}
+
+	var commits []*Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		commits = append(commits, &Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    date,
+			Subject: fields[4],
+		})
+	}
+	return commits, nil
+}
+
+// Blame returns the lines of a note file with the commit that last changed each
+func (g *GitFileSystem) Blame(ctx context.Context, id string) ([]*BlameLine, error) {
+	path, err := g.trackedPath(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not blame %s: %w", id, err)
+	}
+
+	out, err := g.git(ctx, "blame", "--line-porcelain", "--", path)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such path") || strings.Contains(err.Error(), "bad revision") {
+			// Never committed
+			return g.uncommittedBlame(path)
+		}
+		return nil, fmt.Errorf("could not blame %s: %w", id, err)
+	}
+	return parseBlame(out), nil
+}
+
+// uncommittedBlame returns the lines of a note file git doesn't know about
+func (g *GitFileSystem) uncommittedBlame(path string) ([]*BlameLine, error) {
+	data, err := os.ReadFile(filepath.Join(g.NotesDir(), filepath.FromSlash(path)))
+	if err != nil {
+		return nil, fmt.Errorf("could not blame %s: %w", path, readError(err))
+	}
+
+	var lines []*BlameLine
+	for i, text := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		lines = append(lines, &BlameLine{Line: i + 1, Text: text})
+	}
+	return lines, nil
+}
+
+// parseBlame reads the output of git blame --line-porcelain
+// Every line starts with a header naming its commit, then the commit details,
+// then the line itself after a tab
+func parseBlame(out string) []*BlameLine {
+	var lines []*BlameLine
+	commits := map[string]*Commit{}
+	var current *BlameLine
+	var commit *Commit
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if text, ok := strings.CutPrefix(line, "\t"); ok {
+			if current != nil {
+				current.Text = text
+				if commit != nil && commit.Hash != uncommittedHash {
+					current.Commit = commit
+				}
+				lines = append(lines, current)
+			}
+			current, commit = nil, nil
+			continue
+		}
+
+		if current == nil {
+			// Header: <hash> <original line> <final line> [<lines in group>]
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				continue
+			}
+			n, _ := strconv.Atoi(fields[2])
+			current = &BlameLine{Line: n}
+			commit = commits[fields[0]]
+			if commit == nil {
+				commit = &Commit{Hash: fields[0]}
+				commits[fields[0]] = commit
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			commit.Author = value
+		case "author-mail":
+			commit.Email = strings.Trim(value, "<>")
+		case "author-time":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				commit.Date = time.Unix(secs, 0)
+			}
+		case "summary":
+			commit.Subject = value
+		}
+	}
+	return lines
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// historyDirName holds the snapshots of every note, one directory per note
+	historyDirName = "history"
+
+	// snapshotExt is the extension of a compressed snapshot
+	snapshotExt = ".md.gz"
+
+	// DefaultHistoryVersions is how many versions of a note are kept unless
+	// WithHistoryRetention says otherwise
+	DefaultHistoryVersions = 50
+)
+
+// Version describes a saved version of a note
+type Version struct {
+	ID      string // Identifies the version among those of the note
+	NoteID  string
+	SavedAt time.Time
+	Size    int64 // Size of the compressed snapshot
+}
+
+// History is implemented by storages that keep the previous versions of notes
+type History interface {
+	// ListVersions returns the saved versions of a note, most recent first
+	ListVersions(ctx context.Context, id string) ([]*Version, error)
+
+	// GetVersion returns a note as it was in one of its versions
+	GetVersion(ctx context.Context, id, versionID string) (*Note, error)
+}
+
+// WithHistoryRetention sets how many versions of each note are kept and for
+// how long; 0 lifts the limit
+func WithHistoryRetention(maxVersions int, maxAge time.Duration) LocalOption {
+	return func(fs *LocalFileSystem) {
+		fs.historyVersions = maxVersions
+		fs.historyAge = maxAge
+	}
+}
+
+// historyDir returns the directory holding the versions of note id
+func (fs *LocalFileSystem) historyDir(id string) string {
+	return filepath.Join(fs.notesDir, dataDirName, historyDirName, id)
+}
+
+// snapshot keeps a compressed copy of a note file just written, then drops
+// the versions past the retention
+func (fs *LocalFileSystem) snapshot(id string, data []byte, savedAt time.Time) error {
+	dir := fs.historyDir(id)
+	if err := os.MkdirAll(dir, dirMode(fs.fileMode)); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, strconv.FormatInt(savedAt.UnixNano(), 10)+snapshotExt)
+	if err := writeFileAtomic(path, buf.Bytes(), fs.fileMode); err != nil {
+		return err
+	}
+	return fs.pruneHistory(id)
+}
+
+// pruneHistory removes the versions of note id past the retention
+func (fs *LocalFileSystem) pruneHistory(id string) error {
+	versions, err := fs.versions(id)
+	if err != nil {
+		return err
+	}
+
+	for i, version := range versions {
+		tooMany := fs.historyVersions > 0 && i >= fs.historyVersions
+		// The latest version always stays, it is the note itself
+		tooOld := fs.historyAge > 0 && i > 0 && time.Since(version.SavedAt) > fs.historyAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(fs.versionPath(id, version.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeHistory forgets every version of note id
+func (fs *LocalFileSystem) removeHistory(id string) error {
+	return os.RemoveAll(fs.historyDir(id))
+}
+
+// versionPath returns the path of a snapshot
+func (fs *LocalFileSystem) versionPath(id, versionID string) string {
+	return filepath.Join(fs.historyDir(id), versionID+snapshotExt)
+}
+
+// versions lists the snapshots of note id, most recent first
+func (fs *LocalFileSystem) versions(id string) ([]*Version, error) {
+	entries, err := os.ReadDir(fs.historyDir(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*Version
+	for _, entry := range entries {
+		versionID, ok := strings.CutSuffix(entry.Name(), snapshotExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		nanos, err := strconv.ParseInt(versionID, 10, 64)
+		if err != nil {
+			continue
+		}
+		version := &Version{ID: versionID, NoteID: id, SavedAt: time.Unix(0, nanos)}
+		if info, err := entry.Info(); err == nil {
+			version.Size = info.Size()
+		}
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].SavedAt.After(versions[j].SavedAt)
+	})
+	return versions, nil
+}
+
+// ListVersions returns the saved versions of a note, most recent first
+func (fs *LocalFileSystem) ListVersions(ctx context.Context, id string) ([]*Version, error) {
+	if err := validateID(id); err != nil {
+		return nil, fmt.Errorf("could not list versions: %w", err)
+	}
+
+	versions, err := fs.versions(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not list versions of %s: %w", id, err)
+	}
+	return versions, nil
+}
+
+// GetVersion returns a note as it was in one of its versions
+// The note has no file path and no revision: saving it creates a new version
+func (fs *LocalFileSystem) GetVersion(ctx context.Context, id, versionID string) (*Note, error) {
+	if err := validateID(id); err != nil {
+		return nil, fmt.Errorf("could not load version: %w", err)
+	}
+	nanos, err := strconv.ParseInt(versionID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not load version %s of %s: %w", versionID, id, ErrNotFound)
+	}
+
+	file, err := os.Open(fs.versionPath(id, versionID))
+	if err != nil {
+		return nil, fmt.Errorf("could not load version %s of %s: %w", versionID, id, readError(err))
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not load version %s of %s: %w: %v", versionID, id, ErrCorrupt, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("could not load version %s of %s: %w: %v", versionID, id, ErrCorrupt, err)
+	}
+
+	note, err := decodeNote(id, "", data, time.Unix(0, nanos))
+	if err != nil {
+		return nil, fmt.Errorf("could not load version %s of %s: %w", versionID, id, err)
+	}
+	note.Revision = ""
+	return note, nil
+}
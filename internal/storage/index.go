@@ -0,0 +1,480 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// dataDirName is the hidden directory inside a vault holding leaf's own files
+	dataDirName = ".leaf"
+
+	// indexFileName is the name of the persisted search index inside dataDirName
+	indexFileName = "index.gob"
+
+	// indexVersion is bumped whenever the index layout or tokenizer changes
+	// An index with another version is discarded and rebuilt
+	indexVersion = 6
+
+	// titleGap is how many positions are left empty between the title and the
+	// content of a note, so that a phrase never spans both
+	titleGap = 10
+
+	// indexWriteBatch is how many notes may change in the index before it is
+	// written to disk again; Close writes the rest
+	indexWriteBatch = 50
+
+	// BM25 tuning parameters (standard values)
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// SearchIndex is implemented by storages that keep a search index of the
+// notes, to be told about the files changed behind their back
+type SearchIndex interface {
+	// Invalidate marks the note file at path as changed outside the storage,
+	// or every note file when path is ""
+	Invalidate(path string)
+}
+
+// searchIndex is an inverted index over note titles and contents
+type searchIndex struct {
+	Version int
+
+	// Docs maps a note ID to its indexed document
+	Docs map[string]*indexedDoc
+
+	// Postings maps a term to the positions where it occurs in each document
+	Postings map[string]map[string][]int
+
+	// TotalLength is the sum of all document lengths, used for BM25
+	TotalLength int
+
+	// sortedTerms caches the sorted term list for prefix lookups (not persisted)
+	sortedTerms []string
+}
+
+// indexedDoc is the index entry of a single note
+type indexedDoc struct {
+	Path        string
+	ModTime     int64 // File modification time (UnixNano) when indexed
+	Size        int64 // File size when indexed
+	Length      int   // Number of tokens
+	TitleLength int   // Number of leading tokens coming from the title
+	Terms       []string
+	Title       string
+	Tags        []string // Frontmatter tags and #hashtags
+	Aliases     []string
+	Links       []string // Targets of the [[links]] of the note
+	Folder      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// newSearchIndex creates an empty index
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		Version:  indexVersion,
+		Docs:     make(map[string]*indexedDoc),
+		Postings: make(map[string]map[string][]int),
+	}
+}
+
+// add indexes a document, replacing any previous version with the same ID
+// The tokens are those of the title, doc.TitleLength of them, then the content
+func (idx *searchIndex) add(id string, doc *indexedDoc, tokens []string) {
+	idx.remove(id)
+
+	doc.Length = len(tokens)
+	doc.Terms = nil
+
+	for i, token := range tokens {
+		pos := i
+		if i >= doc.TitleLength {
+			pos += titleGap
+		}
+		postings, ok := idx.Postings[token]
+		if !ok {
+			postings = make(map[string][]int)
+			idx.Postings[token] = postings
+			idx.sortedTerms = nil
+		}
+		if _, seen := postings[id]; !seen {
+			doc.Terms = append(doc.Terms, token)
+		}
+		postings[id] = append(postings[id], pos)
+	}
+
+	idx.Docs[id] = doc
+	idx.TotalLength += doc.Length
+}
+
+// remove drops a document from the index
+func (idx *searchIndex) remove(id string) {
+	doc, ok := idx.Docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.Terms {
+		postings := idx.Postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(idx.Postings, term)
+			idx.sortedTerms = nil
+		}
+	}
+
+	idx.TotalLength -= doc.Length
+	delete(idx.Docs, id)
+}
+
+// expand returns every indexed term starting with prefix
+func (idx *searchIndex) expand(prefix string) []string {
+	if idx.sortedTerms == nil {
+		idx.sortedTerms = make([]string, 0, len(idx.Postings))
+		for term := range idx.Postings {
+			idx.sortedTerms = append(idx.sortedTerms, term)
+		}
+		sort.Strings(idx.sortedTerms)
+	}
+
+	var terms []string
+	start := sort.SearchStrings(idx.sortedTerms, prefix)
+	for _, term := range idx.sortedTerms[start:] {
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// bm25 returns the BM25 score contribution of term for a document
+func (idx *searchIndex) bm25(term, id string) float64 {
+	postings := idx.Postings[term]
+	tf := float64(len(postings[id]))
+	if tf == 0 {
+		return 0
+	}
+
+	n := float64(len(idx.Docs))
+	df := float64(len(postings))
+	idf := math.Log((n-df+0.5)/(df+0.5) + 1)
+
+	avgLength := 1.0
+	if len(idx.Docs) > 0 && idx.TotalLength > 0 {
+		avgLength = float64(idx.TotalLength) / n
+	}
+	length := float64(idx.Docs[id].Length)
+
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/avgLength))
+}
+
+// rank sorts document IDs by descending score, most recently updated first on ties
+func (idx *searchIndex) rank(scores map[string]float64) []string {
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return idx.Docs[ids[i]].UpdatedAt.After(idx.Docs[ids[j]].UpdatedAt)
+	})
+	return ids
+}
+
+// indexPath returns the path of the persisted search index
+func (fs *LocalFileSystem) indexPath() string {
+	return filepath.Join(fs.notesDir, dataDirName, indexFileName)
+}
+
+// loadIndex reads the persisted index, or returns an empty one when it is
+// missing, unreadable or from another version
+// The caller must hold fs.indexMu
+func (fs *LocalFileSystem) loadIndex() *searchIndex {
+	if fs.index != nil {
+		return fs.index
+	}
+
+	fs.index = newSearchIndex()
+
+	data, err := os.ReadFile(fs.indexPath())
+	if err != nil {
+		return fs.index
+	}
+
+	idx := newSearchIndex()
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(idx); err != nil || idx.Version != indexVersion {
+		// Corrupt or outdated: start over, refreshIndex will rebuild it
+		return fs.index
+	}
+
+	if idx.Docs == nil {
+		idx.Docs = make(map[string]*indexedDoc)
+	}
+	if idx.Postings == nil {
+		idx.Postings = make(map[string]map[string][]int)
+	}
+	fs.index = idx
+	return fs.index
+}
+
+// refreshIndex brings the index up to date with the note files, then
+// persists it if needed
+// The files are all checked the first time and when Invalidate says so;
+// otherwise only the files marked as changed are
+// The caller must hold fs.indexMu
+func (fs *LocalFileSystem) refreshIndex() (*searchIndex, error) {
+	idx := fs.loadIndex()
+
+	changed := false
+	if fs.indexChecked {
+		for path := range fs.indexStale {
+			reindexed, ok := fs.reindexFile(idx, path)
+			if !ok {
+				fs.indexChecked = false
+				break
+			}
+			changed = changed || reindexed
+		}
+	}
+	fs.indexStale = nil
+
+	if !fs.indexChecked {
+		rescanned, err := fs.rescanIndex(idx)
+		if err != nil {
+			return nil, err
+		}
+		fs.indexChecked = true
+		if rescanned {
+			// Possibly many notes at once, worth writing now
+			if err := fs.persistIndex(); err != nil {
+				return nil, err
+			}
+		}
+		return idx, nil
+	}
+
+	if changed {
+		if err := fs.indexChanged(); err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+// rescanIndex reindexes notes whose file changed since they were indexed and
+// drops notes whose file is gone, reporting whether anything changed
+// The caller must hold fs.indexMu
+func (fs *LocalFileSystem) rescanIndex(idx *searchIndex) (bool, error) {
+	files, err := fs.noteFiles()
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	seen := make(map[string]bool, len(files))
+
+	for _, file := range files {
+		seen[file.id] = true
+
+		doc, ok := idx.Docs[file.id]
+		if ok && doc.Path == file.path && doc.ModTime == file.info.ModTime().UnixNano() && doc.Size == file.info.Size() {
+			continue
+		}
+
+		note, err := fs.parseNote(file.path)
+		if err != nil {
+			// Unreadable notes are left out of the index
+			idx.remove(file.id)
+			changed = true
+			continue
+		}
+
+		idx.add(file.id, newIndexedDoc(note, file.info), tokenizeNote(note))
+		changed = true
+	}
+
+	for id := range idx.Docs {
+		if !seen[id] {
+			idx.remove(id)
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// reindexFile updates the index for the note file at path, reporting whether
+// anything changed
+// It reports false when every file must be checked instead, e.g. when a
+// folder changed or the note now has two files
+// The caller must hold fs.indexMu
+func (fs *LocalFileSystem) reindexFile(idx *searchIndex, path string) (changed, ok bool) {
+	// The note last indexed at path, if any
+	previous := ""
+	for id, doc := range idx.Docs {
+		if doc.Path == path {
+			previous = id
+			break
+		}
+	}
+
+	id := ""
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			return false, false
+		}
+		if id = fs.fileID(path, info); validateID(id) != nil {
+			id = ""
+		}
+	}
+	if previous != "" && previous != id {
+		idx.remove(previous)
+		changed = true
+	}
+	if id == "" {
+		// Gone, or not a note
+		return changed, true
+	}
+
+	doc, indexed := idx.Docs[id]
+	if indexed && doc.Path != path {
+		// Moved, or held by another file too
+		return changed, false
+	}
+	if indexed && doc.ModTime == info.ModTime().UnixNano() && doc.Size == info.Size() {
+		return changed, true
+	}
+
+	note, err := fs.parseNote(path)
+	if err != nil {
+		// Unreadable notes are left out of the index
+		idx.remove(id)
+		return true, true
+	}
+	idx.add(id, newIndexedDoc(note, info), tokenizeNote(note))
+	return true, true
+}
+
+// Invalidate marks the note file at path as changed outside the storage, or
+// every note file when path is "", so the next search looks at it again
+func (fs *LocalFileSystem) Invalidate(path string) {
+	fs.indexMu.Lock()
+	defer fs.indexMu.Unlock()
+
+	if path == "" {
+		fs.indexChecked = false
+		return
+	}
+	if fs.indexStale == nil {
+		fs.indexStale = make(map[string]bool)
+	}
+	fs.indexStale[path] = true
+}
+
+// indexNote adds or replaces a single note in the index
+func (fs *LocalFileSystem) indexNote(note *Note) error {
+	info, err := os.Stat(note.FilePath)
+	if err != nil {
+		return err
+	}
+
+	fs.indexMu.Lock()
+	defer fs.indexMu.Unlock()
+
+	idx := fs.loadIndex()
+	idx.add(note.ID, newIndexedDoc(note, info), tokenizeNote(note))
+	return fs.indexChanged()
+}
+
+// unindexNote removes a single note from the index
+func (fs *LocalFileSystem) unindexNote(id string) error {
+	fs.indexMu.Lock()
+	defer fs.indexMu.Unlock()
+
+	idx := fs.loadIndex()
+	if _, ok := idx.Docs[id]; !ok {
+		return nil
+	}
+	idx.remove(id)
+	return fs.indexChanged()
+}
+
+// indexChanged records that a note changed in the index, and writes it to
+// disk once indexWriteBatch of them did
+// The persisted index is only a cache checked against the note files when it
+// is loaded, so the changes not written yet are found again after a crash
+// The caller must hold fs.indexMu
+func (fs *LocalFileSystem) indexChanged() error {
+	fs.indexUnsaved++
+	if fs.indexUnsaved < indexWriteBatch {
+		return nil
+	}
+	return fs.persistIndex()
+}
+
+// Close writes the changes of the search index not yet on disk
+func (fs *LocalFileSystem) Close() error {
+	fs.indexMu.Lock()
+	defer fs.indexMu.Unlock()
+
+	if fs.index == nil || fs.indexUnsaved == 0 {
+		return nil
+	}
+	return fs.persistIndex()
+}
+
+// persistIndex writes the index to disk atomically, so a crash never leaves a
+// half-written index behind
+// The caller must hold fs.indexMu
+func (fs *LocalFileSystem) persistIndex() error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(fs.index); err != nil {
+		return fmt.Errorf("could not encode search index: %w", err)
+	}
+
+	dir := filepath.Dir(fs.indexPath())
+	if err := os.MkdirAll(dir, dirMode(fs.fileMode)); err != nil {
+		return fmt.Errorf("could not create %s: %w", dir, err)
+	}
+
+	if err := writeFileAtomic(fs.indexPath(), buf.Bytes(), fs.fileMode); err != nil {
+		return fmt.Errorf("could not write search index: %w", err)
+	}
+	fs.indexUnsaved = 0
+	return nil
+}
+
+// newIndexedDoc builds the index entry of a note stored in a file
+func newIndexedDoc(note *Note, info os.FileInfo) *indexedDoc {
+	return &indexedDoc{
+		Path:        note.FilePath,
+		ModTime:     info.ModTime().UnixNano(),
+		Size:        info.Size(),
+		TitleLength: len(tokenize(note.Title)),
+		Title:       note.Title,
+		Tags:        note.AllTags(),
+		Aliases:     note.Aliases,
+		Links:       linkTargets(note.Content),
+		Folder:      note.Folder,
+		CreatedAt:   note.CreatedAt,
+		UpdatedAt:   note.UpdatedAt,
+	}
+}
+
+// tokenizeNote returns the tokens of a note, title first
+func tokenizeNote(note *Note) []string {
+	return append(tokenize(note.Title), tokenize(note.Content)...)
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultJournalTemplate is the template journal entries start from, when
+	// the vault has one
+	DefaultJournalTemplate = "journal"
+
+	// journalIDLayout names the journal entry of a day, so every storage
+	// finds it with GetNote
+	journalIDLayout = "2006-01-02"
+)
+
+// Journal creates and finds the daily journal entries of a vault, one note
+// per day whose ID is the date
+type Journal struct {
+	// Template is the template new entries start from, rendered for their
+	// day; without it an entry starts empty
+	Template string
+
+	// Folder is where new entries are created, "" for the root of the vault
+	Folder string
+}
+
+// JournalID returns the ID of the journal entry of day
+func JournalID(day time.Time) string {
+	return day.Format(journalIDLayout)
+}
+
+// JournalDay returns the day of the journal entry id, in the local time zone
+// It reports false when id doesn't name a journal entry
+func JournalDay(id string) (time.Time, bool) {
+	day, err := time.ParseInLocation(journalIDLayout, id, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// Open returns the journal entry of day, creating it when there is none
+func (j Journal) Open(ctx context.Context, fs FileSystem, day time.Time) (*Note, error) {
+	note, err := fs.GetNote(ctx, JournalID(day))
+	if !errors.Is(err, ErrNotFound) {
+		return note, err
+	}
+
+	note, err = j.newEntry(ctx, fs, day)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.SaveNote(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// newEntry creates the journal entry of day from the journal template
+func (j Journal) newEntry(ctx context.Context, fs FileSystem, day time.Time) (*Note, error) {
+	id := JournalID(day)
+
+	var tmpl *Template
+	if templates, ok := fs.(Templates); ok && j.Template != "" {
+		var err error
+		tmpl, err = templates.GetTemplate(ctx, j.Template)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("could not create journal entry %s: %w", id, err)
+		}
+	}
+
+	note := NewNote("", "")
+	if tmpl != nil {
+		// Render the template for the day of the entry, at the current time
+		data := NewTemplateData("")
+		y, m, d := day.Date()
+		data.Now = time.Date(y, m, d, data.Now.Hour(), data.Now.Minute(), data.Now.Second(), 0, day.Location())
+
+		var err error
+		if note, _, err = tmpl.Render(data); err != nil {
+			return nil, fmt.Errorf("could not create journal entry %s: %w", id, err)
+		}
+	}
+
+	note.ID = id
+	if note.Title == "" {
+		note.Title = id
+	}
+	note.Folder = j.Folder
+	return note, nil
+}
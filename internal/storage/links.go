@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// linkPattern matches a [[target]] or [[target|label]] link
+var linkPattern = regexp.MustCompile(`\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]*))?\]\]`)
+
+// Link is a [[link]] to another note, found in the content of a note
+type Link struct {
+	Target string // Title, ID or alias of the linked note
+	Label  string // Text shown instead of the target, "" for none
+	Start  int    // Byte offset of the link in the content
+	End    int    // Byte offset just after the link
+}
+
+// Text returns what the link is shown as: its label, or else its target
+func (l Link) Text() string {
+	if l.Label != "" {
+		return l.Label
+	}
+	return l.Target
+}
+
+// LinkGraph is implemented by storages that follow the [[links]] between notes
+// A link names a note by ID, title or alias, the latter two without case
+type LinkGraph interface {
+	// ResolveLink returns the note a link target names
+	// It fails with ErrNotFound when no note matches
+	ResolveLink(ctx context.Context, target string) (*Note, error)
+
+	// Backlinks returns the notes linking to note id, sorted by title
+	Backlinks(ctx context.Context, id string) ([]*Note, error)
+}
+
+// ParseLinks returns the [[links]] of content in order
+// Links in code blocks and code spans are not links
+func ParseLinks(content string) []Link {
+	var links []Link
+	scanProse(content, func(offset int, line string) {
+		for _, match := range linkPattern.FindAllStringSubmatchIndex(line, -1) {
+			target := strings.TrimSpace(line[match[2]:match[3]])
+			if target == "" {
+				continue
+			}
+			link := Link{Target: target, Start: offset + match[0], End: offset + match[1]}
+			if match[4] >= 0 {
+				link.Label = strings.TrimSpace(line[match[4]:match[5]])
+			}
+			links = append(links, link)
+		}
+	})
+	return links
+}
+
+// linkTargets returns the targets of the links of content, without duplicates
+func linkTargets(content string) []string {
+	var targets []string
+	for _, link := range ParseLinks(content) {
+		if !containsTag(targets, link.Target) {
+			targets = append(targets, link.Target)
+		}
+	}
+	return targets
+}
+
+// renameLinks points the links of content to from at to instead, keeping
+// their labels
+func renameLinks(content, from, to string) string {
+	var b strings.Builder
+	last := 0
+	for _, link := range ParseLinks(content) {
+		if !strings.EqualFold(link.Target, from) {
+			continue
+		}
+		b.WriteString(content[last:link.Start])
+		b.WriteString("[[" + to)
+		if link.Label != "" {
+			b.WriteString("|" + link.Label)
+		}
+		b.WriteString("]]")
+		last = link.End
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// linkResolver finds the note a link target names among indexed notes
+type linkResolver struct {
+	ids     map[string]bool
+	titles  map[string]string // Lowercase title to ID
+	aliases map[string]string // Lowercase alias to ID
+}
+
+// newLinkResolver indexes the IDs, titles and aliases of docs
+// When notes share a title or an alias, the smallest ID wins so links always
+// lead to the same note
+func newLinkResolver(docs map[string]*indexedDoc) *linkResolver {
+	r := &linkResolver{
+		ids:     make(map[string]bool, len(docs)),
+		titles:  make(map[string]string, len(docs)),
+		aliases: make(map[string]string),
+	}
+	claim := func(names map[string]string, name, id string) {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			return
+		}
+		if owner, ok := names[key]; !ok || id < owner {
+			names[key] = id
+		}
+	}
+	for id, doc := range docs {
+		r.ids[id] = true
+		claim(r.titles, doc.Title, id)
+		for _, alias := range doc.Aliases {
+			claim(r.aliases, alias, id)
+		}
+	}
+	return r
+}
+
+// resolve returns the ID of the note target names, "" for none
+// IDs come first, then titles, then aliases
+func (r *linkResolver) resolve(target string) string {
+	target = strings.TrimSpace(target)
+	if r.ids[target] {
+		return target
+	}
+	key := strings.ToLower(target)
+	if id, ok := r.titles[key]; ok {
+		return id
+	}
+	return r.aliases[key]
+}
+
+// ResolveLink returns the note a link target names, by ID, title or alias
+func (fs *LocalFileSystem) ResolveLink(ctx context.Context, target string) (*Note, error) {
+	fs.indexMu.Lock()
+	idx, err := fs.refreshIndex()
+	if err != nil {
+		fs.indexMu.Unlock()
+		return nil, fmt.Errorf("could not follow link to %s: %w", target, err)
+	}
+	id := newLinkResolver(idx.Docs).resolve(target)
+	fs.indexMu.Unlock()
+
+	if id == "" {
+		return nil, fmt.Errorf("could not follow link: %w: no note named %q", ErrNotFound, target)
+	}
+	return fs.GetNote(ctx, id)
+}
+
+// Backlinks returns the notes linking to note id, sorted by title
+// A note linking to itself is not among them
+func (fs *LocalFileSystem) Backlinks(ctx context.Context, id string) ([]*Note, error) {
+	fs.indexMu.Lock()
+	idx, err := fs.refreshIndex()
+	if err != nil {
+		fs.indexMu.Unlock()
+		return nil, fmt.Errorf("could not list backlinks of %s: %w", id, err)
+	}
+	resolver := newLinkResolver(idx.Docs)
+	var paths []string
+	for source, doc := range idx.Docs {
+		if source == id {
+			continue
+		}
+		for _, target := range doc.Links {
+			if resolver.resolve(target) == id {
+				paths = append(paths, doc.Path)
+				break
+			}
+		}
+	}
+	fs.indexMu.Unlock()
+
+	notes, err := fs.loadNotes(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return strings.ToLower(notes[i].Title) < strings.ToLower(notes[j].Title)
+	})
+	return notes, nil
+}
+
+// rewriteLinks points the links to oldTitle at the new title of note, once
+// note was renamed, and returns the notes it changed
+// Links are left alone while oldTitle still names a note, another one or
+// note itself through an alias
+func (fs *LocalFileSystem) rewriteLinks(ctx context.Context, note *Note, oldTitle string) ([]*Note, error) {
+	if oldTitle == "" || strings.EqualFold(oldTitle, note.Title) {
+		return nil, nil
+	}
+
+	fs.indexMu.Lock()
+	idx, err := fs.refreshIndex()
+	if err != nil {
+		fs.indexMu.Unlock()
+		return nil, fmt.Errorf("could not update links to %s: %w", oldTitle, err)
+	}
+	if newLinkResolver(idx.Docs).resolve(oldTitle) != "" {
+		fs.indexMu.Unlock()
+		return nil, nil
+	}
+	var paths []string
+	for _, doc := range idx.Docs {
+		if containsTag(doc.Links, oldTitle) {
+			paths = append(paths, doc.Path)
+		}
+	}
+	fs.indexMu.Unlock()
+
+	sources, err := fs.loadNotes(ctx, paths)
+	if err != nil {
+		return nil, fmt.Errorf("could not update links to %s: %w", oldTitle, err)
+	}
+
+	var changed []*Note
+	for _, source := range sources {
+		content := renameLinks(source.Content, oldTitle, note.Title)
+		if content == source.Content {
+			continue
+		}
+		source.Content = content
+		if _, err := fs.saveNote(source); err != nil {
+			return changed, fmt.Errorf("could not update links to %s: %w", oldTitle, err)
+		}
+		changed = append(changed, source)
+	}
+	return changed, nil
+}
@@ -7,11 +7,47 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 type LocalFileSystem struct {
-	notesDir string
+	notesDir  string
+	fileMode  os.FileMode
+	filenames FilenameStrategy
+
+	// How long deleted notes stay in the trash, 0 for ever
+	trashRetention time.Duration
+
+	// How many versions of each note are kept and for how long, 0 for no limit
+	historyVersions int
+	historyAge      time.Duration
+
+	// Serializes the revision check and the write of SaveNote
+	saveMu sync.Mutex
+
+	// Where the notes were last seen, and the IDs read from their files,
+	// guarded by locMu
+	locMu     sync.Mutex
+	locations map[string]string
+	fileIDs   map[string]cachedID
+
+	// Search index, loaded lazily and guarded by indexMu
+	indexMu sync.Mutex
+	index   *searchIndex
+	// Whether the index was checked against the note files, the files
+	// changed since then, and the changes not yet written to disk
+	indexChecked bool
+	indexStale   map[string]bool
+	indexUnsaved int
+}
+
+// noteFile is a note file found in the notes directory or one of its folders
+type noteFile struct {
+	id   string
+	path string
+	info os.FileInfo
 }
 
 // NotesDir returns the path to the notes directory
@@ -19,56 +55,76 @@ func (fs *LocalFileSystem) NotesDir() string {
 	return fs.notesDir
 }
 
-// NewLocalFileSystem creates an instance of the local storage system
-// It determines the path ~/.leaf/notes/, creates the directory if it doesn't exist
-func NewLocalFileSystem() (*LocalFileSystem, error) {
+// DefaultNotesDir returns the default notes directory ~/.leaf/notes/
+func DefaultNotesDir() (string, error) {
 	// Get the user's home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("could not determine home directory: %w", err)
+		return "", fmt.Errorf("could not determine home directory: %w", err)
 	}
 
 	// Build the path ~/.leaf/notes/ (cross-platform)
-	notesDir := filepath.Join(homeDir, ".leaf", "notes")
+	return filepath.Join(homeDir, ".leaf", "notes"), nil
+}
+
+// NewLocalFileSystem creates an instance of the local storage system
+// It determines the path ~/.leaf/notes/, creates the directory if it doesn't exist
+func NewLocalFileSystem() (*LocalFileSystem, error) {
+	notesDir, err := DefaultNotesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocalFileSystemAt(notesDir)
+}
+
+// NewLocalFileSystemAt creates an instance of the local storage system rooted at notesDir
+// The directory is created if it doesn't exist
+func NewLocalFileSystemAt(notesDir string, opts ...LocalOption) (*LocalFileSystem, error) {
+	if notesDir == "" {
+		return nil, fmt.Errorf("notes directory cannot be empty")
+	}
+
+	// Resolve relative paths so NotesDir() is stable whatever the working directory
+	notesDir, err := filepath.Abs(notesDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve notes directory: %w", err)
+	}
+
+	fs := &LocalFileSystem{
+		notesDir:  notesDir,
+		fileMode:  DefaultFileMode,
+		filenames: FilenamesUUID,
+
+		trashRetention:  DefaultTrashRetention,
+		historyVersions: DefaultHistoryVersions,
+	}
+	for _, opt := range opts {
+		opt(fs)
+	}
 
 	// Create the directory if it doesn't exist
-	if err := os.MkdirAll(notesDir, 0755); err != nil {
+	if err := os.MkdirAll(notesDir, dirMode(fs.fileMode)); err != nil {
 		return nil, fmt.Errorf("could not create notes directory %s: %w", notesDir, err)
 	}
 
-	return &LocalFileSystem{
-		notesDir: notesDir,
-	}, nil
+	return fs, nil
 }
 
 func (fs *LocalFileSystem) ListNotes(ctx context.Context) ([]*Note, error) {
-	// Read the notes directory content
-	entries, err := os.ReadDir(fs.notesDir)
+	files, err := fs.noteFiles()
 	if err != nil {
-		return nil, fmt.Errorf("could not read directory: %w", err)
+		return nil, err
 	}
 
 	var notes []*Note
 
-	// Filter and parse .md files
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		// Check that the file is a .md file
-		if !strings.HasSuffix(entry.Name(), ".md") {
-			continue
-		}
-
-		// Build the full path
-		filePath := filepath.Join(fs.notesDir, entry.Name())
-
+	for _, file := range files {
 		// Parse the file
-		note, err := fs.parseNote(filePath)
+		note, err := fs.parseNote(file.path)
 		if err != nil {
 			// Log the error but continue
-			fmt.Fprintf(os.Stderr, "error parsing %s: %v\n", entry.Name(), err)
+			fmt.Fprintf(os.Stderr, "error parsing %s: %v\n", filepath.Base(file.path), err)
 			continue
 		}
 
@@ -83,74 +139,315 @@ func (fs *LocalFileSystem) ListNotes(ctx context.Context) ([]*Note, error) {
 	return notes, nil
 }
 
+// noteFiles returns the .md files of the notes directory and its folders
+// Hidden directories, such as .leaf, .trash or .git, are skipped; when two
+// files hold a note with the same ID, the first one in path order wins
+func (fs *LocalFileSystem) noteFiles() ([]noteFile, error) {
+	var files []noteFile
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(fs.notesDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if path == fs.notesDir {
+				return err
+			}
+			// A folder that can't be read, or that disappeared meanwhile
+			return nil
+		}
+
+		if entry.IsDir() {
+			if path != fs.notesDir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Check that the file is a .md file
+		if !strings.HasSuffix(entry.Name(), ".md") {
+			return nil
+		}
+
+		// Skip links leading out of the vault
+		if entry.Type()&os.ModeSymlink != 0 {
+			if err := fs.checkWithin(path); err != nil {
+				return nil
+			}
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// The file disappeared since the directory was read
+			return nil
+		}
+
+		// Skip files that can't be addressed by ID
+		id := fs.fileID(path, info)
+		if err := validateID(id); err != nil || seen[id] {
+			return nil
+		}
+
+		seen[id] = true
+		files = append(files, noteFile{
+			id:   id,
+			path: path,
+			info: info,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory: %w", err)
+	}
+
+	// Remember where the notes are, so finding one doesn't walk the folders again
+	locations := make(map[string]string, len(files))
+	for _, file := range files {
+		locations[file.id] = file.path
+	}
+	fs.locMu.Lock()
+	fs.locations = locations
+	fs.locMu.Unlock()
+
+	return files, nil
+}
+
+// findNote returns the path of the file of note id if it exists
+// Notes named after their ID at the root are found at once, the others where
+// they were last seen or where the search index saw them, walking the folders
+// again when the note moved or is unknown
+func (fs *LocalFileSystem) findNote(id string) (string, bool) {
+	if fs.holdsNote(filepath.Join(fs.notesDir, id+".md"), id) {
+		return filepath.Join(fs.notesDir, id+".md"), true
+	}
+
+	fs.locMu.Lock()
+	path, ok := fs.locations[id]
+	fs.locMu.Unlock()
+	if ok && fs.holdsNote(path, id) {
+		return path, true
+	}
+
+	if path, ok := fs.indexedPath(id); ok && fs.holdsNote(path, id) {
+		fs.setLocation(id, path)
+		return path, true
+	}
+
+	if _, err := fs.noteFiles(); err != nil {
+		return "", false
+	}
+	fs.locMu.Lock()
+	path, ok = fs.locations[id]
+	fs.locMu.Unlock()
+	return path, ok
+}
+
+// holdsNote reports whether the file at path exists and holds note id
+func (fs *LocalFileSystem) holdsNote(path, id string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		// A dangling link still names the note, reading it tells what's wrong
+		_, err = os.Lstat(path)
+		return err == nil && strings.TrimSuffix(filepath.Base(path), ".md") == id
+	}
+	return fs.fileID(path, info) == id
+}
+
+// setLocation remembers where the file of note id is
+func (fs *LocalFileSystem) setLocation(id, path string) {
+	fs.locMu.Lock()
+	if fs.locations == nil {
+		fs.locations = make(map[string]string)
+	}
+	fs.locations[id] = path
+	fs.locMu.Unlock()
+}
+
+// SaveNote writes a note to its file, in note.Folder
+// A note saved in another folder than the one holding it is moved there
+// A note loaded from disk is only saved if the file still has the revision it
+// was loaded from; otherwise the error wraps ErrConflict and nothing is written
+// Renaming a note points the [[links]] to its old title at the new one
 func (fs *LocalFileSystem) SaveNote(ctx context.Context, note *Note) error {
-	// Build the path: notesDir/{id}.md
-	filePath := filepath.Join(fs.notesDir, note.ID+".md")
+	oldTitle, err := fs.saveNote(note)
+	if err != nil {
+		return err
+	}
 
-	// Update UpdatedAt
+	// Links to the old title follow a rename
+	// A failure here is not fatal: the note is saved, and still linked to by ID
+	_, _ = fs.rewriteLinks(ctx, note, oldTitle)
+	return nil
+}
+
+// saveNote writes note and returns the title it had before, "" for a new note
+// The file is named by the filename strategy, and renamed when the title changes
+func (fs *LocalFileSystem) saveNote(note *Note) (string, error) {
+	if err := validateID(note.ID); err != nil {
+		return "", fmt.Errorf("could not save note: %w", err)
+	}
+
+	fs.saveMu.Lock()
+	defer fs.saveMu.Unlock()
+
+	// Where the note is now, if it exists
+	currentPath, exists := fs.findNote(note.ID)
+
+	// Make sure nobody changed the file since the note was loaded
+	// A file deleted in the meantime is simply recreated
+	oldTitle := ""
+	if data, err := os.ReadFile(currentPath); err == nil {
+		if note.Revision != "" && revisionOf(data) != note.Revision {
+			return "", fmt.Errorf("could not save note %s: %w", note.ID, ErrConflict)
+		}
+		if previous, err := decodeNote(note.ID, currentPath, data, time.Time{}); err == nil {
+			oldTitle = previous.Title
+		}
+	}
+
+	// Update UpdatedAt (and CreatedAt for notes built without NewNote)
 	note.UpdatedAt = time.Now()
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = note.UpdatedAt
+	}
+
+	// Build the path: notesDir/{folder}/{name}.md, keeping the name of the
+	// file while the title doesn't change
+	name := fs.fileName(note)
+	if exists && oldTitle == note.Title {
+		name = strings.TrimSuffix(filepath.Base(currentPath), ".md")
+	}
+	filePath, err := fs.freePath(note.Folder, name, currentPath)
+	if err != nil {
+		return "", fmt.Errorf("could not save note: %w", err)
+	}
+	if !exists {
+		currentPath = filePath
+	}
 	note.FilePath = filePath
+	note.Folder, _ = cleanFolder(note.Folder)
 
 	// Write the file content
-	// Format: # Title\n\nContent
-	fileContent := fmt.Sprintf("# %s\n\n%s", note.Title, note.Content)
+	// Format: ---\nfrontmatter\n---\n# Title\n\nContent
+	header, err := encodeFrontmatter(note)
+	if err != nil {
+		return "", fmt.Errorf("could not write note %s: %w", filePath, err)
+	}
+	fileContent := fmt.Sprintf("%s# %s\n\n%s", header, note.Title, note.Content)
+
+	// Write through a temporary file so a crash never leaves a truncated note
+	// A note moved to another folder or renamed is written where it is, then
+	// renamed, so it never has two files
+	if err := os.MkdirAll(filepath.Dir(filePath), dirMode(fs.fileMode)); err != nil {
+		return "", fmt.Errorf("could not create folder %s: %w", note.Folder, writeError(err))
+	}
+	if err := writeFileAtomic(currentPath, []byte(fileContent), fs.fileMode); err != nil {
+		return "", fmt.Errorf("could not write note %s: %w", currentPath, writeError(err))
+	}
+	note.Revision = revisionOf([]byte(fileContent))
 
-	if err := os.WriteFile(filePath, []byte(fileContent), 0644); err != nil {
-		return fmt.Errorf("could not write note %s: %w", filePath, err)
+	if currentPath != filePath {
+		if err := os.Rename(currentPath, filePath); err != nil {
+			return "", fmt.Errorf("could not move note %s: %w", note.ID, writeError(err))
+		}
+		if err := syncDir(filepath.Dir(filePath)); err != nil {
+			return "", fmt.Errorf("could not move note %s: %w", note.ID, err)
+		}
 	}
+	fs.setLocation(note.ID, filePath)
+	fs.cacheID(filePath, note.ID)
 
-	return nil
+	// Keep the search index in sync
+	// A failure here is not fatal: the next search refreshes stale entries
+	_ = fs.indexNote(note)
+
+	// Keep this version in the history, which is a convenience: the note is saved anyway
+	_ = fs.snapshot(note.ID, []byte(fileContent), note.UpdatedAt)
+
+	return oldTitle, nil
 }
 
 func (fs *LocalFileSystem) GetNote(ctx context.Context, id string) (*Note, error) {
 	// Build the path
-	filePath := filepath.Join(fs.notesDir, id+".md")
+	filePath, err := fs.notePath(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not load note: %w", err)
+	}
 
 	// Load and parse the note
 	note, err := fs.parseNote(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("could not load note %s: %w", id, err)
+		return nil, fmt.Errorf("could not load note %s: %w", id, readError(err))
 	}
 
 	return note, nil
 }
 
+// DeleteNote moves a note to the trash, see Trash
 func (fs *LocalFileSystem) DeleteNote(ctx context.Context, id string) error {
 	// Build the path
-	filePath := filepath.Join(fs.notesDir, id+".md")
-
-	// Check that the file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("note not found: %s", id)
+	filePath, err := fs.notePath(id)
+	if err != nil {
+		return fmt.Errorf("could not delete note: %w", err)
 	}
 
-	// Delete the file
-	if err := os.Remove(filePath); err != nil {
+	// Move the file to the trash
+	if err := fs.trashNote(id, filePath); err != nil {
 		return fmt.Errorf("could not delete note %s: %w", id, err)
 	}
 
+	// Keep the search index in sync
+	_ = fs.unindexNote(id)
+
 	return nil
 }
 
+// SearchNotes returns the notes matching query, best matches first
+// The query syntax is described on Query; a malformed query returns a *ParseError
+// Words match as prefixes, accents and case are ignored, and results are ranked with BM25
 func (fs *LocalFileSystem) SearchNotes(ctx context.Context, query string) ([]*Note, error) {
-	// Get all notes
-	notes, err := fs.ListNotes(ctx)
+	q, err := ParseQuery(query)
 	if err != nil {
 		return nil, err
 	}
 
-	var results []*Note
-	query = strings.ToLower(query)
+	// An empty query matches every note
+	if q == nil {
+		return fs.ListNotes(ctx)
+	}
 
-	// Filter by title and content
-	for _, note := range notes {
-		if strings.Contains(strings.ToLower(note.Title), query) ||
-			strings.Contains(strings.ToLower(note.Content), query) {
-			results = append(results, note)
-		}
+	// Rank the matching notes from the index
+	fs.indexMu.Lock()
+	idx, err := fs.refreshIndex()
+	if err != nil {
+		fs.indexMu.Unlock()
+		return nil, fmt.Errorf("could not search notes: %w", err)
 	}
+	ranked := newQueryEvaluator(fs, idx).run(q)
+	paths := make([]string, len(ranked))
+	for i, id := range ranked {
+		paths[i] = idx.Docs[id].Path
+	}
+	fs.indexMu.Unlock()
+
+	// Load the matching notes in rank order
+	return fs.loadNotes(ctx, paths)
+}
+
+// loadNotes parses the note files at paths, in order
+func (fs *LocalFileSystem) loadNotes(ctx context.Context, paths []string) ([]*Note, error) {
+	notes := make([]*Note, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-	return results, nil
+		note, err := fs.parseNote(path)
+		if err != nil {
+			// The file changed or disappeared since the index was refreshed
+			continue
+		}
+		notes = append(notes, note)
+	}
+	return notes, nil
 }
 
 // parseNote parses a markdown file into a Note struct
@@ -161,8 +458,45 @@ func (fs *LocalFileSystem) parseNote(filePath string) (*Note, error) {
 		return nil, err
 	}
 
+	// Get file info
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The filename stands for the ID of notes without one in their frontmatter
+	id := strings.TrimSuffix(filepath.Base(filePath), ".md")
+
+	note, err := decodeNote(id, filePath, fileBytes, fileInfo.ModTime())
+	if err != nil {
+		return nil, err
+	}
+	note.Folder = fs.folderOf(filePath)
+	return note, nil
+}
+
+// decodeNote parses the content of a note file
+// modTime stands for the timestamps the frontmatter doesn't give
+func decodeNote(id, filePath string, fileBytes []byte, modTime time.Time) (*Note, error) {
+	// Notes are text, anything else is not worth parsing
+	if !utf8.Valid(fileBytes) {
+		return nil, fmt.Errorf("%w: %s is not UTF-8 text", ErrCorrupt, filepath.Base(filePath))
+	}
+
 	content := string(fileBytes)
 
+	// Extract the frontmatter block if present
+	// A block that isn't valid YAML is kept as regular content
+	var fm *frontmatter
+	var metadata map[string]interface{}
+	if block, body, ok := splitFrontmatter(content); ok {
+		if decoded, custom, err := decodeFrontmatter(block); err == nil {
+			fm = decoded
+			metadata = custom
+			content = body
+		}
+	}
+
 	// Extract the title (first line if it starts with #)
 	var title string
 	lines := strings.Split(content, "\n")
@@ -173,22 +507,36 @@ func (fs *LocalFileSystem) parseNote(filePath string) (*Note, error) {
 		content = strings.TrimSpace(content)
 	}
 
-	// Extract the ID from the filename
-	fileName := filepath.Base(filePath)
-	id := strings.TrimSuffix(fileName, ".md")
-
-	// Get file info
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Note{
+	note := &Note{
 		ID:        id,
 		Title:     title,
 		Content:   content,
-		CreatedAt: fileInfo.ModTime(), // TODO: store CreatedAt in metadata
-		UpdatedAt: fileInfo.ModTime(),
+		CreatedAt: modTime,
+		UpdatedAt: modTime,
 		FilePath:  filePath,
-	}, nil
+		Metadata:  metadata,
+		Revision:  revisionOf(fileBytes),
+	}
+
+	// Frontmatter values take precedence over the file name and info
+	// The heading is what people edit, so it wins over a frontmatter title
+	// left behind, which only names notes without a heading
+	if fm != nil {
+		if validateID(fm.ID) == nil {
+			note.ID = fm.ID
+		}
+		if note.Title == "" {
+			note.Title = fm.Title
+		}
+		if !fm.Created.IsZero() {
+			note.CreatedAt = fm.Created
+		}
+		if !fm.Updated.IsZero() {
+			note.UpdatedAt = fm.Updated
+		}
+		note.Tags = fm.Tags
+		note.Aliases = fm.Aliases
+	}
+
+	return note, nil
 }
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,19 @@ type Note struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	FilePath  string
+
+	// Folder is the path of the folder holding the note, with forward slashes,
+	// "" for the root of the vault; the ID doesn't change when the note moves
+	Folder string
+
+	// Revision identifies the file content the note was loaded from
+	// SaveNote refuses to overwrite a file whose revision changed since
+	Revision string
+
+	// Frontmatter metadata
+	Tags     []string
+	Aliases  []string
+	Metadata map[string]interface{} // Custom frontmatter fields
 }
 
 // NewNote creates a new note with a generated ID
@@ -28,6 +42,16 @@ func NewNote(title, content string) *Note {
 	}
 }
 
+// SameContent reports whether two notes have the same title, content and frontmatter
+// Timestamps, file paths and revisions are ignored
+func (n *Note) SameContent(other *Note) bool {
+	return n.Title == other.Title &&
+		n.Content == other.Content &&
+		reflect.DeepEqual(n.Tags, other.Tags) &&
+		reflect.DeepEqual(n.Aliases, other.Aliases) &&
+		reflect.DeepEqual(n.Metadata, other.Metadata)
+}
+
 // generateID generates a unique ID for a note using UUID v4
 func generateID() string {
 	return uuid.New().String()
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxIDLength keeps note file names within the limits of common filesystems
+	maxIDLength = 200
+
+	// maxTrashIDLength leaves room for the deletion time added to trashed notes
+	maxTrashIDLength = maxIDLength + 32
+)
+
+// validateID checks that id can name a note file: a single, visible path
+// element without control characters
+func validateID(id string) error {
+	return validateName(id, maxIDLength)
+}
+
+// validateName checks that id is a valid file name of at most maxLength bytes
+func validateName(id string, maxLength int) error {
+	switch {
+	case id == "":
+		return fmt.Errorf("%w: empty ID", ErrInvalidID)
+	case len(id) > maxLength:
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidID, maxLength)
+	case !utf8.ValidString(id):
+		return fmt.Errorf("%w: %q is not UTF-8", ErrInvalidID, id)
+	case strings.HasPrefix(id, "."):
+		// Covers "." and "..", and keeps notes apart from hidden and temporary files
+		return fmt.Errorf("%w: %q starts with a dot", ErrInvalidID, id)
+	}
+
+	for _, r := range id {
+		if unicode.IsControl(r) || strings.ContainsRune(reservedIDChars(), r) {
+			return fmt.Errorf("%w: %q contains %q", ErrInvalidID, id, r)
+		}
+	}
+	return nil
+}
+
+// reservedIDChars returns the characters a note ID can't contain
+// Windows gives a meaning to more of them, such as ':' for alternate streams
+func reservedIDChars() string {
+	if runtime.GOOS == "windows" {
+		return `/\:*?"<>|`
+	}
+	return `/\`
+}
+
+// notePath returns the path of the file of note id, in whichever folder it is
+// A note that doesn't exist is looked for at the root of the vault, under its
+// ID, where reading it fails with ErrNotFound
+// It fails with ErrInvalidID when id is malformed or when the file, through a
+// symbolic link, lies outside the notes directory
+func (fs *LocalFileSystem) notePath(id string) (string, error) {
+	if err := validateID(id); err != nil {
+		return "", err
+	}
+	if path, ok := fs.findNote(id); ok {
+		return path, fs.checkWithin(path)
+	}
+
+	path, err := fs.freePath("", id, "")
+	if err != nil {
+		return "", err
+	}
+	if filepath.Base(path) != id+".md" {
+		// The file named after id holds another note
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return path, nil
+}
+
+// cleanFolder checks a folder path and returns it with forward slashes and
+// without leading or trailing slashes; "" is the root of the vault
+// Every element must be a valid name, which keeps out "..", hidden directories
+// and the vault's own .leaf and .trash
+func cleanFolder(folder string) (string, error) {
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
+	if folder == "" {
+		return "", nil
+	}
+	for _, part := range strings.Split(folder, "/") {
+		if err := validateName(part, maxIDLength); err != nil {
+			return "", fmt.Errorf("invalid folder %q: %w", folder, err)
+		}
+	}
+	return folder, nil
+}
+
+// folderDir returns the directory of folder
+func (fs *LocalFileSystem) folderDir(folder string) (string, error) {
+	folder, err := cleanFolder(folder)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(fs.notesDir, filepath.FromSlash(folder))
+	return dir, fs.checkWithin(dir)
+}
+
+// folderOf returns the folder holding the file at path
+func (fs *LocalFileSystem) folderOf(path string) string {
+	rel, err := filepath.Rel(fs.notesDir, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// checkWithin fails with ErrInvalidID when path, through symbolic links, lies
+// outside the notes directory
+// Paths that don't exist yet are checked through their closest existing parent;
+// a dangling link is harmless, writes replace the link itself
+func (fs *LocalFileSystem) checkWithin(path string) error {
+	resolved, err := resolveExisting(path)
+	if err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(fs.notesDir)
+	if err != nil {
+		return err
+	}
+	if !isWithin(root, resolved) {
+		return fmt.Errorf("%w: %s resolves outside the notes directory", ErrInvalidID, filepath.Base(path))
+	}
+	return nil
+}
+
+// resolveExisting resolves the symbolic links of the longest existing part of path
+func resolveExisting(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if _, err := os.Lstat(path); err == nil {
+		// A dangling link
+		parent, err := resolveExisting(filepath.Dir(path))
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(parent, filepath.Base(path)), nil
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	resolvedParent, err := resolveExisting(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(path)), nil
+}
+
+// isWithin reports whether path is dir or lies below it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,658 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query is a node of a parsed search query
+//
+// The syntax supports:
+//
+//	word            notes containing a word starting with "word"
+//	"exact phrase"  notes containing the words in this order
+//	title:word      the word (or "phrase") must appear in the title
+//	tag:name        notes tagged with name
+//	folder:a/b      notes in folder a/b or below it
+//	created:>2026-01-01, updated:<7d
+//	                date filters (>, >=, <, <=, or a single day),
+//	                relative ages use h, d, w, m (30 days) or y
+//	/regex/         regular expression over title and content (/regex/i ignores case)
+//	a AND b, a OR b, NOT a, -a, (a OR b) c
+//
+// Adjacent clauses are combined with AND
+type Query interface {
+	// String returns the query in its canonical textual form
+	String() string
+}
+
+// TermQuery matches notes containing a word starting with Term
+type TermQuery struct {
+	Term string
+}
+
+// PhraseQuery matches notes containing the words of Phrase consecutively
+type PhraseQuery struct {
+	Phrase string
+}
+
+// TitleQuery matches notes whose title contains Text (a word prefix or a phrase)
+type TitleQuery struct {
+	Text   string
+	Phrase bool
+}
+
+// TagQuery matches notes tagged with Tag (case-insensitive)
+type TagQuery struct {
+	Tag string
+}
+
+// FolderQuery matches notes in Folder or one of its subfolders
+type FolderQuery struct {
+	Folder string
+}
+
+// DateQuery matches notes whose Field ("created" or "updated") is in [From, To)
+// A zero bound is open
+type DateQuery struct {
+	Field string
+	From  time.Time
+	To    time.Time
+	Raw   string // The value as typed, e.g. ">2026-01-01"
+}
+
+// RegexQuery matches notes whose title or content match Pattern
+type RegexQuery struct {
+	Pattern *regexp.Regexp
+	Raw     string // The expression as typed, including slashes and flags
+}
+
+// AndQuery matches notes matching every clause
+type AndQuery struct {
+	Clauses []Query
+}
+
+// OrQuery matches notes matching at least one clause
+type OrQuery struct {
+	Clauses []Query
+}
+
+// NotQuery matches notes not matching Clause
+type NotQuery struct {
+	Clause Query
+}
+
+func (q *TermQuery) String() string   { return q.Term }
+func (q *PhraseQuery) String() string { return strconv.Quote(q.Phrase) }
+func (q *DateQuery) String() string   { return q.Field + ":" + q.Raw }
+
+func (q *RegexQuery) String() string { return q.Raw }
+func (q *NotQuery) String() string   { return "NOT " + q.Clause.String() }
+
+func (q *TagQuery) String() string {
+	if strings.ContainsAny(q.Tag, " \t\"()") {
+		return "tag:" + strconv.Quote(q.Tag)
+	}
+	return "tag:" + q.Tag
+}
+
+func (q *FolderQuery) String() string {
+	if strings.ContainsAny(q.Folder, " \t\"()") {
+		return "folder:" + strconv.Quote(q.Folder)
+	}
+	return "folder:" + q.Folder
+}
+
+func (q *TitleQuery) String() string {
+	if q.Phrase {
+		return "title:" + strconv.Quote(q.Text)
+	}
+	return "title:" + q.Text
+}
+
+func (q *AndQuery) String() string { return joinClauses(q.Clauses, " AND ") }
+func (q *OrQuery) String() string  { return joinClauses(q.Clauses, " OR ") }
+
+// joinClauses renders clauses separated by sep, wrapping nested boolean queries in parentheses
+func joinClauses(clauses []Query, sep string) string {
+	parts := make([]string, len(clauses))
+	for i, clause := range clauses {
+		switch clause.(type) {
+		case *AndQuery, *OrQuery:
+			parts[i] = "(" + clause.String() + ")"
+		default:
+			parts[i] = clause.String()
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// ParseError reports a syntax error in a search query
+type ParseError struct {
+	Query string // The full query being parsed
+	Pos   int    // Byte offset of the problem in Query
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid query at column %d: %s", e.Column()+1, e.Msg)
+}
+
+// Column returns the rune offset of the problem, for pointing at it under the query
+func (e *ParseError) Column() int {
+	pos := e.Pos
+	if pos > len(e.Query) {
+		pos = len(e.Query)
+	}
+	return utf8.RuneCountInString(e.Query[:pos])
+}
+
+// ParseQuery parses a search query, resolving relative dates against the current time
+// An empty query returns a nil Query
+func ParseQuery(input string) (Query, error) {
+	return ParseQueryAt(input, time.Now())
+}
+
+// ParseQueryAt parses a search query, resolving relative dates against now
+func ParseQueryAt(input string, now time.Time) (Query, error) {
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &queryParser{input: input, tokens: tokens, now: now}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, p.errorAt(tok.pos, "unexpected ')'")
+		}
+		return nil, p.errorAt(tok.pos, fmt.Sprintf("unexpected %q", tok.text))
+	}
+
+	return q, nil
+}
+
+// HighlightTerms returns the words and phrases a query looks for, outside of negations
+// They are meant for highlighting matches in results
+func HighlightTerms(q Query) []string {
+	var terms []string
+
+	var walk func(q Query)
+	walk = func(q Query) {
+		switch q := q.(type) {
+		case *TermQuery:
+			terms = append(terms, q.Term)
+		case *PhraseQuery:
+			terms = append(terms, q.Phrase)
+		case *TitleQuery:
+			terms = append(terms, q.Text)
+		case *AndQuery:
+			for _, clause := range q.Clauses {
+				walk(clause)
+			}
+		case *OrQuery:
+			for _, clause := range q.Clauses {
+				walk(clause)
+			}
+		}
+	}
+	walk(q)
+
+	return terms
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokRegex
+	tokField // text holds the field name, value holds the value
+	tokMinus
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type queryToken struct {
+	kind   tokenKind
+	text   string // Raw text of the token
+	value  string // Unquoted value (phrases, regexes, field values)
+	quoted bool   // Field value was quoted
+	pos    int    // Byte offset of the token
+	valPos int    // Byte offset of the value for fields
+}
+
+// queryFields are the field prefixes understood by the parser
+var queryFields = map[string]bool{"title": true, "tag": true, "folder": true, "created": true, "updated": true}
+
+// lexQuery splits a query into tokens
+func lexQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, text: "(", pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, text: ")", pos: i})
+			i++
+
+		case r == '"':
+			value, end, err := lexQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokPhrase, text: input[i:end], value: value, pos: i})
+			i = end
+
+		case r == '/':
+			end, err := lexRegex(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokRegex, text: input[i:end], pos: i})
+			i = end
+
+		case r == '-' && i+1 < len(input) && !unicode.IsSpace(rune(input[i+1])):
+			tokens = append(tokens, queryToken{kind: tokMinus, text: "-", pos: i})
+			i++
+
+		default:
+			tok, end, err := lexWord(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// lexQuoted reads a double-quoted string starting at start
+// Backslash escapes the next character
+func lexQuoted(input string, start int) (string, int, error) {
+	var b strings.Builder
+	i := start + 1
+
+	for i < len(input) {
+		switch c := input[i]; c {
+		case '\\':
+			if i+1 < len(input) {
+				b.WriteByte(input[i+1])
+				i += 2
+				continue
+			}
+			i++
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return "", 0, &ParseError{Query: input, Pos: start, Msg: "unterminated quote"}
+}
+
+// lexRegex finds the end of a /regex/ literal starting at start, including trailing flags
+func lexRegex(input string, start int) (int, error) {
+	i := start + 1
+
+	for i < len(input) {
+		switch input[i] {
+		case '\\':
+			i += 2
+			continue
+		case '/':
+			i++
+			// Optional flags
+			for i < len(input) && input[i] == 'i' {
+				i++
+			}
+			return i, nil
+		}
+		i++
+	}
+
+	return 0, &ParseError{Query: input, Pos: start, Msg: "unterminated regular expression"}
+}
+
+// lexWord reads a bare word, a keyword or a field:value pair starting at start
+func lexWord(input string, start int) (queryToken, int, error) {
+	end := start
+	for end < len(input) {
+		r, size := utf8.DecodeRuneInString(input[end:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		end += size
+	}
+	word := input[start:end]
+
+	switch word {
+	case "AND":
+		return queryToken{kind: tokAnd, text: word, pos: start}, end, nil
+	case "OR":
+		return queryToken{kind: tokOr, text: word, pos: start}, end, nil
+	case "NOT":
+		return queryToken{kind: tokNot, text: word, pos: start}, end, nil
+	}
+
+	// field:value
+	if colon := strings.IndexByte(word, ':'); colon > 0 {
+		field := strings.ToLower(word[:colon])
+		if queryFields[field] {
+			valPos := start + colon + 1
+			tok := queryToken{kind: tokField, text: field, pos: start, valPos: valPos}
+
+			// Quoted value: title:"some phrase"
+			if valPos == end && end < len(input) && input[end] == '"' {
+				value, quotedEnd, err := lexQuoted(input, end)
+				if err != nil {
+					return queryToken{}, 0, err
+				}
+				tok.value = value
+				tok.quoted = true
+				return tok, quotedEnd, nil
+			}
+
+			tok.value = word[colon+1:]
+			return tok, end, nil
+		}
+	}
+
+	return queryToken{kind: tokWord, text: word, value: word, pos: start}, end, nil
+}
+
+// Parser
+
+type queryParser struct {
+	input  string
+	tokens []queryToken
+	pos    int
+	now    time.Time
+}
+
+func (p *queryParser) peek() queryToken {
+	if p.pos >= len(p.tokens) {
+		return queryToken{kind: tokEOF, pos: len(p.input)}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) errorAt(pos int, msg string) error {
+	return &ParseError{Query: p.input, Pos: pos, Msg: msg}
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *queryParser) parseOr() (Query, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := []Query{first}
+	for p.peek().kind == tokOr {
+		p.next()
+		clause, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	if len(clauses) == 1 {
+		return first, nil
+	}
+	return &OrQuery{Clauses: clauses}, nil
+}
+
+// parseAnd parses: unary (["AND"] unary)*
+func (p *queryParser) parseAnd() (Query, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := []Query{first}
+	for {
+		tok := p.peek()
+		if tok.kind == tokEOF || tok.kind == tokOr || tok.kind == tokRParen {
+			break
+		}
+		if tok.kind == tokAnd {
+			p.next()
+		}
+
+		clause, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	if len(clauses) == 1 {
+		return first, nil
+	}
+	return &AndQuery{Clauses: clauses}, nil
+}
+
+// parseUnary parses: "NOT" unary | "-" primary | primary
+func (p *queryParser) parseUnary() (Query, error) {
+	switch p.peek().kind {
+	case tokNot:
+		p.next()
+		clause, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotQuery{Clause: clause}, nil
+
+	case tokMinus:
+		p.next()
+		clause, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotQuery{Clause: clause}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a single clause or a parenthesized group
+func (p *queryParser) parsePrimary() (Query, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokEOF:
+		return nil, p.errorAt(tok.pos, "expected a search term")
+
+	case tokLParen:
+		if p.peek().kind == tokRParen {
+			return nil, p.errorAt(p.peek().pos, "empty group")
+		}
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorAt(tok.pos, "missing ')'")
+		}
+		return q, nil
+
+	case tokRParen:
+		return nil, p.errorAt(tok.pos, "unexpected ')'")
+
+	case tokAnd, tokOr:
+		return nil, p.errorAt(tok.pos, fmt.Sprintf("expected a search term before %s", tok.text))
+
+	case tokWord:
+		return &TermQuery{Term: tok.value}, nil
+
+	case tokPhrase:
+		if strings.TrimSpace(tok.value) == "" {
+			return nil, p.errorAt(tok.pos, "empty phrase")
+		}
+		return &PhraseQuery{Phrase: tok.value}, nil
+
+	case tokRegex:
+		return p.parseRegex(tok)
+
+	case tokField:
+		return p.parseField(tok)
+	}
+
+	return nil, p.errorAt(tok.pos, fmt.Sprintf("unexpected %q", tok.text))
+}
+
+// parseRegex compiles a /regex/ token
+func (p *queryParser) parseRegex(tok queryToken) (Query, error) {
+	end := strings.LastIndexByte(tok.text, '/')
+	expr := strings.ReplaceAll(tok.text[1:end], `\/`, "/")
+	if strings.Contains(tok.text[end+1:], "i") {
+		expr = "(?i)" + expr
+	}
+	if expr == "" {
+		return nil, p.errorAt(tok.pos, "empty regular expression")
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, p.errorAt(tok.pos, fmt.Sprintf("invalid regular expression: %v", err))
+	}
+	return &RegexQuery{Pattern: re, Raw: tok.text}, nil
+}
+
+// parseField parses the value of a field:value token
+func (p *queryParser) parseField(tok queryToken) (Query, error) {
+	if strings.TrimSpace(tok.value) == "" {
+		return nil, p.errorAt(tok.valPos, fmt.Sprintf("missing value for %s:", tok.text))
+	}
+
+	switch tok.text {
+	case "title":
+		return &TitleQuery{Text: tok.value, Phrase: tok.quoted}, nil
+	case "tag":
+		return &TagQuery{Tag: strings.TrimPrefix(tok.value, "#")}, nil
+	case "folder":
+		return &FolderQuery{Folder: strings.Trim(tok.value, "/")}, nil
+	default:
+		return p.parseDate(tok)
+	}
+}
+
+// parseDate parses a created:/updated: value into a time range
+func (p *queryParser) parseDate(tok queryToken) (Query, error) {
+	value := tok.value
+
+	// Comparison operator
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, candidate) {
+			op = candidate
+			value = value[len(candidate):]
+			break
+		}
+	}
+	valPos := tok.valPos + len(op)
+	if value == "" {
+		return nil, p.errorAt(valPos, "missing date")
+	}
+
+	q := &DateQuery{Field: tok.text, Raw: tok.value}
+
+	// Relative age: 7d, 12h, 2w, 3m, 1y
+	if age, ok := parseAge(value); ok {
+		cutoff := p.now.Add(-age)
+		switch op {
+		case "<", "<=":
+			// Less than 7 days ago
+			q.From = cutoff
+		case ">", ">=":
+			// More than 7 days ago
+			q.To = cutoff
+		default:
+			return nil, p.errorAt(tok.valPos, "relative dates need < or >")
+		}
+		return q, nil
+	}
+
+	// Absolute day: 2026-01-02
+	day, err := time.ParseInLocation("2006-01-02", value, p.now.Location())
+	if err != nil {
+		return nil, p.errorAt(valPos, fmt.Sprintf("invalid date %q (use YYYY-MM-DD or an age like 7d)", value))
+	}
+	nextDay := day.AddDate(0, 0, 1)
+
+	switch op {
+	case ">":
+		q.From = nextDay
+	case ">=":
+		q.From = day
+	case "<":
+		q.To = day
+	case "<=":
+		q.To = nextDay
+	default:
+		q.From = day
+		q.To = nextDay
+	}
+	return q, nil
+}
+
+// parseAge parses a relative age such as 7d into a duration
+func parseAge(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	day := 24 * time.Hour
+	units := map[byte]time.Duration{'h': time.Hour, 'd': day, 'w': 7 * day, 'm': 30 * day, 'y': 365 * day}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
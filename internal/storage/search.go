@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"strings"
+)
+
+// queryEvaluator matches a parsed Query against the search index
+type queryEvaluator struct {
+	fs  *LocalFileSystem
+	idx *searchIndex
+
+	// scores accumulates BM25 scores of the positive terms per document
+	scores map[string]float64
+
+	// texts caches note title and content loaded for regular expressions
+	texts map[string]string
+}
+
+// docSet is a set of note IDs
+type docSet map[string]bool
+
+// newQueryEvaluator creates an evaluator over idx
+// The caller must hold fs.indexMu for as long as the evaluator is used
+func newQueryEvaluator(fs *LocalFileSystem, idx *searchIndex) *queryEvaluator {
+	return &queryEvaluator{
+		fs:     fs,
+		idx:    idx,
+		scores: make(map[string]float64),
+		texts:  make(map[string]string),
+	}
+}
+
+// run evaluates q and returns the matching IDs, best matches first
+func (e *queryEvaluator) run(q Query) []string {
+	matches := e.eval(q, false)
+
+	scores := make(map[string]float64, len(matches))
+	for id := range matches {
+		scores[id] = e.scores[id]
+	}
+	return e.idx.rank(scores)
+}
+
+// eval returns the documents matching q
+// Terms are only scored when they are not negated
+func (e *queryEvaluator) eval(q Query, negated bool) docSet {
+	switch q := q.(type) {
+	case *TermQuery:
+		return e.evalText(q.Term, false, false, negated)
+
+	case *PhraseQuery:
+		return e.evalText(q.Phrase, true, false, negated)
+
+	case *TitleQuery:
+		return e.evalText(q.Text, q.Phrase, true, negated)
+
+	case *TagQuery:
+		return e.filter(func(_ string, doc *indexedDoc) bool {
+			for _, tag := range doc.Tags {
+				if strings.EqualFold(tag, q.Tag) {
+					return true
+				}
+			}
+			return false
+		})
+
+	case *FolderQuery:
+		return e.filter(func(_ string, doc *indexedDoc) bool {
+			return q.Folder == "" || doc.Folder == q.Folder || strings.HasPrefix(doc.Folder, q.Folder+"/")
+		})
+
+	case *DateQuery:
+		return e.filter(func(_ string, doc *indexedDoc) bool {
+			t := doc.UpdatedAt
+			if q.Field == "created" {
+				t = doc.CreatedAt
+			}
+			if !q.From.IsZero() && t.Before(q.From) {
+				return false
+			}
+			if !q.To.IsZero() && !t.Before(q.To) {
+				return false
+			}
+			return true
+		})
+
+	case *RegexQuery:
+		return e.filter(func(id string, doc *indexedDoc) bool {
+			return q.Pattern.MatchString(e.text(id, doc))
+		})
+
+	case *NotQuery:
+		excluded := e.eval(q.Clause, !negated)
+		return e.filter(func(id string, _ *indexedDoc) bool {
+			return !excluded[id]
+		})
+
+	case *AndQuery:
+		var result docSet
+		for _, clause := range q.Clauses {
+			matches := e.eval(clause, negated)
+			if result == nil {
+				result = matches
+				continue
+			}
+			for id := range result {
+				if !matches[id] {
+					delete(result, id)
+				}
+			}
+		}
+		return result
+
+	case *OrQuery:
+		result := make(docSet)
+		for _, clause := range q.Clauses {
+			for id := range e.eval(clause, negated) {
+				result[id] = true
+			}
+		}
+		return result
+	}
+
+	return make(docSet)
+}
+
+// evalText matches a word or a phrase, optionally restricted to the title
+// A single word matches as a prefix, a phrase needs its words in order
+func (e *queryEvaluator) evalText(text string, phrase, titleOnly, negated bool) docSet {
+	tokens := tokenize(text)
+
+	// Nothing searchable (punctuation only): the clause doesn't restrict anything
+	if len(tokens) == 0 {
+		return e.filter(func(string, *indexedDoc) bool { return true })
+	}
+
+	// A single word: prefix match
+	if len(tokens) == 1 && !phrase {
+		result := make(docSet)
+		for _, term := range e.idx.expand(tokens[0]) {
+			for id, positions := range e.idx.Postings[term] {
+				if titleOnly && positions[0] >= e.idx.Docs[id].TitleLength {
+					continue
+				}
+				result[id] = true
+				if !negated {
+					e.scores[id] += e.idx.bm25(term, id)
+				}
+			}
+		}
+		return result
+	}
+
+	// Several words: exact phrase
+	result := make(docSet)
+	for id, starts := range e.idx.Postings[tokens[0]] {
+		doc := e.idx.Docs[id]
+		for _, start := range starts {
+			if titleOnly && start+len(tokens) > doc.TitleLength {
+				break
+			}
+			if e.hasPhraseAt(id, tokens, start) {
+				result[id] = true
+				break
+			}
+		}
+	}
+
+	if !negated {
+		for id := range result {
+			for _, token := range tokens {
+				e.scores[id] += e.idx.bm25(token, id)
+			}
+		}
+	}
+	return result
+}
+
+// hasPhraseAt reports whether tokens appear consecutively from position start
+func (e *queryEvaluator) hasPhraseAt(id string, tokens []string, start int) bool {
+	for offset, token := range tokens[1:] {
+		if !containsInt(e.idx.Postings[token][id], start+offset+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns every indexed document for which keep returns true
+func (e *queryEvaluator) filter(keep func(id string, doc *indexedDoc) bool) docSet {
+	result := make(docSet)
+	for id, doc := range e.idx.Docs {
+		if keep(id, doc) {
+			result[id] = true
+		}
+	}
+	return result
+}
+
+// text returns the title and content of a note, loading it on first use
+func (e *queryEvaluator) text(id string, doc *indexedDoc) string {
+	if text, ok := e.texts[id]; ok {
+		return text
+	}
+
+	text := ""
+	if note, err := e.fs.parseNote(doc.Path); err == nil {
+		text = note.Title + "\n" + note.Content
+	}
+	e.texts[id] = text
+	return text
+}
+
+// containsInt reports whether sorted contains v
+func containsInt(sorted []int, v int) bool {
+	lo, hi := 0, len(sorted)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case sorted[mid] == v:
+			return true
+		case sorted[mid] < v:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return false
+}
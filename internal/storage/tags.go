@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// hashtagPattern matches a #hashtag and the character before it, which must
+// not make it part of a word, a URL fragment or a heading such as "##"
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#\\])#([\p{L}\p{N}_][\p{L}\p{N}_/-]*)`)
+
+// inlineCodePattern matches `code spans`, whose content is never a tag
+var inlineCodePattern = regexp.MustCompile("`[^`\n]*`")
+
+// TagCount is a tag with the number of notes carrying it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// TagIndex is implemented by storages that index the tags of notes
+// Tags come from the frontmatter and from #hashtags in the content, and are
+// compared without case
+type TagIndex interface {
+	// ListTags returns every tag with the number of notes carrying it, sorted by name
+	ListTags(ctx context.Context) ([]TagCount, error)
+
+	// NotesByTag returns the notes carrying tag, sorted by title
+	NotesByTag(ctx context.Context, tag string) ([]*Note, error)
+}
+
+// AllTags returns the tags of the note: those of its frontmatter, then the
+// #hashtags of its content, without duplicates
+func (n *Note) AllTags() []string {
+	tags := append([]string(nil), n.Tags...)
+	for _, tag := range InlineTags(n.Content) {
+		if !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// HasTag reports whether the note carries tag, in its frontmatter or its content
+func (n *Note) HasTag(tag string) bool {
+	return containsTag(n.AllTags(), tag)
+}
+
+// AddTag adds tag to the frontmatter of the note
+// It reports false when the note already carries it
+func (n *Note) AddTag(tag string) bool {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	if tag == "" || n.HasTag(tag) {
+		return false
+	}
+	n.Tags = append(n.Tags, tag)
+	return true
+}
+
+// RemoveTag removes tag from the frontmatter of the note and turns its
+// #hashtags into plain words
+// It reports false when the note didn't carry it
+func (n *Note) RemoveTag(tag string) bool {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	changed := false
+
+	tags := n.Tags[:0:0]
+	for _, t := range n.Tags {
+		if strings.EqualFold(t, tag) {
+			changed = true
+			continue
+		}
+		tags = append(tags, t)
+	}
+	n.Tags = tags
+
+	var b strings.Builder
+	last := 0
+	scanHashtags(n.Content, func(start int, name string) {
+		if strings.EqualFold(name, tag) {
+			b.WriteString(n.Content[last:start])
+			last = start + 1 // Drop the '#'
+			changed = true
+		}
+	})
+	if last > 0 {
+		b.WriteString(n.Content[last:])
+		n.Content = b.String()
+	}
+	return changed
+}
+
+// InlineTags returns the #hashtags of content in order, without duplicates
+// Hashtags in code, URL fragments and numbers such as #42 are not tags
+func InlineTags(content string) []string {
+	var tags []string
+	scanHashtags(content, func(_ int, tag string) {
+		if !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	})
+	return tags
+}
+
+// scanHashtags calls found with the byte offset of the '#' and the name of
+// every hashtag of content, skipping code blocks and code spans
+func scanHashtags(content string, found func(start int, tag string)) {
+	scanProse(content, func(offset int, line string) {
+		for _, match := range hashtagPattern.FindAllStringSubmatchIndex(line, -1) {
+			hash := match[2] - 1
+			if hash >= 2 && line[hash-2:hash] == "](" {
+				// A link to an anchor: [see](#section)
+				continue
+			}
+			tag := strings.TrimRight(line[match[2]:match[3]], "/-")
+			if !strings.ContainsFunc(tag, unicode.IsLetter) {
+				// An issue number or a channel such as #42
+				continue
+			}
+			found(offset+hash, tag)
+		}
+	})
+}
+
+// scanProse calls visit with the byte offset and the text of every line of
+// content outside code blocks, with its code spans blanked out
+func scanProse(content string, visit func(offset int, line string)) {
+	fence := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		// Blank code spans out, keeping the offsets
+		line = inlineCodePattern.ReplaceAllStringFunc(line, func(code string) string {
+			return strings.Repeat(" ", len(code))
+		})
+		visit(start, line)
+	}
+}
+
+// containsTag reports whether tags contains tag, ignoring case
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListTags returns every tag with the number of notes carrying it, sorted by name
+// A tag written with different cases is shown as it is most often written
+func (fs *LocalFileSystem) ListTags(ctx context.Context) ([]TagCount, error) {
+	fs.indexMu.Lock()
+	idx, err := fs.refreshIndex()
+	if err != nil {
+		fs.indexMu.Unlock()
+		return nil, fmt.Errorf("could not list tags: %w", err)
+	}
+
+	counts := make(map[string]int)
+	spellings := make(map[string]map[string]int)
+	for _, doc := range idx.Docs {
+		for _, tag := range doc.Tags {
+			key := strings.ToLower(tag)
+			counts[key]++
+			if spellings[key] == nil {
+				spellings[key] = make(map[string]int)
+			}
+			spellings[key][tag]++
+		}
+	}
+	fs.indexMu.Unlock()
+
+	tags := make([]TagCount, 0, len(counts))
+	for key, count := range counts {
+		name, uses := "", 0
+		for spelling, n := range spellings[key] {
+			if n > uses || (n == uses && spelling < name) {
+				name, uses = spelling, n
+			}
+		}
+		tags = append(tags, TagCount{Tag: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i].Tag) < strings.ToLower(tags[j].Tag)
+	})
+	return tags, nil
+}
+
+// NotesByTag returns the notes carrying tag, sorted by title
+func (fs *LocalFileSystem) NotesByTag(ctx context.Context, tag string) ([]*Note, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+
+	fs.indexMu.Lock()
+	idx, err := fs.refreshIndex()
+	if err != nil {
+		fs.indexMu.Unlock()
+		return nil, fmt.Errorf("could not list notes tagged %s: %w", tag, err)
+	}
+	var paths []string
+	for _, doc := range idx.Docs {
+		if containsTag(doc.Tags, tag) {
+			paths = append(paths, doc.Path)
+		}
+	}
+	fs.indexMu.Unlock()
+
+	notes, err := fs.loadNotes(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return strings.ToLower(notes[i].Title) < strings.ToLower(notes[j].Title)
+	})
+	return notes, nil
+}
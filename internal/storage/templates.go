@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// templatesDirName is the hidden directory inside a vault holding note
+	// templates, kept out of the notes but not out of git
+	templatesDirName = ".templates"
+
+	// cursorMarker stands for {{cursor}} while a template is rendered
+	cursorMarker = "\x00leaf-cursor\x00"
+)
+
+// Template is a skeleton new notes start from, written with text/template
+//
+// Besides the fields of TemplateData, {{prompt "Name"}} asks for a value
+// when the note is created and {{cursor}} marks where editing starts
+// A frontmatter in the template gives the note its default title, tags,
+// aliases and custom fields
+type Template struct {
+	Name    string // File name without the .md extension
+	Content string
+}
+
+// TemplateData holds the values a template is rendered with
+type TemplateData struct {
+	Title  string
+	User   string
+	Now    time.Time
+	Fields map[string]string // Values of the prompted fields, by name
+}
+
+// Templates is implemented by storages offering note templates
+type Templates interface {
+	// ListTemplates returns every template, sorted by name
+	ListTemplates(ctx context.Context) ([]*Template, error)
+
+	// GetTemplate returns the template called name, without case, failing
+	// with ErrNotFound if there is none
+	GetTemplate(ctx context.Context, name string) (*Template, error)
+}
+
+// NewTemplateData returns the data to render a template for a note titled
+// title, created now by the current user
+func NewTemplateData(title string) TemplateData {
+	return TemplateData{Title: title, User: currentUser(), Now: time.Now()}
+}
+
+// Date returns the day the note is created, as 2006-01-02
+func (d TemplateData) Date() string {
+	return d.Now.Format("2006-01-02")
+}
+
+// Time returns the time the note is created, as 15:04
+func (d TemplateData) Time() string {
+	return d.Now.Format("15:04")
+}
+
+// Fields returns the names of the fields the template prompts for, in order
+func (t *Template) Fields() ([]string, error) {
+	var fields []string
+	seen := map[string]bool{}
+	prompt := func(name string) string {
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+		return ""
+	}
+
+	if _, err := t.execute(TemplateData{}, prompt); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Render creates a note from the template
+// It also returns the byte offset of {{cursor}} in the content, -1 when the
+// template has none
+func (t *Template) Render(data TemplateData) (*Note, int, error) {
+	out, err := t.execute(data, func(name string) string {
+		return data.Fields[name]
+	})
+	if err != nil {
+		return nil, -1, err
+	}
+
+	note := NewNote(data.Title, out)
+	if block, body, ok := splitFrontmatter(out); ok {
+		fm, custom, err := decodeFrontmatter(strings.ReplaceAll(block, cursorMarker, ""))
+		if err != nil {
+			return nil, -1, fmt.Errorf("could not render template %s: %w", t.Name, err)
+		}
+		if note.Title == "" {
+			note.Title = fm.Title
+		}
+		note.Tags = fm.Tags
+		note.Aliases = fm.Aliases
+		note.Metadata = custom
+		note.Content = body
+	}
+
+	cursor := strings.Index(note.Content, cursorMarker)
+	note.Content = strings.ReplaceAll(note.Content, cursorMarker, "")
+	return note, cursor, nil
+}
+
+// execute renders the template, calling prompt for the prompted fields
+func (t *Template) execute(data TemplateData, prompt func(name string) string) (string, error) {
+	tmpl, err := template.New(t.Name).Funcs(template.FuncMap{
+		"prompt": prompt,
+		"cursor": func() string { return cursorMarker },
+	}).Parse(t.Content)
+	if err != nil {
+		return "", fmt.Errorf("could not parse template %s: %w", t.Name, err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("could not render template %s: %w", t.Name, err)
+	}
+	return b.String(), nil
+}
+
+// currentUser returns the name of the user running leaf, "" when unknown
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		if u.Name != "" {
+			return u.Name
+		}
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// templatesDir returns the path of the templates directory
+func (fs *LocalFileSystem) templatesDir() string {
+	return filepath.Join(fs.notesDir, templatesDirName)
+}
+
+// ListTemplates returns the templates of the vault's .templates directory
+func (fs *LocalFileSystem) ListTemplates(ctx context.Context) ([]*Template, error) {
+	entries, err := os.ReadDir(fs.templatesDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list templates: %w", err)
+	}
+
+	var templates []*Template
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if !ok || entry.IsDir() || validateID(name) != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(fs.templatesDir(), entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read template %s: %w", name, err)
+		}
+		templates = append(templates, &Template{Name: name, Content: string(data)})
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return strings.ToLower(templates[i].Name) < strings.ToLower(templates[j].Name)
+	})
+	return templates, nil
+}
+
+// GetTemplate returns the template called name
+func (fs *LocalFileSystem) GetTemplate(ctx context.Context, name string) (*Template, error) {
+	templates, err := fs.ListTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no template %q", ErrNotFound, name)
+}
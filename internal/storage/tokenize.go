@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// tokenize splits text into normalized search terms
+// Terms are lowercased, stripped of diacritics ("Café" → "cafe") and split on
+// anything that isn't a letter or a digit
+func tokenize(text string) []string {
+	var tokens []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	// NFKD decomposes accented letters into a base letter and combining marks,
+	// and folds compatibility forms such as ligatures and full-width letters
+	for _, r := range norm.NFKD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop combining marks so accents don't affect matching
+			continue
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			current.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
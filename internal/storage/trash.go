@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// trashDirName is the hidden directory inside a vault holding deleted notes
+	trashDirName = ".trash"
+
+	// trashRecordExt is the extension of the file describing a trashed note
+	trashRecordExt = ".json"
+
+	// DefaultTrashRetention is how long deleted notes are kept unless
+	// WithTrashRetention says otherwise
+	DefaultTrashRetention = 30 * 24 * time.Hour
+)
+
+// TrashedNote describes a deleted note kept in the trash
+type TrashedNote struct {
+	TrashID      string    `json:"-"`             // Identifies the note in the trash
+	ID           string    `json:"id"`            // ID of the note when it was deleted
+	Title        string    `json:"title"`         // Title of the note when it was deleted
+	OriginalPath string    `json:"original_path"` // Where the note file was
+	DeletedAt    time.Time `json:"deleted_at"`
+}
+
+// Trash is implemented by storages that keep deleted notes for a while
+// instead of removing them at once
+type Trash interface {
+	// ListTrash returns the trashed notes, most recently deleted first
+	ListTrash(ctx context.Context) ([]*TrashedNote, error)
+
+	// RestoreNote puts a trashed note back where it was
+	// It fails with ErrConflict if a note with the same ID exists again
+	RestoreNote(ctx context.Context, trashID string) (*Note, error)
+
+	// PurgeNote removes a trashed note for good
+	PurgeNote(ctx context.Context, trashID string) error
+
+	// EmptyTrash removes every trashed note for good
+	EmptyTrash(ctx context.Context) error
+}
+
+// WithTrashRetention sets how long deleted notes stay in the trash before
+// PurgeExpired removes them; 0 keeps them until the trash is emptied
+func WithTrashRetention(retention time.Duration) LocalOption {
+	return func(fs *LocalFileSystem) {
+		fs.trashRetention = retention
+	}
+}
+
+// trashDir returns the path of the trash directory
+func (fs *LocalFileSystem) trashDir() string {
+	return filepath.Join(fs.notesDir, trashDirName)
+}
+
+// trashPaths returns the paths of the note file and of the record of a trashed note
+func (fs *LocalFileSystem) trashPaths(trashID string) (note, record string, err error) {
+	if err := validateName(trashID, maxTrashIDLength); err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(fs.trashDir(), trashID)
+	return base + ".md", base + trashRecordExt, nil
+}
+
+// trashNote moves the file of note id at path to the trash
+func (fs *LocalFileSystem) trashNote(id, path string) error {
+	if _, err := os.Lstat(path); err != nil {
+		return readError(err)
+	}
+
+	// Read the title so the trash can show it, the file may not parse
+	title := id
+	if note, err := fs.parseNote(path); err == nil && note.Title != "" {
+		title = note.Title
+	}
+
+	if err := os.MkdirAll(fs.trashDir(), dirMode(fs.fileMode)); err != nil {
+		return writeError(err)
+	}
+
+	now := time.Now()
+	trashed := &TrashedNote{
+		TrashID:      fmt.Sprintf("%s-%d", id, now.UnixNano()),
+		ID:           id,
+		Title:        title,
+		OriginalPath: path,
+		DeletedAt:    now,
+	}
+	notePath, recordPath, err := fs.trashPaths(trashed.TrashID)
+	if err != nil {
+		return err
+	}
+
+	// The record goes first: a record without a note is ignored, a note
+	// without a record would be lost in the trash
+	data, err := json.MarshalIndent(trashed, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(recordPath, data, fs.fileMode); err != nil {
+		return writeError(err)
+	}
+	if err := os.Rename(path, notePath); err != nil {
+		os.Remove(recordPath)
+		return writeError(readError(err))
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// ListTrash returns the trashed notes, most recently deleted first
+func (fs *LocalFileSystem) ListTrash(ctx context.Context) ([]*TrashedNote, error) {
+	entries, err := os.ReadDir(fs.trashDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the trash: %w", err)
+	}
+
+	var notes []*TrashedNote
+	for _, entry := range entries {
+		trashID, ok := strings.CutSuffix(entry.Name(), trashRecordExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		trashed, err := fs.trashRecord(trashID)
+		if err != nil {
+			// Left over from an interrupted deletion
+			continue
+		}
+		notes = append(notes, trashed)
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].DeletedAt.After(notes[j].DeletedAt)
+	})
+	return notes, nil
+}
+
+// trashRecord reads the record of a trashed note whose file is still there
+func (fs *LocalFileSystem) trashRecord(trashID string) (*TrashedNote, error) {
+	notePath, recordPath, err := fs.trashPaths(trashID)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed, err := fs.trashRecordFile(recordPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(notePath); err != nil {
+		return nil, readError(err)
+	}
+	trashed.TrashID = trashID
+	return trashed, nil
+}
+
+// trashRecordFile reads a trash record
+func (fs *LocalFileSystem) trashRecordFile(recordPath string) (*TrashedNote, error) {
+	data, err := os.ReadFile(recordPath)
+	if err != nil {
+		return nil, readError(err)
+	}
+	trashed := &TrashedNote{}
+	if err := json.Unmarshal(data, trashed); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, filepath.Base(recordPath), err)
+	}
+	return trashed, nil
+}
+
+// RestoreNote puts a trashed note back in the folder it was deleted from
+// It fails with ErrConflict if a note with the same ID was created since
+func (fs *LocalFileSystem) RestoreNote(ctx context.Context, trashID string) (*Note, error) {
+	trashed, err := fs.trashRecord(trashID)
+	if err != nil {
+		return nil, fmt.Errorf("could not restore %s: %w", trashID, err)
+	}
+
+	fs.saveMu.Lock()
+	defer fs.saveMu.Unlock()
+
+	if _, exists := fs.findNote(trashed.ID); exists {
+		return nil, fmt.Errorf("could not restore %s: a note with ID %s exists: %w", trashed.Title, trashed.ID, ErrConflict)
+	}
+
+	// The note gets its file name back, unless another note took it
+	name := trashed.ID
+	if trashed.OriginalPath != "" {
+		name = strings.TrimSuffix(filepath.Base(trashed.OriginalPath), ".md")
+	}
+	target, err := fs.freePath(fs.restoreFolder(trashed), name, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not restore %s: %w", trashID, err)
+	}
+
+	// The folder may have been removed since
+	if err := os.MkdirAll(filepath.Dir(target), dirMode(fs.fileMode)); err != nil {
+		return nil, fmt.Errorf("could not restore %s: %w", trashed.Title, writeError(err))
+	}
+	notePath, recordPath, _ := fs.trashPaths(trashID)
+	if err := os.Rename(notePath, target); err != nil {
+		return nil, fmt.Errorf("could not restore %s: %w", trashed.Title, writeError(readError(err)))
+	}
+	if err := syncDir(filepath.Dir(target)); err != nil {
+		return nil, fmt.Errorf("could not restore %s: %w", trashed.Title, err)
+	}
+	os.Remove(recordPath)
+	fs.setLocation(trashed.ID, target)
+
+	note, err := fs.parseNote(target)
+	if err != nil {
+		return nil, fmt.Errorf("could not load restored note %s: %w", trashed.ID, err)
+	}
+
+	// Keep the search index in sync
+	_ = fs.indexNote(note)
+
+	return note, nil
+}
+
+// PurgeNote removes a trashed note for good
+func (fs *LocalFileSystem) PurgeNote(ctx context.Context, trashID string) error {
+	notePath, recordPath, err := fs.trashPaths(trashID)
+	if err != nil {
+		return fmt.Errorf("could not purge %s: %w", trashID, err)
+	}
+
+	if err := os.Remove(notePath); err != nil {
+		return fmt.Errorf("could not purge %s: %w", trashID, writeError(readError(err)))
+	}
+	trashed, _ := fs.trashRecordFile(recordPath)
+	if err := os.Remove(recordPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not purge %s: %w", trashID, writeError(err))
+	}
+
+	// Its versions go too, unless the ID is in use again
+	if trashed != nil && validateID(trashed.ID) == nil {
+		if _, exists := fs.findNote(trashed.ID); !exists {
+			_ = fs.removeHistory(trashed.ID)
+		}
+	}
+	return nil
+}
+
+// EmptyTrash removes every trashed note for good
+func (fs *LocalFileSystem) EmptyTrash(ctx context.Context) error {
+	notes, err := fs.ListTrash(ctx)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		if err := fs.PurgeNote(ctx, note.TrashID); err != nil {
+			return err
+		}
+	}
+
+	// Whatever is left are leftovers of interrupted deletions
+	if err := os.RemoveAll(fs.trashDir()); err != nil {
+		return fmt.Errorf("could not empty the trash: %w", writeError(err))
+	}
+	return nil
+}
+
+// PurgeExpired removes the notes deleted longer ago than the trash retention,
+// along with the leftovers of interrupted deletions, and returns the notes removed
+func (fs *LocalFileSystem) PurgeExpired(ctx context.Context) ([]*TrashedNote, error) {
+	if fs.trashRetention <= 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(fs.trashDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the trash: %w", err)
+	}
+
+	var purged []*TrashedNote
+	for _, entry := range entries {
+		trashID, ok := strings.CutSuffix(entry.Name(), trashRecordExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		trashed, err := fs.trashRecord(trashID)
+		if errors.Is(err, ErrNotFound) {
+			// A record whose note never made it to the trash
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > staleTempAge {
+				os.Remove(filepath.Join(fs.trashDir(), entry.Name()))
+			}
+			continue
+		}
+		if err != nil || time.Since(trashed.DeletedAt) < fs.trashRetention {
+			continue
+		}
+
+		if err := fs.PurgeNote(ctx, trashID); err != nil {
+			return purged, err
+		}
+		purged = append(purged, trashed)
+	}
+	return purged, nil
+}
@@ -1,8 +1,81 @@
 package ui
 
-// This file will contain reusable UI components
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// This file contains reusable UI components
 // TODO: Implement the following components:
-// - NoteList: List of notes with selection
 // - NoteEditor: Note editor with syntax highlighting
 // - SearchBar: Search bar
 // - StatusBar: Status bar with keyboard shortcuts
+
+// NoteList is a window of note titles with the selected one marked
+type NoteList struct {
+	Titles   []string
+	Selected int
+	Offset   int // First visible title
+	Width    int // Titles are cut to this width, 0 means no limit
+	Height   int // Number of visible titles
+}
+
+// View renders the visible titles, one per line
+func (l NoteList) View() string {
+	var lines []string
+	for i := l.Offset; i < len(l.Titles) && i < l.Offset+l.Height; i++ {
+		prefix := "  "
+		if i == l.Selected {
+			prefix = "> "
+		}
+		line := prefix + l.Titles[i]
+		if l.Width > 0 {
+			// Cut long titles instead of wrapping them
+			line = lipgloss.NewStyle().Width(l.Width).Render(ansi.Truncate(line, l.Width, "…"))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Preview is a note rendered as markdown into a box of fixed width
+type Preview struct {
+	Height int // Lines shown, the rest of the note is cut
+
+	width    int
+	rendered string
+}
+
+// NewPreview renders content wrapped to width
+// Errors fall back to the raw markdown
+func NewPreview(content string, width int) Preview {
+	rendered, err := RenderMarkdown(content, width)
+	if err != nil {
+		rendered = content
+	}
+	return Preview{width: width, rendered: rendered}
+}
+
+// Width returns the width the preview was rendered for
+func (p Preview) Width() int {
+	return p.width
+}
+
+// View renders the preview, padded or cut to exactly Width x Height
+func (p Preview) View() string {
+	lines := strings.Split(p.rendered, "\n")
+	if len(lines) > p.Height {
+		lines = lines[:p.Height]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, p.width, "")
+	}
+
+	return lipgloss.NewStyle().
+		Width(p.width).
+		Height(p.Height).
+		MaxHeight(p.Height).
+		Render(strings.Join(lines, "\n"))
+}
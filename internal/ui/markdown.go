@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+)
+
+// MarkdownStyle is the glamour style used to render notes
+// It can be any standard style name ("dark", "light", "dracula", "notty", ...)
+// and must be set before the first note is rendered
+var MarkdownStyle = styles.DarkStyle
+
+var (
+	renderersMu sync.Mutex
+	renderers   = make(map[int]*glamour.TermRenderer)
+)
+
+// RenderMarkdown renders markdown for a terminal of the given width
+// Code blocks are highlighted per language by Chroma
+func RenderMarkdown(content string, width int) (string, error) {
+	r, err := markdownRenderer(width)
+	if err != nil {
+		return "", err
+	}
+
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	out, err := r.Render(content)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(out, "\n"), nil
+}
+
+// markdownRenderer returns the renderer for a width, creating it on first use
+// Building a renderer parses the whole style, so they are cached per width
+func markdownRenderer(width int) (*glamour.TermRenderer, error) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	if r, ok := renderers[width]; ok {
+		return r, nil
+	}
+
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(MarkdownStyle),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return nil, err
+	}
+	renderers[width] = r
+	return r, nil
+}
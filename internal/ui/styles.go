@@ -19,6 +19,30 @@ var (
 				Foreground(lipgloss.Color("63")).
 				Bold(true)
 
+	// Search styles
+	HighlightStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("229")).
+			Background(lipgloss.Color("58")).
+			Bold(true)
+
+	SnippetStyle = lipgloss.NewStyle().
+			PaddingLeft(4).
+			Foreground(lipgloss.Color("245"))
+
+	// Quick open styles
+	OverlayStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1)
+
+	FuzzyMatchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("212")).
+			Bold(true).
+			Underline(true)
+
+	DimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+
 	// Editor styles
 	EditorStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
@@ -40,4 +64,11 @@ var (
 	SuccessStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("46")).
 			Bold(true)
+
+	// Diff styles
+	DiffInsertStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("42"))
+
+	DiffDeleteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203"))
 )
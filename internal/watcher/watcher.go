@@ -0,0 +1,331 @@
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// DefaultPollInterval is how often the polling fallback scans the directory
+	DefaultPollInterval = time.Second
+
+	// settleDelay groups the bursts of events an editor or a sync tool makes
+	// for a single save
+	settleDelay = 100 * time.Millisecond
+
+	noteExt = ".md"
+)
+
+// Op is the kind of change reported by an Event
+type Op int
+
+const (
+	Changed Op = iota // The note was created or modified
+	Removed           // The note file is gone
+	Rescan            // Events were lost, every note should be reloaded
+)
+
+// Event reports a change to a note file
+type Event struct {
+	Op   Op
+	ID   string // Note ID, derived from the file name; files named after the title have it in their frontmatter
+	Path string
+}
+
+// Watcher reports changes to the notes of a directory and its subdirectories
+// It uses inotify (or the platform equivalent) when available, and polls otherwise
+// Hidden directories, such as .leaf and .trash, are not watched
+type Watcher struct {
+	dir    string
+	events chan Event
+	done   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+
+	notify *fsnotify.Watcher // nil when polling
+	dirs   map[string]bool   // Directories watched by notify
+}
+
+// New watches dir with filesystem notifications, falling back to polling
+// when they are not available
+func New(dir string) (*Watcher, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return NewPoller(dir, DefaultPollInterval), nil
+	}
+
+	w := newWatcher(dir)
+	w.notify = notify
+	if err := w.addTree(dir); err != nil {
+		notify.Close()
+		return NewPoller(dir, DefaultPollInterval), nil
+	}
+	w.wg.Add(1)
+	go w.runNotify()
+	return w, nil
+}
+
+// NewPoller watches dir by scanning it every interval
+func NewPoller(dir string, interval time.Duration) *Watcher {
+	w := newWatcher(dir)
+	// Scan before returning so changes made right after are reported
+	initial := w.scan()
+	w.wg.Add(1)
+	go w.runPoll(interval, initial)
+	return w
+}
+
+// newWatcher creates a watcher without starting it
+func newWatcher(dir string) *Watcher {
+	return &Watcher{
+		dir:    dir,
+		events: make(chan Event),
+		done:   make(chan struct{}),
+		dirs:   make(map[string]bool),
+	}
+}
+
+// addTree watches dir and every directory below it, skipping hidden ones
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != w.dir && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := w.notify.Add(path); err != nil {
+			return err
+		}
+		w.dirs[path] = true
+		return nil
+	})
+}
+
+// removeTree stops watching dir and the directories below it
+func (w *Watcher) removeTree(dir string) {
+	for path := range w.dirs {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			// Already gone when the directory was removed
+			_ = w.notify.Remove(path)
+			delete(w.dirs, path)
+		}
+	}
+}
+
+// Events returns the channel of note changes, closed by Close
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Polling reports whether the watcher fell back to polling
+func (w *Watcher) Polling() bool {
+	return w.notify == nil
+}
+
+// Close stops the watcher and closes the events channel
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		if w.notify != nil {
+			err = w.notify.Close()
+		}
+		w.wg.Wait()
+		close(w.events)
+	})
+	return err
+}
+
+// runNotify turns filesystem notifications into events
+// Notifications for a file are gathered until it settles, then the file is
+// checked to tell a change from a removal
+// A directory appearing or going away moves notes without an event for each,
+// so it asks for a rescan instead
+func (w *Watcher) runNotify() {
+	defer w.wg.Done()
+
+	pending := make(map[string]bool)
+	rescan := false
+	settle := time.NewTimer(settleDelay)
+	settle.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case ev, ok := <-w.notify.Events:
+			if !ok {
+				return
+			}
+			if w.dirs[ev.Name] && (ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename)) {
+				w.removeTree(ev.Name)
+				rescan = true
+				settle.Reset(settleDelay)
+				continue
+			}
+			if ev.Has(fsnotify.Create) && isWatchedDir(ev.Name) {
+				// Notes may have been written before the directory was watched
+				_ = w.addTree(ev.Name)
+				rescan = true
+				settle.Reset(settleDelay)
+				continue
+			}
+			if !isNoteFile(ev.Name) || ev.Op == fsnotify.Chmod {
+				continue
+			}
+			pending[ev.Name] = true
+			settle.Reset(settleDelay)
+
+		case err, ok := <-w.notify.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				clear(pending)
+				if !w.send(Event{Op: Rescan}) {
+					return
+				}
+			}
+
+		case <-settle.C:
+			if rescan {
+				rescan = false
+				clear(pending)
+				if !w.send(Event{Op: Rescan}) {
+					return
+				}
+			}
+			for path := range pending {
+				if !w.send(w.eventFor(path)) {
+					return
+				}
+			}
+			clear(pending)
+		}
+	}
+}
+
+// runPoll scans the directory every interval and reports the differences
+func (w *Watcher) runPoll(interval time.Duration, previous map[string]fileState) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := w.scan()
+		for path, info := range current {
+			if old, ok := previous[path]; ok && old == info {
+				continue
+			}
+			if !w.send(Event{Op: Changed, ID: noteID(path), Path: path}) {
+				return
+			}
+		}
+		for path := range previous {
+			if _, ok := current[path]; ok {
+				continue
+			}
+			if !w.send(Event{Op: Removed, ID: noteID(path), Path: path}) {
+				return
+			}
+		}
+		previous = current
+	}
+}
+
+// fileState is what the poller compares between two scans
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+// scan returns the state of every note file in the directory and below it
+func (w *Watcher) scan() map[string]fileState {
+	files := make(map[string]fileState)
+
+	_ = filepath.WalkDir(w.dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path != w.dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isNoteFile(path) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		files[path] = fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		return nil
+	})
+	return files
+}
+
+// eventFor checks whether path still exists after a notification
+func (w *Watcher) eventFor(path string) Event {
+	ev := Event{Op: Changed, ID: noteID(path), Path: path}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		ev.Op = Removed
+	}
+	return ev
+}
+
+// send delivers an event, giving up when the watcher is closed
+func (w *Watcher) send(ev Event) bool {
+	select {
+	case w.events <- ev:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// isNoteFile reports whether path is a note, skipping hidden and temporary files
+func isNoteFile(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasSuffix(name, noteExt) && !strings.HasPrefix(name, ".")
+}
+
+// isWatchedDir reports whether path is a directory that should be watched
+func isWatchedDir(path string) bool {
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return false
+	}
+	info, err := os.Lstat(path)
+	return err == nil && info.IsDir()
+}
+
+// noteID returns the ID of the note stored at path
+func noteID(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), noteExt)
+}
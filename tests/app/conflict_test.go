@@ -0,0 +1,133 @@
+package app_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/diff"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// conflictingModel edits a note, changes it on disk meanwhile, then saves it
+// The returned model shows the conflict screen
+func conflictingModel(t *testing.T) (app.Model, *storage.LocalFileSystem, *storage.Note) {
+	t.Helper()
+	ctx := context.Background()
+
+	fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create storage: %v", err)
+	}
+	note := storage.NewNote("Plan", "one\ntwo\nthree")
+	if err := fs.SaveNote(ctx, note); err != nil {
+		t.Fatalf("could not save note: %v", err)
+	}
+
+	model := app.NewModel(app.WithStorage(fs))
+	model = drain(model, model.Init())
+
+	// Add a line at the end in the editor
+	model, _ = press(model, "e")
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(app.Model)
+	model, _ = press(model, "mine")
+
+	// Meanwhile, the first line changes on disk
+	other, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("could not load note: %v", err)
+	}
+	other.Content = "ONE\ntwo\nthree"
+	if err := fs.SaveNote(ctx, other); err != nil {
+		t.Fatalf("could not save note: %v", err)
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	model = drain(updated.(app.Model), cmd)
+	return model, fs, note
+}
+
+func TestSaveConflict(t *testing.T) {
+	t.Run("should open the conflict screen", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		model, _, _ := conflictingModel(t)
+
+		assert.Equal(app.ModeConflict, model.Mode(), "a conflicting save should open the conflict screen")
+		view := model.View()
+		assert.Contains(view, "changed on disk", "the conflict should be explained")
+		assert.Contains(view, "+ mine", "our change should be shown")
+		assert.Contains(view, "- ONE", "their change should be shown")
+	})
+
+	t.Run("o should overwrite the version on disk", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		model, fs, note := conflictingModel(t)
+		model, cmd := press(model, "o")
+		model = drain(model, cmd)
+
+		assert.Equal(app.ModeList, model.Mode(), "should return to the list")
+		saved, err := fs.GetNote(context.Background(), note.ID)
+		assert.NoError(err, "note should load")
+		assert.Equal("one\ntwo\nthree\nmine", saved.Content, "our version should be saved")
+	})
+
+	t.Run("r should drop our changes", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		model, _, note := conflictingModel(t)
+		model, cmd := press(model, "r")
+		model = drain(model, cmd)
+
+		assert.Equal(app.ModeView, model.Mode(), "should show the note")
+		assert.Equal("ONE\ntwo\nthree", model.CurrentNote().Content, "the version on disk should be shown")
+		data, err := os.ReadFile(note.FilePath)
+		assert.NoError(err, "note file should exist")
+		assert.False(strings.Contains(string(data), "mine"), "our changes should not be saved")
+	})
+
+	t.Run("m should merge both versions", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		model, fs, note := conflictingModel(t)
+		model, _ = press(model, "m")
+
+		assert.Equal(app.ModeEdit, model.Mode(), "the merge should be reviewed in the editor")
+		assert.Equal("ONE\ntwo\nthree\nmine", model.CurrentNote().Content, "both changes should be merged")
+		assert.Empty(model.LastError(), "there should be no conflict left")
+
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		model = drain(updated.(app.Model), cmd)
+
+		assert.Equal(app.ModeList, model.Mode(), "the merged note should save")
+		saved, err := fs.GetNote(context.Background(), note.ID)
+		assert.NoError(err, "note should load")
+		assert.Equal("ONE\ntwo\nthree\nmine", saved.Content, "the merged version should be saved")
+	})
+
+	t.Run("m should mark lines changed on both sides", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		model, fs, note := conflictingModel(t)
+		// Change the last line on disk too
+		other, err := fs.GetNote(context.Background(), note.ID)
+		assert.NoError(err, "note should load")
+		other.Content = "ONE\ntwo\nTHREE"
+		assert.NoError(fs.SaveNote(context.Background(), other), "note should save")
+		model, _ = press(model, "esc")
+		assert.Equal(app.ModeEdit, model.Mode(), "esc should go back to editing")
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		model = drain(updated.(app.Model), cmd)
+
+		model, _ = press(model, "m")
+
+		assert.Contains(model.CurrentNote().Content, diff.MarkerOurs, "the conflict should be marked")
+		assert.Contains(model.LastError(), "1 conflicting section", "the user should be told")
+	})
+}
@@ -0,0 +1,89 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+// flakyFileSystem fails its operations with the configured errors
+type flakyFileSystem struct {
+	mockFileSystem
+	listErr   error
+	deleteErr error
+}
+
+func (f *flakyFileSystem) ListNotes(ctx context.Context) ([]*storage.Note, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	return f.notes, nil
+}
+
+func (f *flakyFileSystem) DeleteNote(ctx context.Context, id string) error {
+	return f.deleteErr
+}
+
+func TestStorageErrors(t *testing.T) {
+	notes := func() []*storage.Note {
+		return []*storage.Note{{ID: "1", Title: "First"}, {ID: "2", Title: "Second"}}
+	}
+
+	t.Run("should drop a note that is already gone", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &flakyFileSystem{mockFileSystem: mockFileSystem{notes: notes()}}
+		model := app.NewModel(app.WithStorage(fs))
+		model = drain(model, model.Init())
+
+		fs.deleteErr = fmt.Errorf("could not delete note 1: %w", storage.ErrNotFound)
+		model, cmd := press(model, "d", "d")
+		model = drain(model, cmd)
+
+		assert.Len(model.Notes(), 1, "the missing note should be dropped")
+		assert.Equal("2", model.Notes()[0].ID, "the other note should stay")
+		assert.Empty(model.LastError(), "deleting a missing note is not an error")
+	})
+
+	t.Run("should offer to retry a transient failure", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &flakyFileSystem{
+			mockFileSystem: mockFileSystem{notes: notes()},
+			listErr:        errors.New("input/output error"),
+		}
+		model := app.NewModel(app.WithStorage(fs))
+		model = drain(model, model.Init())
+
+		assert.Contains(model.View(), "R to retry", "a retry should be offered")
+
+		fs.listErr = nil
+		model, cmd := press(model, "R")
+		assert.NotNil(cmd, "R should retry")
+		model = drain(model, cmd)
+
+		assert.Len(model.Notes(), 2, "notes should load on retry")
+		assert.Empty(model.LastError(), "the error should be cleared")
+		assert.False(strings.Contains(model.View(), "R to retry"), "the retry should no longer be offered")
+	})
+
+	t.Run("should not offer to retry a permanent failure", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &flakyFileSystem{mockFileSystem: mockFileSystem{notes: notes()}}
+		model := app.NewModel(app.WithStorage(fs))
+		model = drain(model, model.Init())
+
+		fs.deleteErr = fmt.Errorf("could not delete note 1: %w", storage.ErrReadOnly)
+		model, cmd := press(model, "d", "d")
+		model = drain(model, cmd)
+
+		assert.Contains(model.LastError(), "read-only", "the error should be shown")
+		assert.False(strings.Contains(model.View(), "R to retry"), "retrying can't help")
+		_, cmd = press(model, "R")
+		assert.Nil(cmd, "R should do nothing")
+	})
+}
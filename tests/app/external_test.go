@@ -0,0 +1,49 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestExternalEditor(t *testing.T) {
+	note := &storage.Note{ID: "1", Title: "Todo", Content: "Buy milk", FilePath: "/notes/1.md"}
+
+	t.Run("E should suspend the program for the editor", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := app.NewModel(app.WithStorage(&mockFileSystem{notes: []*storage.Note{note}}))
+		model = drain(model, model.Init())
+
+		_, cmd := press(model, "E")
+		assert.NotNil(cmd, "E should run the editor")
+
+		_, cmd = press(model, "r", "E")
+		assert.NotNil(cmd, "E should also work in view mode")
+	})
+
+	t.Run("should show the reloaded note and refresh the list", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := app.NewModel(app.WithStorage(&mockFileSystem{notes: []*storage.Note{note}}))
+		model = drain(model, model.Init())
+		model, _ = press(model, "r")
+
+		edited := &storage.Note{ID: "1", Title: "Todo", Content: "Buy bread", FilePath: "/notes/1.md"}
+		updated, cmd := model.Update(app.ExternalEditMsg{Note: edited, Changed: true})
+		model = updated.(app.Model)
+
+		assert.Equal("Buy bread", model.CurrentNote().Content, "viewed note should be reloaded")
+		assert.NotNil(cmd, "notes should be reloaded")
+	})
+
+	t.Run("should do nothing when the note is unchanged", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := app.NewModel(app.WithStorage(&mockFileSystem{notes: []*storage.Note{note}}))
+		model = drain(model, model.Init())
+
+		_, cmd := model.Update(app.ExternalEditMsg{Note: note})
+
+		assert.Nil(cmd, "nothing should be reloaded")
+	})
+}
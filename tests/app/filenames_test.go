@@ -0,0 +1,54 @@
+package app_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEditRenamesSlugFile(t *testing.T) {
+	assert := testutil.New(t)
+	dir := t.TempDir()
+	fs, err := storage.NewLocalFileSystemAt(dir, storage.WithFilenames(storage.FilenamesSlug))
+	if err != nil {
+		t.Fatalf("could not create storage: %v", err)
+	}
+	for _, title := range []string{"Draft", "Final"} {
+		if err := fs.SaveNote(context.Background(), storage.NewNote(title, "text")); err != nil {
+			t.Fatalf("could not save note: %v", err)
+		}
+	}
+	model := app.NewModel(app.WithStorage(fs))
+	model = drain(model, model.Init())
+
+	// Rename Draft to Final, whose file name is taken
+	id := ""
+	for i, note := range model.Notes() {
+		if note.Title == "Draft" {
+			id = note.ID
+			for range i {
+				model, _ = press(model, "j")
+			}
+		}
+	}
+	model, _ = press(model, "e")
+	model = pressKey(model, tea.KeyTab)
+	model = pressKey(model, tea.KeyCtrlU)
+	model, _ = press(model, "Final")
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	model = drain(updated.(app.Model), cmd)
+
+	assert.Equal(app.ModeList, model.Mode(), "saving should return to the list")
+	assert.Equal("", model.LastError(), "the rename should succeed")
+	note, err := fs.GetNote(context.Background(), id)
+	assert.NoError(err, "the note should keep its ID")
+	assert.Equal("final-2.md", filepath.Base(note.FilePath), "the file should take the next free name")
+	_, err = os.Stat(filepath.Join(dir, "draft.md"))
+	assert.True(os.IsNotExist(err), "the old file should be gone")
+}
@@ -0,0 +1,116 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestFoldersMode(t *testing.T) {
+	newModel := func(t *testing.T) (app.Model, *storage.LocalFileSystem) {
+		fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+		if err != nil {
+			t.Fatalf("could not create storage: %v", err)
+		}
+		plan := storage.NewNote("Plan", "")
+		plan.Folder = "work"
+		for _, note := range []*storage.Note{storage.NewNote("Loose", ""), plan} {
+			if err := fs.SaveNote(context.Background(), note); err != nil {
+				t.Fatalf("could not save note: %v", err)
+			}
+		}
+		model := app.NewModel(app.WithStorage(fs))
+		return drain(model, model.Init()), fs
+	}
+
+	t.Run("enter should scope the list to a folder", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := newModel(t)
+		assert.Len(model.Notes(), 2, "every note should be listed at first")
+
+		model, _ = press(model, "f")
+		assert.Equal(app.ModeFolders, model.Mode(), "f should open the folder tree")
+		assert.Contains(model.View(), "All notes", "the tree should start at the root")
+		assert.Contains(model.View(), "work", "folders should be listed")
+
+		model, cmd := press(model, "j", "enter")
+		model = drain(model, cmd)
+		assert.Equal(app.ModeList, model.Mode(), "enter should return to the list")
+		assert.Equal("work", model.FolderFilter(), "the folder should be selected")
+		assert.Len(model.Notes(), 1, "only the notes of the folder should be listed")
+		assert.Equal("Plan", model.Notes()[0].Title, "the note of the folder should be listed")
+
+		model, cmd = press(model, "f", "k", "enter")
+		model = drain(model, cmd)
+		assert.Equal("", model.FolderFilter(), "the root should show every note")
+		assert.Len(model.Notes(), 2, "every note should be listed again")
+	})
+
+	t.Run("n should create a folder", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := newModel(t)
+
+		model, _ = press(model, "f", "n", "archive")
+		model, cmd := press(model, "enter")
+		model = drain(model, cmd)
+
+		folders, err := fs.ListFolders(context.Background())
+		assert.NoError(err, "ListFolders should succeed")
+		assert.Equal([]string{"archive", "work"}, folders, "the folder should be created")
+		assert.Contains(model.View(), "archive", "the new folder should be in the tree")
+	})
+
+	t.Run("r should rename a folder and keep it shown", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := newModel(t)
+
+		model, cmd := press(model, "f", "j", "enter")
+		model = drain(model, cmd)
+		model, _ = press(model, "f", "r", "s")
+		model, cmd = press(model, "enter")
+		model = drain(model, cmd)
+
+		assert.Equal("works", model.FolderFilter(), "the list should follow the renamed folder")
+		assert.Len(model.Notes(), 1, "the notes should follow their folder")
+		assert.Equal("works", model.Notes()[0].Folder, "the note should be in the renamed folder")
+	})
+
+	t.Run("M should move the selected note", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := newModel(t)
+
+		var loose *storage.Note
+		for i, note := range model.Notes() {
+			if note.Title == "Loose" {
+				loose = note
+				for range i {
+					model, _ = press(model, "j")
+				}
+			}
+		}
+
+		model, _ = press(model, "M")
+		assert.Equal(app.ModeFolders, model.Mode(), "M should ask for the destination")
+		assert.Contains(model.View(), "Move 'Loose' to", "the note moved should be shown")
+
+		model, cmd := press(model, "j", "enter")
+		model = drain(model, cmd)
+		assert.Equal(app.ModeList, model.Mode(), "the list should be back")
+
+		moved, err := fs.GetNote(context.Background(), loose.ID)
+		assert.NoError(err, "the note should still exist")
+		assert.Equal("work", moved.Folder, "the note should be in the folder picked")
+	})
+
+	t.Run("storages without folders should report it", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := app.NewModel(app.WithStorage(&mockFileSystem{}))
+
+		model, _ = press(model, "f")
+		assert.Equal(app.ModeList, model.Mode(), "the list should stay")
+		assert.Contains(model.LastError(), "no folders", "the reason should be shown")
+	})
+}
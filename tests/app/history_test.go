@@ -0,0 +1,78 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestHistoryMode(t *testing.T) {
+	newModel := func(t *testing.T) (app.Model, *storage.LocalFileSystem) {
+		fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+		if err != nil {
+			t.Fatalf("could not create storage: %v", err)
+		}
+		note := storage.NewNote("Recipe", "flour\nsugar")
+		for _, content := range []string{"flour\nsugar", "flour\nhoney"} {
+			note.Content = content
+			if err := fs.SaveNote(context.Background(), note); err != nil {
+				t.Fatalf("could not save note: %v", err)
+			}
+		}
+		model := app.NewModel(app.WithStorage(fs))
+		model = drain(model, model.Init())
+
+		model, _ = press(model, "r")
+		model, cmd := press(model, "H")
+		return drain(model, cmd), fs
+	}
+
+	t.Run("H should show the changes of each revision", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := newModel(t)
+
+		assert.Equal(app.ModeHistory, model.Mode(), "H should open the history")
+		view := model.View()
+		assert.Contains(view, "(latest)", "the revisions should be listed")
+		assert.Contains(view, "- sugar", "the removed line should be shown")
+		assert.Contains(view, "+ honey", "the added line should be shown")
+
+		model, _ = press(model, "s")
+		assert.Contains(model.View(), "sugar", "the side-by-side diff should show the old line")
+
+		model, _ = press(model, "esc")
+		assert.Equal(app.ModeView, model.Mode(), "esc should return to the note")
+	})
+
+	t.Run("r should save the selected revision", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := newModel(t)
+		id := model.CurrentNote().ID
+
+		model, _ = press(model, "j")
+		model, cmd := press(model, "r")
+		model = drain(model, cmd)
+
+		assert.Equal(app.ModeView, model.Mode(), "restoring should show the note")
+		note, err := fs.GetNote(context.Background(), id)
+		assert.NoError(err, "the note should load")
+		assert.Equal("flour\nsugar", note.Content, "the old content should be saved")
+
+		versions, err := fs.ListVersions(context.Background(), id)
+		assert.NoError(err, "the versions should load")
+		assert.Len(versions, 3, "the restore should be a new version")
+	})
+
+	t.Run("should refuse storages without history", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := app.NewModel(app.WithStorage(&mockFileSystem{notes: []*storage.Note{storage.NewNote("Plain", "")}}))
+		model = drain(model, model.Init())
+
+		model, _ = press(model, "r", "H")
+		assert.Equal(app.ModeView, model.Mode(), "the note should stay open")
+		assert.Contains(model.View(), "no history", "the user should be told why")
+	})
+}
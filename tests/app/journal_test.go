@@ -0,0 +1,109 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+// journalModel returns a model over an empty vault
+func journalModel(t *testing.T) (app.Model, *storage.LocalFileSystem) {
+	t.Helper()
+
+	fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create storage: %v", err)
+	}
+	model := app.NewModel(app.WithStorage(fs), app.WithJournal(storage.Journal{Folder: "journal"}))
+	return drain(model, model.Init()), fs
+}
+
+// pressAndDrain presses keys and runs the commands of the last one
+func pressAndDrain(model app.Model, keys ...string) app.Model {
+	model, cmd := press(model, keys...)
+	return drain(model, cmd)
+}
+
+func TestJournal(t *testing.T) {
+	today := time.Now()
+
+	t.Run("should open today's entry and move between entries", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := journalModel(t)
+		for _, delta := range []int{-3, 2} {
+			entry := storage.NewNote("Entry", "text")
+			entry.ID = storage.JournalID(today.AddDate(0, 0, delta))
+			if err := fs.SaveNote(context.Background(), entry); err != nil {
+				t.Fatalf("could not save note: %v", err)
+			}
+		}
+
+		model = pressAndDrain(model, "J")
+
+		assert.Equal(app.ModeView, model.Mode(), "the entry should be shown")
+		assert.Equal(storage.JournalID(today), model.CurrentNote().ID, "today's entry should be opened")
+		assert.Equal("journal", model.CurrentNote().Folder, "the entry should go to the journal folder")
+		assert.Contains(model.View(), "</> (previous/next entry)", "entry keys should be offered")
+
+		model = pressAndDrain(model, ">")
+		assert.Equal(storage.JournalID(today.AddDate(0, 0, 2)), model.CurrentNote().ID, "> should skip to the next entry")
+
+		model = pressAndDrain(model, ">")
+		assert.Equal(storage.JournalID(today.AddDate(0, 0, 2)), model.CurrentNote().ID, "the last entry should stay open")
+		assert.Contains(model.LastError(), "no journal entry after", "the user should be told why")
+
+		model = pressAndDrain(model, "<")
+		model = pressAndDrain(model, "<")
+		assert.Equal(storage.JournalID(today.AddDate(0, 0, -3)), model.CurrentNote().ID, "< should skip to the previous entry")
+
+		notes, err := fs.ListNotes(context.Background())
+		assert.NoError(err, "notes should list")
+		assert.Len(notes, 3, "moving between entries should not create any")
+	})
+
+	t.Run("should not move from a regular note", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := journalModel(t)
+		if err := fs.SaveNote(context.Background(), storage.NewNote("Ideas", "text")); err != nil {
+			t.Fatalf("could not save note: %v", err)
+		}
+		model = drain(model, model.Init())
+
+		model = pressAndDrain(model, "r", "<")
+
+		assert.Equal("Ideas", model.CurrentNote().Title, "the note should stay open")
+		assert.Contains(model.LastError(), "not a journal entry", "the user should be told why")
+	})
+
+	t.Run("should browse the calendar", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := journalModel(t)
+		entry := storage.NewNote("Release day", "Shipped")
+		entry.ID = storage.JournalID(today)
+		if err := fs.SaveNote(context.Background(), entry); err != nil {
+			t.Fatalf("could not save note: %v", err)
+		}
+
+		model = pressAndDrain(model, "c")
+
+		assert.Equal(app.ModeCalendar, model.Mode(), "c should open the calendar")
+		assert.Contains(model.View(), today.Format("January 2006"), "the current month should be shown")
+		assert.Contains(model.View(), "•", "the day with an entry should be marked")
+		assert.Contains(model.View(), ": Release day", "the entry of the selected day should be named")
+
+		model, _ = press(model, "l")
+		assert.Equal(storage.JournalID(today.AddDate(0, 0, 1)), storage.JournalID(model.CalendarDay()), "l should select the next day")
+		model, _ = press(model, "j")
+		assert.Equal(storage.JournalID(today.AddDate(0, 0, 8)), storage.JournalID(model.CalendarDay()), "j should select the next week")
+		model, _ = press(model, "k", "h")
+		assert.Equal(storage.JournalID(today), storage.JournalID(model.CalendarDay()), "k and h should move back")
+
+		model = pressAndDrain(model, "enter")
+		assert.Equal(app.ModeView, model.Mode(), "enter should open the entry")
+		assert.Equal("Shipped", model.CurrentNote().Content, "the existing entry should be opened")
+	})
+}
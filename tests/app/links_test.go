@@ -0,0 +1,108 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLinks(t *testing.T) {
+	newModel := func(t *testing.T) app.Model {
+		fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+		if err != nil {
+			t.Fatalf("could not create storage: %v", err)
+		}
+		home := storage.NewNote("Home", "start at [[Plan]] or [[Trip|the trip]], not [[Nowhere]]")
+		plan := storage.NewNote("Plan", "back [[home]]")
+		trip := storage.NewNote("Trip", "no links")
+		for _, note := range []*storage.Note{home, plan, trip} {
+			if err := fs.SaveNote(context.Background(), note); err != nil {
+				t.Fatalf("could not save note: %v", err)
+			}
+		}
+		model := app.NewModel(app.WithStorage(fs))
+		model = drain(model, model.Init())
+
+		// Open Home from the list
+		for i, note := range model.Notes() {
+			if note.ID == home.ID {
+				for range i {
+					model, _ = press(model, "j")
+				}
+			}
+		}
+		model, _ = press(model, "r")
+		return model
+	}
+
+	t.Run("tab and enter should follow the selected link", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := newModel(t)
+		assert.Equal("Home", model.CurrentNote().Title, "Home should be viewed")
+
+		model = pressKey(model, tea.KeyTab)
+		model = pressKey(model, tea.KeyTab)
+		assert.Contains(model.View(), "🔗 the trip 2/3", "the selected link should be shown")
+
+		model, cmd := press(model, "enter")
+		model = drain(model, cmd)
+		assert.Equal(app.ModeView, model.Mode(), "the linked note should be viewed")
+		assert.Equal("Trip", model.CurrentNote().Title, "the link should lead to Trip")
+	})
+
+	t.Run("a broken link should show an error", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := newModel(t)
+
+		model = pressKey(model, tea.KeyShiftTab)
+		model, cmd := press(model, "enter")
+		model = drain(model, cmd)
+		assert.Equal("Home", model.CurrentNote().Title, "the note should stay open")
+		assert.Contains(model.LastError(), `no note named "Nowhere"`, "the broken link should be named")
+	})
+
+	t.Run("[ and ] should go back and forward", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := newModel(t)
+
+		model = pressKey(model, tea.KeyTab)
+		model, cmd := press(model, "enter")
+		model = drain(model, cmd)
+		assert.Equal("Plan", model.CurrentNote().Title, "the link should lead to Plan")
+
+		model, cmd = press(model, "[")
+		model = drain(model, cmd)
+		assert.Equal("Home", model.CurrentNote().Title, "[ should go back")
+
+		model, cmd = press(model, "[")
+		model = drain(model, cmd)
+		assert.Equal("Home", model.CurrentNote().Title, "there is nothing further back")
+
+		model, cmd = press(model, "]")
+		model = drain(model, cmd)
+		assert.Equal("Plan", model.CurrentNote().Title, "] should go forward")
+	})
+
+	t.Run("B should list the backlinks", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := newModel(t)
+
+		model, cmd := press(model, "B")
+		model = drain(model, cmd)
+		assert.Equal(app.ModeBacklinks, model.Mode(), "B should open the backlinks")
+		assert.Len(model.Backlinks(), 1, "only Plan links to Home")
+		assert.Contains(model.View(), "Plan", "the linking note should be listed")
+
+		model, _ = press(model, "enter")
+		assert.Equal(app.ModeView, model.Mode(), "enter should open the linking note")
+		assert.Equal("Plan", model.CurrentNote().Title, "Plan should be viewed")
+
+		model, cmd = press(model, "[")
+		model = drain(model, cmd)
+		assert.Equal("Home", model.CurrentNote().Title, "[ should return to the linked note")
+	})
+}
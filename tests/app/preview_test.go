@@ -0,0 +1,70 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSplitPane(t *testing.T) {
+	now := time.Now()
+	fs := &mockFileSystem{notes: []*storage.Note{
+		{ID: "1", Title: "Shopping list with a title long enough to be cut in the list pane", Content: "Buy **apples**", UpdatedAt: now},
+		{ID: "2", Title: "Ideas", Content: "Write a *novel*", UpdatedAt: now.Add(-time.Minute)},
+	}}
+
+	sized := func(width, height int) app.Model {
+		model := app.NewModel(app.WithStorage(fs))
+		model = drain(model, model.Init())
+		updated, _ := model.Update(tea.WindowSizeMsg{Width: width, Height: height})
+		return updated.(app.Model)
+	}
+
+	t.Run("should preview the selected note on wide terminals", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := sized(120, 20)
+
+		view := model.View()
+		assert.Contains(view, "apples", "preview should show the selected note")
+		assert.False(strings.Contains(view, "**apples**"), "preview should be rendered")
+		assert.False(strings.Contains(view, "novel"), "other notes should not be previewed")
+	})
+
+	t.Run("should follow the selection", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := sized(120, 20)
+
+		model, _ = press(model, "j")
+		view := model.View()
+		assert.Contains(view, "novel", "preview should show the new selection")
+		assert.False(strings.Contains(view, "apples"), "previous note should be gone")
+	})
+
+	t.Run("should fit the terminal", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := sized(100, 20)
+
+		for _, line := range strings.Split(model.View(), "\n") {
+			if strings.HasPrefix(line, "Shortcuts:") {
+				continue // The shortcuts line is not cut
+			}
+			assert.True(lipgloss.Width(line) <= 100, "lines should fit the width: "+line)
+		}
+		assert.True(strings.Count(model.View(), "\n") < 20, "view should fit the height")
+	})
+
+	t.Run("should fall back to a single pane on narrow terminals", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := sized(60, 20)
+
+		view := model.View()
+		assert.Contains(view, "Ideas", "list should be shown")
+		assert.False(strings.Contains(view, "apples"), "preview should be hidden")
+	})
+}
@@ -0,0 +1,75 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openQuickOpen loads the notes of fs and presses ctrl+p
+func openQuickOpen(fs storage.FileSystem) app.Model {
+	model := app.NewModel(app.WithStorage(fs))
+	model = drain(model, model.Init())
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	return updated.(app.Model)
+}
+
+func TestQuickOpen(t *testing.T) {
+	fs := &mockFileSystem{notes: []*storage.Note{
+		{ID: "a1", Title: "Weekly review"},
+		{ID: "b2", Title: "Meeting notes"},
+		{ID: "c3", Title: "Grocery list"},
+	}}
+
+	t.Run("ctrl+p should open the overlay", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := openQuickOpen(fs)
+
+		assert.Equal(app.ModeQuickOpen, model.Mode(), "should enter quick open mode")
+		assert.Contains(model.View(), "Meeting notes", "all notes should be listed before typing")
+	})
+
+	t.Run("enter should open the best fuzzy match", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := openQuickOpen(fs)
+
+		model, _ = press(model, "m", "t", "g", "enter")
+
+		assert.Equal(app.ModeView, model.Mode(), "should view the note")
+		assert.NotNil(model.CurrentNote(), "a note should be open")
+		assert.Equal("Meeting notes", model.CurrentNote().Title, "should open the matching note")
+	})
+
+	t.Run("should match note IDs", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := openQuickOpen(fs)
+
+		model, _ = press(model, "c", "3", "enter")
+
+		assert.NotNil(model.CurrentNote(), "a note should be open")
+		assert.Equal("c3", model.CurrentNote().ID, "should open the note with that ID")
+	})
+
+	t.Run("enter without matches should do nothing", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := openQuickOpen(fs)
+
+		model, _ = press(model, "x", "y", "z", "enter")
+
+		assert.Equal(app.ModeQuickOpen, model.Mode(), "should stay in quick open")
+		assert.Contains(model.View(), "No matching notes", "should say nothing matches")
+	})
+
+	t.Run("esc should return to the list", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := openQuickOpen(fs)
+
+		model, _ = press(model, "esc")
+
+		assert.Equal(app.ModeList, model.Mode(), "should return to the list")
+	})
+}
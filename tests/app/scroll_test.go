@@ -0,0 +1,158 @@
+package app_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// manyNotesModel loads n notes, newest first ("Note 001" on top), in a 80x20 window
+func manyNotesModel(n int) app.Model {
+	now := time.Now()
+	notes := make([]*storage.Note, n)
+	for i := range notes {
+		notes[i] = &storage.Note{
+			ID:        fmt.Sprintf("%03d", i+1),
+			Title:     fmt.Sprintf("Note %03d", i+1),
+			UpdatedAt: now.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+
+	model := app.NewModel(app.WithStorage(&mockFileSystem{notes: notes}))
+	model = drain(model, model.Init())
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	return updated.(app.Model)
+}
+
+// screenHeight returns how many lines view takes on a terminal width columns wide
+func screenHeight(view string, width int) int {
+	return lipgloss.Height(ansi.Hardwrap(view, width, true))
+}
+
+// pressKey sends a special key such as tea.KeyPgDown
+func pressKey(model app.Model, key tea.KeyType) app.Model {
+	updated, _ := model.Update(tea.KeyMsg{Type: key})
+	return updated.(app.Model)
+}
+
+func TestListScrolling(t *testing.T) {
+	t.Run("should only render the notes that fit", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := manyNotesModel(200)
+
+		view := model.View()
+		assert.Contains(view, "Note 001", "first note should be visible")
+		assert.False(strings.Contains(view, "Note 200"), "last note should be off screen")
+		assert.True(strings.Count(view, "\n") < 20, "list should fit the window")
+		assert.Contains(view, "1/200", "should show the position")
+	})
+
+	t.Run("should fit the window with its footer wrapped", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := manyNotesModel(200)
+
+		for i := 0; i < 30; i++ {
+			model, _ = press(model, "j")
+			assert.True(screenHeight(model.View(), 80) <= 20, "the view should fit the window")
+		}
+		model, _ = press(model, "d")
+		assert.True(screenHeight(model.View(), 80) <= 20, "the view should fit the window with a confirmation")
+		assert.Contains(model.View(), "> Note 031", "the selection should stay visible")
+	})
+
+	t.Run("G and g should jump to the bottom and top", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := manyNotesModel(200)
+
+		model, _ = press(model, "G")
+		assert.Contains(model.View(), "> Note 200", "last note should be selected and visible")
+		assert.Contains(model.View(), "200/200", "position should follow")
+
+		model, _ = press(model, "g")
+		assert.Contains(model.View(), "> Note 001", "first note should be selected and visible")
+	})
+
+	t.Run("page down should keep the selection visible", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := manyNotesModel(200)
+
+		model = pressKey(model, tea.KeyPgDown)
+		model = pressKey(model, tea.KeyPgDown)
+		view := model.View()
+		assert.True(strings.Contains(view, "> Note"), "selection should be on screen")
+		assert.False(strings.Contains(view, "Note 001"), "first note should have scrolled away")
+
+		model = pressKey(model, tea.KeyPgUp)
+		model = pressKey(model, tea.KeyPgUp)
+		assert.Contains(model.View(), "> Note 001", "should be back at the top")
+	})
+
+	t.Run("half page should move by half the window", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := manyNotesModel(200)
+
+		model = pressKey(model, tea.KeyCtrlD)
+		assert.False(strings.Contains(model.View(), "> Note 001"), "selection should move down")
+
+		model = pressKey(model, tea.KeyCtrlU)
+		assert.Contains(model.View(), "> Note 001", "selection should move back up")
+	})
+
+	t.Run("j past the window should scroll", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := manyNotesModel(200)
+
+		for i := 0; i < 30; i++ {
+			model, _ = press(model, "j")
+		}
+		view := model.View()
+		assert.Contains(view, "> Note 031", "selection should be visible")
+		assert.Contains(view, "31/200", "position should follow")
+	})
+}
+
+func TestViewerPagination(t *testing.T) {
+	lines := make([]string, 300)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	fs := &mockFileSystem{notes: []*storage.Note{{ID: "1", Title: "Long", Content: strings.Join(lines, "\n")}}}
+
+	model := app.NewModel(app.WithStorage(fs))
+	model = drain(model, model.Init())
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	model = updated.(app.Model)
+	model, _ = press(model, "r", "m")
+
+	t.Run("should show the position in the note", func(t *testing.T) {
+		assert := testutil.New(t)
+		assert.Contains(model.View(), "13/300", "should show the last visible line")
+		assert.True(screenHeight(model.View(), 80) <= 20, "the shortcuts should wrap within the window")
+	})
+
+	t.Run("G and g should jump to the end and the start", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		bottom, _ := press(model, "G")
+		assert.Contains(bottom.View(), "line 300", "end should be visible")
+		assert.Contains(bottom.View(), "300/300", "position should be at the end")
+
+		top, _ := press(bottom, "g")
+		assert.Contains(top.View(), "13/300", "should be back at the start")
+	})
+
+	t.Run("page down should scroll a page", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		paged := pressKey(model, tea.KeyPgDown)
+		assert.Contains(paged.View(), "26/300", "should scroll one page")
+	})
+}
@@ -0,0 +1,224 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchFileSystem is a mock FileSystem whose SearchNotes filters titles
+type searchFileSystem struct {
+	mockFileSystem
+	queries []string
+}
+
+func (s *searchFileSystem) SearchNotes(ctx context.Context, query string) ([]*storage.Note, error) {
+	s.queries = append(s.queries, query)
+
+	var results []*storage.Note
+	for _, note := range s.notes {
+		if strings.Contains(strings.ToLower(note.Title), strings.ToLower(query)) {
+			results = append(results, note)
+		}
+	}
+	return results, nil
+}
+
+// drain runs cmd and feeds every resulting message back into the model
+func drain(model app.Model, cmd tea.Cmd) app.Model {
+	if cmd == nil {
+		return model
+	}
+
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			model = drain(model, c)
+		}
+		return model
+	}
+	if msg == nil {
+		return model
+	}
+
+	updated, next := model.Update(msg)
+	return drain(updated.(app.Model), next)
+}
+
+func TestSearchMode(t *testing.T) {
+	notes := []*storage.Note{
+		{ID: "1", Title: "Go Tutorial", Content: "Learn Go"},
+		{ID: "2", Title: "Python Tips", Content: "Snakes"},
+		{ID: "3", Title: "Go Modules", Content: "go.mod files"},
+	}
+
+	t.Run("should search as the user types", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &searchFileSystem{mockFileSystem: mockFileSystem{notes: notes}}
+		model := app.NewModel(app.WithStorage(fs))
+
+		model, _ = press(model, "/")
+		assert.Equal(app.ModeSearch, model.Mode(), "/ should enter search mode")
+
+		model, cmd := press(model, "g", "o")
+		model = drain(model, cmd)
+
+		assert.Len(model.SearchResults(), 2, "two notes match 'go'")
+		assert.Equal([]string{"go"}, fs.queries, "intermediate queries should be debounced")
+	})
+
+	t.Run("should ignore stale results", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &searchFileSystem{mockFileSystem: mockFileSystem{notes: notes}}
+		model := app.NewModel(app.WithStorage(fs))
+
+		model, _ = press(model, "/", "g", "o")
+
+		// Results of an older query arrive after the query changed
+		updated, _ := model.Update(app.SearchResultsMsg{Seq: 1, Query: "g", Notes: notes})
+		model = updated.(app.Model)
+
+		assert.Empty(model.SearchResults(), "results for a superseded query should be dropped")
+	})
+
+	t.Run("should navigate results and open the selected note", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &searchFileSystem{mockFileSystem: mockFileSystem{notes: notes}}
+		model := app.NewModel(app.WithStorage(fs))
+
+		model, cmd := press(model, "/", "g", "o")
+		model = drain(model, cmd)
+
+		// Arrow keys move while typing, j/k once the results have focus
+		model, _ = press(model, "down")
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyTab})
+		model = updated.(app.Model)
+		model, _ = press(model, "k", "j")
+
+		model, _ = press(model, "enter")
+		assert.Equal(app.ModeView, model.Mode(), "enter should open the note")
+		assert.Equal("Go Modules", model.CurrentNote().Title, "the selected result should be opened")
+
+		model, _ = press(model, "esc")
+		assert.Equal(app.ModeSearch, model.Mode(), "esc should return to the results")
+		assert.Len(model.SearchResults(), 2, "results should be kept")
+	})
+
+	t.Run("should highlight matches in the view", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &searchFileSystem{mockFileSystem: mockFileSystem{notes: notes}}
+		model := app.NewModel(app.WithStorage(fs))
+
+		model, cmd := press(model, "/", "p", "y")
+		model = drain(model, cmd)
+
+		view := model.View()
+		assert.Contains(view, "1 result(s)", "result count should be shown")
+		assert.Contains(view, "thon Tips", "matching note should be listed")
+	})
+}
+
+// failingSearchFileSystem is a mock FileSystem whose SearchNotes parses the query
+type failingSearchFileSystem struct {
+	mockFileSystem
+}
+
+func (f *failingSearchFileSystem) SearchNotes(ctx context.Context, query string) ([]*storage.Note, error) {
+	if _, err := storage.ParseQuery(query); err != nil {
+		return nil, err
+	}
+	return f.notes, nil
+}
+
+func TestSearchMode_ParseError(t *testing.T) {
+	t.Run("should point at the problem in the query", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &failingSearchFileSystem{mockFileSystem{notes: []*storage.Note{{ID: "1", Title: "Kept"}}}}
+		model := app.NewModel(app.WithStorage(fs))
+
+		model, cmd := press(model, "/", "k")
+		model = drain(model, cmd)
+		assert.Len(model.SearchResults(), 1, "valid query should return results")
+
+		model, cmd = press(model, " ", "t", "a", "g", ":")
+		model = drain(model, cmd)
+
+		assert.NotNil(model.QueryError(), "parse error should be stored")
+		assert.Equal(6, model.QueryError().Column(), "error should point after tag:")
+		assert.Len(model.SearchResults(), 1, "previous results should be kept")
+		assert.Contains(model.View(), "k tag:\n         ^", "caret should be rendered under the query")
+
+		model, cmd = press(model, "x")
+		model = drain(model, cmd)
+		assert.Nil(model.QueryError(), "error should clear once the query is valid")
+	})
+
+	t.Run("should not show the query error on the opened note", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &failingSearchFileSystem{mockFileSystem{notes: []*storage.Note{{ID: "1", Title: "Kept"}}}}
+		model := app.NewModel(app.WithStorage(fs))
+
+		model, cmd := press(model, "/", "k")
+		model = drain(model, cmd)
+		model, cmd = press(model, " ", "t", "a", "g", ":")
+		model = drain(model, cmd)
+		assert.NotNil(model.QueryError(), "parse error should be stored")
+
+		model, _ = press(model, "enter")
+
+		assert.Equal(app.ModeView, model.Mode(), "enter should open the kept result")
+		assert.Nil(model.QueryError(), "error should clear when leaving the search")
+		assert.False(strings.Contains(model.View(), "Error"), "the note should not show the query error")
+	})
+}
+
+func TestSearchScrolling(t *testing.T) {
+	// searchModel shows 200 results for "note" in a 80x20 window
+	searchModel := func() app.Model {
+		notes := make([]*storage.Note, 200)
+		for i := range notes {
+			notes[i] = &storage.Note{
+				ID:      fmt.Sprintf("%03d", i+1),
+				Title:   fmt.Sprintf("Note %03d", i+1),
+				Content: "Some text",
+			}
+		}
+		model := app.NewModel(app.WithStorage(&searchFileSystem{mockFileSystem: mockFileSystem{notes: notes}}))
+		updated, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+		model, cmd := press(updated.(app.Model), "/", "n", "o", "t", "e")
+		return drain(model, cmd)
+	}
+
+	t.Run("should only render the results that fit", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := searchModel()
+
+		view := model.View()
+		assert.Len(model.SearchResults(), 200, "every note should match")
+		assert.Contains(view, "Note 001", "first result should be visible")
+		assert.False(strings.Contains(view, "Note 200"), "last result should be off screen")
+		assert.True(screenHeight(view, 80) <= 20, "results should fit the window")
+		assert.Contains(view, "1/200", "should show the position")
+	})
+
+	t.Run("should follow the selection", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := searchModel()
+
+		for range 50 {
+			model = pressKey(model, tea.KeyDown)
+		}
+
+		view := model.View()
+		assert.Contains(view, "> Note 051", "selected result should be visible")
+		assert.False(strings.Contains(view, "Note 001"), "first result should have scrolled off")
+		assert.True(screenHeight(view, 80) <= 20, "results should fit the window")
+		assert.Contains(view, "51/200", "should show the position")
+	})
+}
@@ -0,0 +1,85 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestTagsMode(t *testing.T) {
+	newModel := func(t *testing.T) (app.Model, *storage.LocalFileSystem) {
+		fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+		if err != nil {
+			t.Fatalf("could not create storage: %v", err)
+		}
+		for _, note := range []*storage.Note{
+			storage.NewNote("Plan", "for #work"),
+			storage.NewNote("Trip", "a #holiday idea"),
+			storage.NewNote("Review", "more #work"),
+		} {
+			if err := fs.SaveNote(context.Background(), note); err != nil {
+				t.Fatalf("could not save note: %v", err)
+			}
+		}
+		model := app.NewModel(app.WithStorage(fs))
+		return drain(model, model.Init()), fs
+	}
+
+	t.Run("enter should limit the list to a tag", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := newModel(t)
+
+		model, cmd := press(model, "#")
+		model = drain(model, cmd)
+		assert.Equal(app.ModeTags, model.Mode(), "# should open the tags")
+		assert.Contains(model.View(), "#holiday", "tags should be listed")
+		assert.Contains(model.View(), "(2)", "counts should be shown")
+
+		// Tags are sorted by name: holiday, work
+		model, cmd = press(model, "j", "j", "enter")
+		model = drain(model, cmd)
+		assert.Equal(app.ModeList, model.Mode(), "enter should return to the list")
+		assert.Equal("work", model.TagFilter(), "the tag should be selected")
+		assert.Len(model.Notes(), 2, "only the tagged notes should be listed")
+
+		model, cmd = press(model, "#", "k", "k", "enter")
+		model = drain(model, cmd)
+		assert.Equal("", model.TagFilter(), "the first row should show every note")
+		assert.Len(model.Notes(), 3, "every note should be listed again")
+	})
+
+	t.Run("+ and - should tag the marked notes", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := newModel(t)
+
+		model, _ = press(model, " ", " ")
+		assert.Contains(model.View(), "● ", "marked notes should be shown")
+		marked := []string{model.Notes()[0].ID, model.Notes()[1].ID}
+
+		model, _ = press(model, "+", "urgent")
+		assert.Contains(model.View(), "Add tag to 2 notes", "the prompt should count the notes")
+		model, cmd := press(model, "enter")
+		model = drain(model, cmd)
+
+		for _, id := range marked {
+			note, err := fs.GetNote(context.Background(), id)
+			assert.NoError(err, "the note should exist")
+			assert.True(note.HasTag("urgent"), "the marked notes should be tagged")
+		}
+		assert.False(strings.Contains(model.View(), "● "), "marks should be cleared")
+
+		// Without marks the selected note is the target
+		model, _ = press(model, "g", "-", "urgent")
+		selected := model.Notes()[0].ID
+		model, cmd = press(model, "enter")
+		drain(model, cmd)
+		tagged, err := fs.NotesByTag(context.Background(), "urgent")
+		assert.NoError(err, "NotesByTag should succeed")
+		assert.Len(tagged, 1, "only the selected note should be untagged")
+		assert.True(tagged[0].ID != selected, "the selected note should lose the tag")
+	})
+}
@@ -0,0 +1,95 @@
+package app_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// templateModel returns a model over a vault with a meeting template
+func templateModel(t *testing.T) (app.Model, *storage.LocalFileSystem) {
+	t.Helper()
+
+	fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create storage: %v", err)
+	}
+	dir := filepath.Join(fs.NotesDir(), ".templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("could not create templates folder: %v", err)
+	}
+	content := "---\ntitle: Meeting\ntags: [meeting]\n---\n# {{.Title}}\nWith: {{prompt \"Attendees\"}}\n\n- {{cursor}}\n\nNext steps\n"
+	if err := os.WriteFile(filepath.Join(dir, "meeting.md"), []byte(content), 0o644); err != nil {
+		t.Fatalf("could not write template: %v", err)
+	}
+
+	model := app.NewModel(app.WithStorage(fs))
+	return drain(model, model.Init()), fs
+}
+
+// saveCreated saves the note being created and returns the notes of the vault
+func saveCreated(t *testing.T, model app.Model, fs storage.FileSystem) (app.Model, []*storage.Note) {
+	t.Helper()
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	model = drain(updated.(app.Model), cmd)
+	notes, err := fs.ListNotes(context.Background())
+	if err != nil {
+		t.Fatalf("could not list notes: %v", err)
+	}
+	return model, notes
+}
+
+func TestCreateFromTemplate(t *testing.T) {
+	t.Run("should offer the templates before the title", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := templateModel(t)
+
+		model, _ = press(model, "n")
+
+		assert.Equal(app.ModeCreate, model.Mode(), "n should start creating a note")
+		assert.Contains(model.View(), "> Blank note", "a blank note should be selected first")
+		assert.Contains(model.View(), "meeting", "the template should be offered")
+	})
+
+	t.Run("should fill in the template", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := templateModel(t)
+
+		model, _ = press(model, "n", "j", "enter")
+		assert.Contains(model.View(), "Template: meeting", "the template should be shown")
+		assert.Contains(model.View(), "Meeting", "the template should suggest a title")
+
+		model, _ = press(model, " ", "S", "y", "n", "c", "enter")
+		assert.Contains(model.View(), "Attendees", "the template field should be prompted for")
+
+		model, _ = press(model, "A", "n", "a", "enter")
+		model, _ = press(model, "D", "o", "n", "e")
+		model, notes := saveCreated(t, model, fs)
+
+		assert.Equal(app.ModeList, model.Mode(), "saving should return to the list")
+		assert.Len(notes, 1, "the note should be saved")
+		assert.Equal("Meeting Sync", notes[0].Title, "the suggested title should be kept")
+		assert.Equal("# Meeting Sync\nWith: Ana\n\n- Done\n\nNext steps", notes[0].Content, "typing should start at the cursor")
+		assert.Equal([]string{"meeting"}, notes[0].Tags, "the template should tag the note")
+	})
+
+	t.Run("should create a blank note", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := templateModel(t)
+
+		model, _ = press(model, "n", "enter")
+		model, _ = press(model, "P", "l", "a", "i", "n", "enter", "x")
+		_, notes := saveCreated(t, model, fs)
+
+		assert.Len(notes, 1, "the note should be saved")
+		assert.Equal("Plain", notes[0].Title, "the title should be typed")
+		assert.Equal("x", notes[0].Content, "the note should start empty")
+	})
+}
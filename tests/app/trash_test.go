@@ -0,0 +1,70 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestTrashMode(t *testing.T) {
+	newModel := func(t *testing.T) (app.Model, *storage.LocalFileSystem) {
+		fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+		if err != nil {
+			t.Fatalf("could not create storage: %v", err)
+		}
+		for _, title := range []string{"Keep", "Drop"} {
+			if err := fs.SaveNote(context.Background(), storage.NewNote(title, "")); err != nil {
+				t.Fatalf("could not save note: %v", err)
+			}
+		}
+		model := app.NewModel(app.WithStorage(fs))
+		return drain(model, model.Init()), fs
+	}
+
+	t.Run("deleted notes should be restored from the trash", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := newModel(t)
+		deleted := model.Notes()[0].Title
+
+		model, cmd := press(model, "d", "d")
+		model = drain(model, cmd)
+		assert.Len(model.Notes(), 1, "the note should leave the list")
+
+		model, cmd = press(model, "T")
+		model = drain(model, cmd)
+		assert.Equal(app.ModeTrash, model.Mode(), "T should open the trash")
+		assert.Contains(model.View(), deleted, "the deleted note should be listed")
+
+		model, cmd = press(model, "r")
+		model = drain(model, cmd)
+		assert.Len(model.Notes(), 2, "the note should be back in the list")
+		assert.Contains(model.View(), "The trash is empty", "the trash should be empty")
+
+		model, _ = press(model, "esc")
+		assert.Equal(app.ModeList, model.Mode(), "esc should return to the list")
+	})
+
+	t.Run("x should purge after confirmation", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, fs := newModel(t)
+
+		model, cmd := press(model, "d", "d")
+		model = drain(model, cmd)
+		model, cmd = press(model, "T")
+		model = drain(model, cmd)
+
+		model, cmd = press(model, "x")
+		assert.Nil(cmd, "the first x should only ask for confirmation")
+		assert.Contains(model.View(), "for good", "the purge should be confirmed")
+
+		model, cmd = press(model, "x")
+		model = drain(model, cmd)
+		trashed, err := fs.ListTrash(context.Background())
+		assert.NoError(err, "the trash should load")
+		assert.Empty(trashed, "the note should be purged")
+		assert.Len(model.Notes(), 1, "the other note should stay")
+	})
+}
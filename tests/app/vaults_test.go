@@ -0,0 +1,108 @@
+package app_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/config"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyMsg builds a key press message for a key name such as "j", "enter" or "esc"
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	default:
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+}
+
+// press sends a sequence of key presses to the model and returns the last command
+func press(model app.Model, keys ...string) (app.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var updated tea.Model
+		updated, cmd = model.Update(keyMsg(key))
+		model = updated.(app.Model)
+	}
+	return model, cmd
+}
+
+func TestNewModel_WithStorage(t *testing.T) {
+	t.Run("should use the provided storage", func(t *testing.T) {
+		assert := testutil.New(t)
+		fs := &mockFileSystem{}
+
+		model := app.NewModel(app.WithStorage(fs))
+
+		assert.Equal(storage.FileSystem(fs), model.Storage(), "storage should be the one provided")
+		assert.Empty(model.LastError(), "should have no error")
+	})
+}
+
+func TestVaultSwitcher(t *testing.T) {
+	work := &mockFileSystem{notes: []*storage.Note{{ID: "w", Title: "Work note"}}}
+	personal := &mockFileSystem{notes: []*storage.Note{{ID: "p", Title: "Personal note"}}}
+
+	vaults := []config.Vault{
+		{Name: "work", Path: "/vaults/work"},
+		{Name: "personal", Path: "/vaults/personal"},
+	}
+	opener := func(path string) (storage.FileSystem, error) {
+		if path == "/vaults/personal" {
+			return personal, nil
+		}
+		return nil, errors.New("cannot open " + path)
+	}
+
+	t.Run("should rebuild storage and reload notes", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := app.NewModel(
+			app.WithStorage(work),
+			app.WithVaults(vaults, "work"),
+			app.WithStorageOpener(opener),
+		)
+
+		model, _ = press(model, "v")
+		assert.Equal(app.ModeVaults, model.Mode(), "v should open the vault switcher")
+
+		model, cmd := press(model, "j", "enter")
+		assert.NotNil(cmd, "enter should open the selected vault")
+
+		// Run the open command, then the reload it triggers
+		updated, cmd := model.Update(cmd())
+		model = updated.(app.Model)
+		assert.Equal(app.ModeList, model.Mode(), "should return to the list")
+		assert.Equal("personal", model.CurrentVault(), "current vault should change")
+		assert.Equal(storage.FileSystem(personal), model.Storage(), "storage should be rebuilt")
+		assert.NotNil(cmd, "notes should be reloaded")
+
+		updated, _ = model.Update(cmd())
+		model = updated.(app.Model)
+		assert.Len(model.Notes(), 1, "notes from the new vault should be loaded")
+		assert.Equal("Personal note", model.Notes()[0].Title, "note should come from the new vault")
+	})
+
+	t.Run("should keep the current vault when opening fails", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := app.NewModel(
+			app.WithStorage(personal),
+			app.WithVaults(vaults, "personal"),
+			app.WithStorageOpener(opener),
+		)
+
+		model, cmd := press(model, "v", "k", "enter")
+		updated, _ := model.Update(cmd())
+		model = updated.(app.Model)
+
+		assert.Equal("personal", model.CurrentVault(), "current vault should not change")
+		assert.Equal(storage.FileSystem(personal), model.Storage(), "storage should not change")
+		assert.Contains(model.LastError(), "cannot open", "error should be reported")
+	})
+}
@@ -0,0 +1,70 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestViewMode_Markdown(t *testing.T) {
+	fs := &mockFileSystem{notes: []*storage.Note{
+		{ID: "1", Title: "Recipe", Content: "## Steps\n\nStir **gently**\n\n```go\nfunc main() {}\n```"},
+	}}
+
+	open := func() app.Model {
+		model := app.NewModel(app.WithStorage(fs))
+		model = drain(model, model.Init())
+		model, _ = press(model, "r")
+		return model
+	}
+
+	t.Run("should render markdown by default", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := open()
+
+		view := model.View()
+		assert.Equal(app.ModeView, model.Mode(), "should view the note")
+		assert.Contains(view, "gently", "content should be shown")
+		assert.False(strings.Contains(view, "**gently**"), "emphasis markers should be rendered")
+		assert.False(strings.Contains(view, "```"), "code fences should be rendered")
+	})
+
+	t.Run("m should toggle the raw markdown", func(t *testing.T) {
+		assert := testutil.New(t)
+		model := open()
+
+		model, _ = press(model, "m")
+		assert.Contains(model.View(), "**gently**", "raw view should show the markdown source")
+
+		model, _ = press(model, "m")
+		assert.False(strings.Contains(model.View(), "**gently**"), "should render again")
+	})
+
+	t.Run("should scroll long notes within the window", func(t *testing.T) {
+		assert := testutil.New(t)
+		lines := make([]string, 100)
+		for i := range lines {
+			lines[i] = "line"
+		}
+		lines[99] = "the end"
+		long := &mockFileSystem{notes: []*storage.Note{{ID: "1", Title: "Long", Content: strings.Join(lines, "\n")}}}
+
+		model := app.NewModel(app.WithStorage(long))
+		model = drain(model, model.Init())
+		updated, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+		model = updated.(app.Model)
+		model, _ = press(model, "r", "m")
+
+		assert.False(strings.Contains(model.View(), "the end"), "the end should be out of view")
+		assert.True(strings.Count(model.View(), "\n") < 20, "view should fit the window")
+
+		for i := 0; i < 100; i++ {
+			model, _ = press(model, "j")
+		}
+		assert.Contains(model.View(), "the end", "scrolling should reach the end")
+	})
+}
@@ -0,0 +1,154 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/app"
+	"github.com/N95Ryan/leaf/internal/config"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/internal/watcher"
+	"github.com/N95Ryan/leaf/tests/testutil"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestWatchedChanges(t *testing.T) {
+	now := time.Now()
+	notes := func() []*storage.Note {
+		return []*storage.Note{
+			{ID: "a", Title: "Alpha", UpdatedAt: now},
+			{ID: "b", Title: "Beta", UpdatedAt: now.Add(-time.Minute)},
+			{ID: "c", Title: "Gamma", UpdatedAt: now.Add(-2 * time.Minute)},
+		}
+	}
+	loaded := func() app.Model {
+		model := app.NewModel(app.WithStorage(&mockFileSystem{notes: notes()}))
+		return drain(model, model.Init())
+	}
+	update := func(model app.Model, msg tea.Msg) app.Model {
+		updated, _ := model.Update(msg)
+		return updated.(app.Model)
+	}
+
+	t.Run("should update a changed note and keep the selection", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := press(loaded(), "j") // Beta
+
+		model = update(model, app.NoteChangedMsg{Note: &storage.Note{ID: "c", Title: "Gamma v2", UpdatedAt: now.Add(time.Minute)}})
+
+		assert.Len(model.Notes(), 3, "no note should be added")
+		assert.Equal("Gamma v2", model.Notes()[0].Title, "changed note should move to the top")
+		assert.Contains(model.View(), "> Beta", "selection should stay on Beta")
+	})
+
+	t.Run("should add new notes", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		model := update(loaded(), app.NoteChangedMsg{Note: &storage.Note{ID: "d", Title: "Delta", UpdatedAt: now.Add(-time.Hour)}})
+
+		assert.Len(model.Notes(), 4, "note should be added")
+		assert.Equal("Delta", model.Notes()[3].Title, "note should be sorted in")
+		assert.Contains(model.View(), "> Alpha", "selection should stay on Alpha")
+	})
+
+	t.Run("should drop removed notes", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := press(loaded(), "j", "j") // Gamma
+
+		model = update(model, app.NoteRemovedMsg{ID: "a"})
+
+		assert.Len(model.Notes(), 2, "note should be removed")
+		assert.Contains(model.View(), "> Gamma", "selection should stay on Gamma")
+	})
+
+	t.Run("should refresh the viewed note", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := press(loaded(), "r")
+
+		model = update(model, app.NoteChangedMsg{Note: &storage.Note{ID: "a", Title: "Alpha", Content: "synced text", UpdatedAt: now}})
+
+		assert.Equal("synced text", model.CurrentNote().Content, "viewed note should be reloaded")
+	})
+
+	t.Run("should leave the viewer when its note is removed", func(t *testing.T) {
+		assert := testutil.New(t)
+		model, _ := press(loaded(), "r")
+
+		model = update(model, app.NoteRemovedMsg{ID: "a"})
+
+		assert.Equal(app.ModeList, model.Mode(), "should return to the list")
+		assert.Contains(model.LastError(), "deleted", "should explain why")
+	})
+}
+
+// step runs cmd, or each command of a batch, and updates the model with what
+// they return, leaving the commands that follow to the test
+func step(model app.Model, cmd tea.Cmd) app.Model {
+	if cmd == nil {
+		return model
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			model = step(model, c)
+		}
+		return model
+	}
+	if msg == nil {
+		return model
+	}
+	updated, _ := model.Update(msg)
+	return updated.(app.Model)
+}
+
+func TestWithWatcher(t *testing.T) {
+	t.Run("should watch the active vault once started", func(t *testing.T) {
+		assert := testutil.New(t)
+		dir := t.TempDir()
+		watched := ""
+
+		model := app.NewModel(
+			app.WithStorage(&mockFileSystem{}),
+			app.WithVaults([]config.Vault{{Name: "main", Path: dir}}, "main"),
+			app.WithWatcher(func(path string) (*watcher.Watcher, error) {
+				watched = path
+				w := watcher.NewPoller(path, time.Hour)
+				t.Cleanup(func() { w.Close() })
+				return w, nil
+			}),
+		)
+		assert.Equal("", watched, "building the model should not start watching")
+
+		step(model, model.Init())
+
+		assert.Equal(dir, watched, "the vault directory should be watched")
+	})
+
+	t.Run("should watch the vault switched to", func(t *testing.T) {
+		assert := testutil.New(t)
+		work, personal := t.TempDir(), t.TempDir()
+		watchers := map[string]*watcher.Watcher{}
+
+		model := app.NewModel(
+			app.WithStorage(&mockFileSystem{}),
+			app.WithStorageOpener(func(string) (storage.FileSystem, error) { return &mockFileSystem{}, nil }),
+			app.WithVaults([]config.Vault{{Name: "work", Path: work}, {Name: "personal", Path: personal}}, "work"),
+			app.WithWatcher(func(path string) (*watcher.Watcher, error) {
+				w := watcher.NewPoller(path, time.Hour)
+				t.Cleanup(func() { w.Close() })
+				watchers[path] = w
+				return w, nil
+			}),
+		)
+		model = step(model, model.Init())
+
+		model, cmd := press(model, "v", "j", "enter")
+		updated, cmd := model.Update(cmd()) // The vault opened
+		model = step(updated.(app.Model), cmd)
+
+		_, open := <-watchers[work].Events()
+		assert.False(open, "the watcher of the vault left should be closed")
+		assert.NotNil(watchers[personal], "the vault switched to should be watched")
+		assert.Equal("personal", model.CurrentVault(), "the vault should be switched")
+	})
+}
@@ -0,0 +1,113 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/cli"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+// testEnv is a CLI environment over a temporary vault with captured output
+type testEnv struct {
+	*cli.Env
+	fs     *storage.LocalFileSystem
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+// newTestEnv creates a CLI environment over a temporary vault
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	return &testEnv{
+		Env: &cli.Env{
+			Storage: fs,
+			Stdin:   strings.NewReader(""),
+			Stdout:  stdout,
+			Stderr:  stderr,
+		},
+		fs:     fs,
+		stdout: stdout,
+		stderr: stderr,
+	}
+}
+
+// run executes a command, resetting the captured output first
+func (e *testEnv) run(args ...string) int {
+	e.stdout.Reset()
+	e.stderr.Reset()
+	return cli.Run(e.Env, args)
+}
+
+// save stores a note in the vault
+func (e *testEnv) save(t *testing.T, note *storage.Note) *storage.Note {
+	t.Helper()
+
+	if err := e.fs.SaveNote(context.Background(), note); err != nil {
+		t.Fatalf("SaveNote() failed: %v", err)
+	}
+	return note
+}
+
+func TestRun(t *testing.T) {
+	t.Run("should reject unknown commands", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		code := env.run("frobnicate")
+
+		assert.Equal(cli.ExitUsage, code, "unknown commands are usage errors")
+		assert.Contains(env.stderr.String(), "unknown command", "should explain the problem")
+	})
+
+	t.Run("should run known commands", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		code := env.run("list")
+
+		assert.Equal(cli.ExitOK, code, "list is a command")
+		assert.Empty(env.stderr.String(), "nothing should be reported")
+	})
+}
+
+func TestSearchCommand(t *testing.T) {
+	t.Run("should print matching notes", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Release notes", "Version 2"))
+		env.save(t, storage.NewNote("Groceries", "Milk"))
+
+		code := env.run("search", "title:release", "version")
+
+		assert.Equal(cli.ExitOK, code, "search should succeed")
+		assert.Equal(note.ID+"\tRelease notes\n", env.stdout.String(), "should print id and title")
+	})
+
+	t.Run("should point at query errors", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		code := env.run("search", "foo", "AND")
+
+		assert.Equal(cli.ExitUsage, code, "parse errors are usage errors")
+		assert.Contains(env.stderr.String(), "foo AND\n         ^", "caret should point after AND")
+	})
+
+	t.Run("should require a query", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		assert.Equal(cli.ExitUsage, env.run("search"), "missing query is a usage error")
+	})
+}
@@ -0,0 +1,106 @@
+package cli_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/cli"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+// fakeEditor installs an $EDITOR script running body with the file as $1
+func fakeEditor(t *testing.T, body string) {
+	t.Helper()
+
+	script := filepath.Join(t.TempDir(), "editor")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatalf("could not write editor script: %v", err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+}
+
+func TestEditCommand(t *testing.T) {
+	t.Run("should save the changes made in the editor", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Todo", "Buy milk"))
+		fakeEditor(t, `printf '\nBuy bread' >> "$1"`)
+
+		code := env.run("edit", note.ID)
+
+		assert.Equal(cli.ExitOK, code, "edit should succeed")
+		updated, err := env.fs.GetNote(context.Background(), note.ID)
+		assert.NoError(err, "note should still load")
+		assert.Contains(updated.Content, "Buy bread", "editor changes should be kept")
+		assert.True(updated.UpdatedAt.After(note.UpdatedAt), "updated time should move")
+	})
+
+	t.Run("should find notes by title", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Meeting Notes", "Agenda"))
+		fakeEditor(t, `printf '\nAction items' >> "$1"`)
+
+		code := env.run("edit", "meeting", "notes")
+
+		assert.Equal(cli.ExitOK, code, "edit should succeed")
+		updated, _ := env.fs.GetNote(context.Background(), note.ID)
+		assert.Contains(updated.Content, "Action items", "the titled note should be edited")
+	})
+
+	t.Run("should leave unchanged notes alone", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Todo", "Buy milk"))
+		fakeEditor(t, "true")
+
+		code := env.run("edit", note.ID)
+
+		assert.Equal(cli.ExitOK, code, "edit should succeed")
+		updated, _ := env.fs.GetNote(context.Background(), note.ID)
+		assert.True(updated.UpdatedAt.Equal(note.UpdatedAt), "updated time should not move")
+	})
+
+	t.Run("should follow a heading renamed in the editor", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Todo", "Buy milk"))
+		fakeEditor(t, `sed 's/^# Todo$/# Groceries/' "$1" > "$1.tmp" && mv "$1.tmp" "$1"`)
+
+		code := env.run("edit", note.ID)
+
+		assert.Equal(cli.ExitOK, code, "edit should succeed")
+		updated, err := env.fs.GetNote(context.Background(), note.ID)
+		assert.NoError(err, "note should still load")
+		assert.Equal("Groceries", updated.Title, "the new heading should be the title")
+		assert.True(updated.UpdatedAt.After(note.UpdatedAt), "the rename should be saved")
+		data, err := os.ReadFile(updated.FilePath)
+		assert.NoError(err, "the file should be readable")
+		assert.Contains(string(data), "title: Groceries", "the frontmatter should follow the heading")
+	})
+
+	t.Run("should report ambiguous titles", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.save(t, storage.NewNote("Todo", "one"))
+		env.save(t, storage.NewNote("todo", "two"))
+		fakeEditor(t, "true")
+
+		code := env.run("edit", "Todo")
+
+		assert.Equal(cli.ExitError, code, "ambiguous titles should fail")
+		assert.Contains(env.stderr.String(), "several notes", "should explain the problem")
+	})
+
+	t.Run("should report unknown notes", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		assert.Equal(cli.ExitNotFound, env.run("edit", "nothing"), "unknown notes should fail")
+		assert.Equal(cli.ExitUsage, env.run("edit"), "missing note is a usage error")
+	})
+}
@@ -0,0 +1,45 @@
+package cli_test
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/cli"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestLogAndBlameCommands(t *testing.T) {
+	t.Run("should refuse vaults outside git", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.save(t, storage.NewNote("Plain", ""))
+
+		assert.Equal(cli.ExitError, env.run("log", "Plain"), "log needs a git vault")
+		assert.Contains(env.stderr.String(), "version control", "the user should be told why")
+	})
+
+	t.Run("should print the commits and lines of a note", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not installed")
+		}
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		git, err := storage.NewGitFileSystem(env.fs, storage.WithCommitDelay(0))
+		assert.NoError(err, "the git vault should open")
+		env.Storage = git
+		assert.NoError(git.SaveNote(context.Background(), storage.NewNote("Plan", "step one\n")), "the note should save")
+
+		assert.Equal(cli.ExitOK, env.run("log", "Plan"), "log should succeed")
+		assert.Contains(env.stdout.String(), "Add note: Plan", "the commit should be listed")
+
+		var commits []map[string]interface{}
+		assert.Equal(cli.ExitOK, env.run("log", "--json", "Plan"), "log --json should succeed")
+		decodeJSON(t, env.stdout.String(), &commits)
+		assert.Len(commits, 1, "one commit should be listed")
+
+		assert.Equal(cli.ExitOK, env.run("blame", "Plan"), "blame should succeed")
+		assert.Contains(env.stdout.String(), "| step one", "the lines should be printed")
+	})
+}
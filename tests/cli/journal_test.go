@@ -0,0 +1,50 @@
+package cli_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/cli"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestTodayCommand(t *testing.T) {
+	t.Run("should create today's entry and open it", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.Journal = storage.Journal{Template: storage.DefaultJournalTemplate}
+		fakeEditor(t, `printf '\nStandup went well' >> "$1"`)
+
+		code := env.run("today")
+
+		assert.Equal(cli.ExitOK, code, "today should succeed")
+		note, err := env.fs.GetNote(context.Background(), storage.JournalID(time.Now()))
+		assert.NoError(err, "the entry should be named after the day")
+		assert.Contains(note.Content, "Standup went well", "the entry should be edited")
+	})
+
+	t.Run("should print the existing entry", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		entry := storage.NewNote("Today", "Already written")
+		entry.ID = storage.JournalID(time.Now())
+		env.save(t, entry)
+
+		assert.Equal(cli.ExitOK, env.run("today", "--print"), "today --print should succeed")
+		assert.Equal(entry.ID, strings.TrimSpace(env.stdout.String()), "should print the ID of the entry")
+
+		notes, err := env.fs.ListNotes(context.Background())
+		assert.NoError(err, "notes should list")
+		assert.Len(notes, 1, "no other entry should be created")
+	})
+
+	t.Run("should reject arguments", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		assert.Equal(cli.ExitUsage, env.run("today", "tomorrow"), "today takes no argument")
+	})
+}
@@ -0,0 +1,317 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/cli"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+// decodeJSON parses the JSON printed by a command
+func decodeJSON(t *testing.T, data string, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", data, err)
+	}
+}
+
+func TestNewCommand(t *testing.T) {
+	t.Run("should create a note from stdin", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.Stdin = strings.NewReader("commit 1\ncommit 2\n")
+
+		code := env.run("new", "Release", "notes")
+
+		assert.Equal(cli.ExitOK, code, "new should succeed")
+		id := strings.TrimSpace(env.stdout.String())
+		note, err := env.fs.GetNote(context.Background(), id)
+		assert.NoError(err, "printed ID should exist")
+		assert.Equal("Release notes", note.Title, "arguments should make the title")
+		assert.Equal("commit 1\ncommit 2", note.Content, "stdin should make the content")
+	})
+
+	t.Run("should not read a terminal", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.Stdin = strings.NewReader("typed by mistake")
+		env.StdinIsTerminal = true
+
+		code := env.run("--json", "new", "Empty")
+		assert.Equal(cli.ExitUsage, code, "global flags go before the command")
+
+		code = env.run("new", "--json", "Empty")
+		assert.Equal(cli.ExitOK, code, "new should succeed")
+
+		var note map[string]interface{}
+		decodeJSON(t, env.stdout.String(), &note)
+		assert.Equal("Empty", note["title"], "JSON should have the title")
+		assert.Equal("", note["content"], "content should be empty")
+	})
+
+	t.Run("should require a title", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		assert.Equal(cli.ExitUsage, env.run("new"), "missing title is a usage error")
+	})
+}
+
+func TestListAndShowCommands(t *testing.T) {
+	t.Run("should list notes as text and JSON", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Groceries", "Milk"))
+
+		assert.Equal(cli.ExitOK, env.run("list"), "list should succeed")
+		assert.Equal(note.ID+"\tGroceries\n", env.stdout.String(), "should print id and title")
+
+		assert.Equal(cli.ExitOK, env.run("list", "--json"), "list --json should succeed")
+		var notes []map[string]interface{}
+		decodeJSON(t, env.stdout.String(), &notes)
+		assert.Len(notes, 1, "should list one note")
+		assert.Equal(note.ID, notes[0]["id"], "JSON should have the ID")
+		assert.Nil(notes[0]["content"], "lists should leave content out")
+	})
+
+	t.Run("should print the content of a note", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.save(t, storage.NewNote("Groceries", "Milk\nEggs"))
+
+		code := env.run("show", "groceries")
+
+		assert.Equal(cli.ExitOK, code, "show should succeed")
+		assert.Equal("Milk\nEggs\n", env.stdout.String(), "should print the content")
+	})
+
+	t.Run("should exit with ExitNotFound for unknown notes", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		assert.Equal(cli.ExitNotFound, env.run("show", "nothing"), "unknown notes are not found")
+	})
+}
+
+func TestMoveAndRemoveCommands(t *testing.T) {
+	t.Run("should rename a note", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Draft", "Text"))
+
+		code := env.run("mv", "Draft", "Final version")
+
+		assert.Equal(cli.ExitOK, code, "mv should succeed")
+		renamed, _ := env.fs.GetNote(context.Background(), note.ID)
+		assert.Equal("Final version", renamed.Title, "title should change")
+		assert.Equal("Text", renamed.Content, "content should not change")
+	})
+
+	t.Run("should move a note to a folder", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Draft", "Text"))
+
+		code := env.run("mv", "--folder", "work/projects", "Draft")
+
+		assert.Equal(cli.ExitOK, code, "mv should succeed")
+		moved, err := env.fs.GetNote(context.Background(), note.ID)
+		assert.NoError(err, "the note should keep its ID")
+		assert.Equal("work/projects", moved.Folder, "the note should be in the folder")
+		assert.Equal("Draft", moved.Title, "title should not change")
+		assert.Equal(filepath.Join(env.fs.NotesDir(), "work", "projects", note.ID+".md"), moved.FilePath, "the file should move")
+	})
+
+	t.Run("should move and rename a note at once", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := storage.NewNote("Draft", "Text")
+		note.Folder = "inbox"
+		env.save(t, note)
+
+		code := env.run("mv", "--folder", "/", "Draft", "Final version")
+
+		assert.Equal(cli.ExitOK, code, "mv should succeed")
+		moved, _ := env.fs.GetNote(context.Background(), note.ID)
+		assert.Equal("", moved.Folder, "the note should be at the root of the vault")
+		assert.Equal("Final version", moved.Title, "title should change")
+	})
+
+	t.Run("should need a title or a folder", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.save(t, storage.NewNote("Draft", "Text"))
+
+		assert.Equal(cli.ExitUsage, env.run("mv", "Draft"), "nothing to do is a usage error")
+		assert.Equal(cli.ExitUsage, env.run("mv", "--folder", "../out", "Draft"), "folders stay in the vault")
+	})
+
+	t.Run("should delete the notes that exist", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		a := env.save(t, storage.NewNote("A", "a"))
+		env.save(t, storage.NewNote("B", "b"))
+
+		code := env.run("rm", a.ID, "missing", "B")
+
+		assert.Equal(cli.ExitNotFound, code, "a missing note should be reported")
+		notes, _ := env.fs.ListNotes(context.Background())
+		assert.Len(notes, 0, "existing notes should be deleted")
+	})
+}
+
+func TestTagCommand(t *testing.T) {
+	t.Run("should add and remove tags", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := storage.NewNote("Plan", "Q3")
+		note.Tags = []string{"draft"}
+		env.save(t, note)
+
+		code := env.run("tag", "Plan", "+work", "-draft", "ideas")
+
+		assert.Equal(cli.ExitOK, code, "tag should succeed")
+		assert.Equal("work\nideas\n", env.stdout.String(), "should print the tags")
+		tagged, _ := env.fs.GetNote(context.Background(), note.ID)
+		assert.Equal([]string{"work", "ideas"}, tagged.Tags, "tags should be saved")
+	})
+
+	t.Run("should print tags as JSON", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.save(t, storage.NewNote("Plan", "Q3"))
+
+		code := env.run("tag", "--json", "Plan")
+
+		assert.Equal(cli.ExitOK, code, "tag should succeed")
+		var note map[string]interface{}
+		decodeJSON(t, env.stdout.String(), &note)
+		assert.Equal([]interface{}{}, note["tags"], "no tags should be an empty list")
+	})
+}
+
+func TestTagsCommand(t *testing.T) {
+	t.Run("should list tags with their counts", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := storage.NewNote("Plan", "see #ideas")
+		note.Tags = []string{"work"}
+		env.save(t, note)
+		env.save(t, storage.NewNote("Notes", "more #ideas"))
+
+		code := env.run("tags")
+
+		assert.Equal(cli.ExitOK, code, "tags should succeed")
+		assert.Equal("ideas\t2\nwork\t1\n", env.stdout.String(), "should print every tag")
+	})
+
+	t.Run("should list the notes with a tag", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := storage.NewNote("Plan", "see #ideas")
+		env.save(t, note)
+		env.save(t, storage.NewNote("Other", "nothing"))
+
+		code := env.run("tags", "--json", "ideas")
+
+		assert.Equal(cli.ExitOK, code, "tags should succeed")
+		var notes []map[string]interface{}
+		decodeJSON(t, env.stdout.String(), &notes)
+		assert.Len(notes, 1, "only the tagged note should be listed")
+		assert.Equal([]interface{}{"ideas"}, notes[0]["tags"], "hashtags should be part of the tags")
+	})
+}
+
+func TestNewCommand_Template(t *testing.T) {
+	newEnv := func(t *testing.T) *testEnv {
+		env := newTestEnv(t)
+		dir := filepath.Join(env.fs.NotesDir(), ".templates")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("could not create templates folder: %v", err)
+		}
+		content := "---\ntitle: Meeting {{.Date}}\ntags: [meeting]\n---\nWith: {{prompt \"Attendees\"}}\n\n{{cursor}}\n\nActions:\n"
+		if err := os.WriteFile(filepath.Join(dir, "meeting.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("could not write template: %v", err)
+		}
+		return env
+	}
+
+	t.Run("should fill in the template", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newEnv(t)
+		env.Stdin = strings.NewReader("Decided to ship\n")
+
+		code := env.run("new", "--template", "meeting", "--field", "Attendees=Ana, Bo", "Kickoff")
+
+		assert.Equal(cli.ExitOK, code, "new should succeed")
+		note, err := env.fs.GetNote(context.Background(), strings.TrimSpace(env.stdout.String()))
+		assert.NoError(err, "printed ID should exist")
+		assert.Equal("Kickoff", note.Title, "arguments should make the title")
+		assert.Equal("With: Ana, Bo\n\nDecided to ship\n\nActions:", note.Content, "stdin should go at the cursor")
+		assert.Equal([]string{"meeting"}, note.Tags, "the template should tag the note")
+	})
+
+	t.Run("should take the title of the template", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newEnv(t)
+		env.StdinIsTerminal = true
+
+		code := env.run("new", "--json", "--template", "Meeting", "--field", "Attendees=")
+
+		assert.Equal(cli.ExitOK, code, "new should succeed")
+		var note map[string]interface{}
+		decodeJSON(t, env.stdout.String(), &note)
+		assert.True(strings.HasPrefix(note["title"].(string), "Meeting 2"), "the title should come from the template")
+	})
+
+	t.Run("should require the prompted fields", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newEnv(t)
+
+		assert.Equal(cli.ExitUsage, env.run("new", "--template", "meeting", "Kickoff"), "missing fields are a usage error")
+		assert.Contains(env.stderr.String(), "Attendees", "should name the missing field")
+		assert.Equal(cli.ExitUsage, env.run("new", "--field", "Attendees", "Kickoff"), "fields are name=value pairs")
+	})
+
+	t.Run("should report unknown templates", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newEnv(t)
+
+		assert.Equal(cli.ExitNotFound, env.run("new", "--template", "standup", "Daily"), "unknown template")
+	})
+}
+
+func TestBacklinksCommand(t *testing.T) {
+	t.Run("should list the notes linking to a note", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.save(t, storage.NewNote("Plan", "the plan"))
+		meeting := env.save(t, storage.NewNote("Meeting", "see [[plan]]"))
+		env.save(t, storage.NewNote("Trip", "nothing"))
+
+		code := env.run("backlinks", "Plan")
+
+		assert.Equal(cli.ExitOK, code, "backlinks should succeed")
+		assert.Equal(meeting.ID+"\tMeeting\n", env.stdout.String(), "only the linking note should be listed")
+	})
+
+	t.Run("should find a note by alias", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := storage.NewNote("Project Plan", "the plan")
+		note.Aliases = []string{"roadmap"}
+		env.save(t, note)
+
+		code := env.run("show", "Roadmap")
+
+		assert.Equal(cli.ExitOK, code, "show should resolve the alias")
+		assert.Equal("the plan\n", env.stdout.String(), "should print the aliased note")
+	})
+}
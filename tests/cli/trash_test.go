@@ -0,0 +1,60 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/cli"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestTrashCommand(t *testing.T) {
+	t.Run("rm should move notes to the trash", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Groceries", "Milk"))
+
+		assert.Equal(cli.ExitOK, env.run("rm", "Groceries"), "rm should succeed")
+		assert.Equal(cli.ExitOK, env.run("trash", "list"), "trash list should succeed")
+		assert.Contains(env.stdout.String(), "Groceries", "the note should be in the trash")
+		assert.Contains(env.stdout.String(), note.ID+"-", "the trash ID should start with the note ID")
+
+		var trashed []map[string]interface{}
+		assert.Equal(cli.ExitOK, env.run("trash", "list", "--json"), "trash list --json should succeed")
+		decodeJSON(t, env.stdout.String(), &trashed)
+		assert.Len(trashed, 1, "one note should be trashed")
+		assert.Equal(note.ID, trashed[0]["id"], "the note ID should be listed")
+	})
+
+	t.Run("restore should bring a note back by title", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		note := env.save(t, storage.NewNote("Groceries", "Milk"))
+		env.run("rm", note.ID)
+
+		assert.Equal(cli.ExitOK, env.run("trash", "restore", "groceries"), "restore should succeed")
+		assert.Equal(note.ID, strings.TrimSpace(env.stdout.String()), "the restored ID should be printed")
+
+		assert.Equal(cli.ExitOK, env.run("show", note.ID), "the note should be back")
+		assert.Equal(cli.ExitNotFound, env.run("trash", "restore", "Groceries"), "nothing is left to restore")
+	})
+
+	t.Run("empty should purge the trash", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+		env.save(t, storage.NewNote("Groceries", "Milk"))
+		env.run("rm", "Groceries")
+
+		assert.Equal(cli.ExitOK, env.run("trash", "empty"), "empty should succeed")
+		env.run("trash")
+		assert.Empty(env.stdout.String(), "the trash should be empty")
+	})
+
+	t.Run("should reject unknown actions", func(t *testing.T) {
+		assert := testutil.New(t)
+		env := newTestEnv(t)
+
+		assert.Equal(cli.ExitUsage, env.run("trash", "shred"), "unknown action is a usage error")
+	})
+}
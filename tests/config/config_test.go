@@ -0,0 +1,296 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/config"
+	"github.com/N95Ryan/leaf/internal/storage"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("should return an empty config when the file is missing", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		cfg, err := config.Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+		assert.NoError(err, "a missing config file is not an error")
+		assert.NotNil(cfg, "config should be returned")
+		assert.Empty(cfg.Vaults, "no vaults should be configured")
+	})
+
+	t.Run("should parse vaults and the default vault", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		data := "default_vault: work\nvaults:\n  - name: work\n    path: /srv/notes/work\n  - name: personal\n    path: /home/me/notes\n"
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("could not write config: %v", err)
+		}
+
+		cfg, err := config.Load(path)
+
+		assert.NoError(err, "config should parse")
+		assert.Len(cfg.Vaults, 2, "should have 2 vaults")
+		assert.Equal("work", cfg.DefaultVault, "default vault should be parsed")
+
+		vault, ok := cfg.Vault("personal")
+		assert.True(ok, "personal vault should be found")
+		assert.Equal("/home/me/notes", vault.Path, "vault path should match")
+	})
+
+	t.Run("should report invalid YAML", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("vaults: [unclosed"), 0644); err != nil {
+			t.Fatalf("could not write config: %v", err)
+		}
+
+		_, err := config.Load(path)
+		assert.Error(err, "invalid YAML should return an error")
+	})
+}
+
+func TestResolveVault(t *testing.T) {
+	cfg := &config.Config{
+		DefaultVault: "personal",
+		Vaults: []config.Vault{
+			{Name: "work", Path: "/srv/notes/work"},
+			{Name: "personal", Path: "/home/me/notes"},
+		},
+	}
+
+	t.Run("should prefer the dir flag", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv(config.EnvDir, "/tmp/from-env")
+
+		vault, err := cfg.ResolveVault("/srv/notes/work", "personal")
+
+		assert.NoError(err, "should resolve")
+		assert.Equal("work", vault.Name, "a dir matching a configured vault should reuse its name")
+	})
+
+	t.Run("should use the vault flag before the environment", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv(config.EnvDir, "/tmp/from-env")
+
+		vault, err := cfg.ResolveVault("", "work")
+
+		assert.NoError(err, "should resolve")
+		assert.Equal("/srv/notes/work", vault.Path, "vault flag should win over LEAF_DIR")
+	})
+
+	t.Run("should use LEAF_DIR before the default vault", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv(config.EnvDir, "/tmp/from-env")
+
+		vault, err := cfg.ResolveVault("", "")
+
+		assert.NoError(err, "should resolve")
+		assert.Equal("/tmp/from-env", vault.Path, "LEAF_DIR should be used")
+		assert.Equal("from-env", vault.Name, "ad-hoc vaults are named after their directory")
+	})
+
+	t.Run("should fall back to the default vault", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv(config.EnvDir, "")
+
+		vault, err := cfg.ResolveVault("", "")
+
+		assert.NoError(err, "should resolve")
+		assert.Equal("personal", vault.Name, "default vault should be used")
+	})
+
+	t.Run("should reject unknown vault names", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		_, err := cfg.ResolveVault("", "nope")
+		assert.Error(err, "unknown vault should return an error")
+	})
+}
+
+func TestNoteFileMode(t *testing.T) {
+	t.Run("should default to the storage file mode", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		mode, err := (&config.Config{}).NoteFileMode()
+
+		assert.NoError(err, "an unset file_mode is valid")
+		assert.Equal(storage.DefaultFileMode, mode, "default mode should be used")
+	})
+
+	t.Run("should parse an octal mode", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		mode, err := (&config.Config{FileMode: "0600"}).NoteFileMode()
+
+		assert.NoError(err, "0600 is valid")
+		assert.Equal(os.FileMode(0600), mode, "mode should be parsed as octal")
+	})
+
+	t.Run("should reject invalid modes", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		for _, value := range []string{"rw-------", "0999", "01777", "0400"} {
+			_, err := (&config.Config{FileMode: value}).NoteFileMode()
+			assert.Error(err, "file_mode "+value+" should be rejected")
+		}
+	})
+
+	t.Run("should fail to load a config with an invalid mode", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("file_mode: \"999\"\n"), 0644); err != nil {
+			t.Fatalf("could not write config: %v", err)
+		}
+
+		_, err := config.Load(path)
+		assert.Error(err, "an invalid file_mode should fail the load")
+	})
+}
+
+func TestTrashRetentionDuration(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", storage.DefaultTrashRetention},
+		{"0", 0},
+		{"7d", 7 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+	}
+	for _, c := range cases {
+		t.Run("should parse "+c.value, func(t *testing.T) {
+			assert := testutil.New(t)
+
+			got, err := (&config.Config{TrashRetention: c.value}).TrashRetentionDuration()
+
+			assert.NoError(err, "the retention should parse")
+			assert.Equal(c.want, got, "the retention should match")
+		})
+	}
+
+	t.Run("should reject invalid retentions", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		for _, value := range []string{"forever", "-1d", "-3h", "d"} {
+			_, err := (&config.Config{TrashRetention: value}).TrashRetentionDuration()
+			assert.Error(err, "trash_retention "+value+" should be rejected")
+		}
+	})
+}
+
+func TestHistoryRetention(t *testing.T) {
+	t.Run("should default to the storage limits", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		versions, age, err := (&config.Config{}).HistoryRetention()
+
+		assert.NoError(err, "an empty config should be valid")
+		assert.Equal(storage.DefaultHistoryVersions, versions, "the default count should apply")
+		assert.Equal(time.Duration(0), age, "versions should be kept regardless of age")
+	})
+
+	t.Run("should read the configured limits", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		versions, age, err := (&config.Config{HistoryMaxVersions: 10, HistoryMaxAge: "90d"}).HistoryRetention()
+
+		assert.NoError(err, "the retention should parse")
+		assert.Equal(10, versions, "the count should match")
+		assert.Equal(90*24*time.Hour, age, "the age should match")
+	})
+
+	t.Run("should lift the count limit when negative", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		versions, _, err := (&config.Config{HistoryMaxVersions: -1}).HistoryRetention()
+
+		assert.NoError(err, "a negative count should be valid")
+		assert.Equal(0, versions, "there should be no count limit")
+	})
+
+	t.Run("should reject invalid ages", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		_, _, err := (&config.Config{HistoryMaxAge: "soon"}).HistoryRetention()
+		assert.Error(err, "history_max_age soon should be rejected")
+	})
+}
+
+func TestCommitDelay(t *testing.T) {
+	t.Run("should default to the storage delay", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		delay, err := (&config.Config{}).CommitDelay()
+
+		assert.NoError(err, "an empty config should be valid")
+		assert.Equal(storage.DefaultCommitDelay, delay, "the default delay should apply")
+	})
+
+	t.Run("should parse the configured delay", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		delay, err := (&config.Config{GitCommitDelay: "30s"}).CommitDelay()
+
+		assert.NoError(err, "the delay should parse")
+		assert.Equal(30*time.Second, delay, "the delay should match")
+	})
+
+	t.Run("should reject invalid delays", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		_, err := (&config.Config{GitCommitDelay: "later"}).CommitDelay()
+		assert.Error(err, "git_commit_delay later should be rejected")
+	})
+}
+
+func TestFilenameStrategy(t *testing.T) {
+	t.Run("should default to UUIDs", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		strategy, err := (&config.Config{}).FilenameStrategy()
+
+		assert.NoError(err, "an empty config should be valid")
+		assert.Equal(storage.FilenamesUUID, strategy, "files should be named by ID")
+	})
+
+	t.Run("should parse the configured strategy", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		strategy, err := (&config.Config{Filenames: "Slug"}).FilenameStrategy()
+
+		assert.NoError(err, "the strategy should parse")
+		assert.Equal(storage.FilenamesSlug, strategy, "the strategy should match")
+	})
+
+	t.Run("should reject unknown strategies", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		_, err := (&config.Config{Filenames: "title"}).FilenameStrategy()
+		assert.Error(err, "filenames title should be rejected")
+	})
+}
+
+func TestJournal(t *testing.T) {
+	t.Run("should default to the journal template", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		journal := (&config.Config{}).Journal()
+
+		assert.Equal(storage.Journal{Template: storage.DefaultJournalTemplate}, journal, "entries should start from the journal template")
+	})
+
+	t.Run("should use the configured template and folder", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		journal := (&config.Config{JournalTemplate: "daily", JournalFolder: "journal/"}).Journal()
+
+		assert.Equal(storage.Journal{Template: "daily", Folder: "journal"}, journal, "the config should be followed")
+	})
+}
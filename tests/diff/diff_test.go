@@ -0,0 +1,157 @@
+package diff_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/diff"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestLines(t *testing.T) {
+	t.Run("should mark inserted and deleted lines", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		lines := diff.Lines("a\nb\nc", "a\nc\nd")
+
+		assert.Equal([]diff.Line{
+			{Kind: diff.Equal, Text: "a"},
+			{Kind: diff.Delete, Text: "b"},
+			{Kind: diff.Equal, Text: "c"},
+			{Kind: diff.Insert, Text: "d"},
+		}, lines, "diff should keep the common lines")
+	})
+
+	t.Run("should handle empty texts", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		assert.Empty(diff.Lines("", ""), "two empty texts have no diff")
+		assert.Equal([]diff.Line{{Kind: diff.Insert, Text: "new"}}, diff.Lines("", "new"), "every line is inserted")
+	})
+
+	t.Run("should keep as many lines as possible", func(t *testing.T) {
+		assert := testutil.New(t)
+		rng := rand.New(rand.NewSource(1))
+
+		for n := 0; n < 500; n++ {
+			old, new := randomText(rng), randomText(rng)
+			lines := diff.Lines(old, new)
+
+			a, b := replay(lines)
+			assert.Equal(old, a, "the diff should hold the old text")
+			assert.Equal(new, b, "the diff should hold the new text")
+			kept := 0
+			for _, line := range lines {
+				if line.Kind == diff.Equal {
+					kept++
+				}
+			}
+			assert.Equal(lcsLength(diff.SplitLines(old), diff.SplitLines(new)), kept, fmt.Sprintf("the diff of %q and %q should be minimal", old, new))
+		}
+	})
+
+	t.Run("should handle long texts", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		var old, new strings.Builder
+		for i := 0; i < 100000; i++ {
+			fmt.Fprintf(&old, "line %d\n", i)
+			switch i {
+			case 10, 50000:
+				fmt.Fprintf(&new, "changed %d\n", i)
+			default:
+				fmt.Fprintf(&new, "line %d\n", i)
+			}
+		}
+
+		lines := diff.Lines(old.String(), new.String())
+
+		assert.Len(lines, 100002, "only the changed lines should differ")
+		a, b := replay(lines)
+		assert.Equal(old.String(), a, "the diff should hold the old text")
+		assert.Equal(new.String(), b, "the diff should hold the new text")
+	})
+}
+
+// randomText returns a few lines drawn from a small set, so that texts share some
+func randomText(rng *rand.Rand) string {
+	var b strings.Builder
+	for i := rng.Intn(12); i > 0; i-- {
+		b.WriteString(string(rune('a'+rng.Intn(4))) + "\n")
+	}
+	return b.String()
+}
+
+// replay rebuilds the old and new texts from a diff
+func replay(lines []diff.Line) (old, new string) {
+	var a, b strings.Builder
+	for _, line := range lines {
+		if line.Kind != diff.Insert {
+			a.WriteString(line.Text + "\n")
+		}
+		if line.Kind != diff.Delete {
+			b.WriteString(line.Text + "\n")
+		}
+	}
+	return a.String(), b.String()
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	length := make([][]int, len(a)+1)
+	for i := range length {
+		length[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				length[i][j] = length[i+1][j+1] + 1
+			} else {
+				length[i][j] = max(length[i+1][j], length[i][j+1])
+			}
+		}
+	}
+	return length[0][0]
+}
+
+func TestMerge3(t *testing.T) {
+	base := "title\none\ntwo\nthree"
+
+	t.Run("should apply changes made on different lines", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		merged, conflicts := diff.Merge3(base, "title\nONE\ntwo\nthree", "title\none\ntwo\nTHREE\nfour")
+
+		assert.Equal(0, conflicts, "there should be no conflict")
+		assert.Equal("title\nONE\ntwo\nTHREE\nfour", merged, "both changes should be applied")
+	})
+
+	t.Run("should accept the same change made on both sides", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		merged, conflicts := diff.Merge3(base, "title\none\n2\nthree", "title\none\n2\nthree")
+
+		assert.Equal(0, conflicts, "identical changes don't conflict")
+		assert.Equal("title\none\n2\nthree", merged, "the change should be applied once")
+	})
+
+	t.Run("should mark lines changed differently on both sides", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		merged, conflicts := diff.Merge3(base, "title\none\nmine\nthree", "title\none\ntheirs\nthree")
+
+		assert.Equal(1, conflicts, "there should be one conflict")
+		assert.Equal("title\none\n"+diff.MarkerOurs+"\nmine\n"+diff.MarkerSep+"\ntheirs\n"+diff.MarkerTheirs+"\nthree", merged, "both versions should be kept")
+	})
+
+	t.Run("should keep deletions made on one side", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		merged, conflicts := diff.Merge3(base, "title\none\nthree", base)
+
+		assert.Equal(0, conflicts, "there should be no conflict")
+		assert.Equal("title\none\nthree", merged, "the deleted line should stay deleted")
+	})
+}
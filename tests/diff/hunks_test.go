@@ -0,0 +1,72 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/diff"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestHunks(t *testing.T) {
+	old := strings.Join([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, "\n")
+
+	t.Run("should keep context around each change", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		hunks := diff.Hunks(diff.Lines(old, strings.Replace(old, "2", "two", 1)), 1)
+
+		assert.Len(hunks, 1, "one change makes one hunk")
+		assert.Equal(1, hunks[0].OldStart, "the hunk should start at the first line")
+		assert.Equal(3, hunks[0].OldLines, "the hunk should span lines 1 to 3")
+		assert.Equal(3, hunks[0].NewLines, "the new side has as many lines")
+		assert.Equal([]diff.Line{
+			{Kind: diff.Equal, Text: "1"},
+			{Kind: diff.Delete, Text: "2"},
+			{Kind: diff.Insert, Text: "two"},
+			{Kind: diff.Equal, Text: "3"},
+		}, hunks[0].Lines, "the change should be surrounded by one line of context")
+	})
+
+	t.Run("should split distant changes", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		changed := strings.Replace(strings.Replace(old, "2", "two", 1), "9", "nine", 1)
+		hunks := diff.Hunks(diff.Lines(old, changed), 1)
+
+		assert.Len(hunks, 2, "changes far apart should make two hunks")
+		assert.Equal(8, hunks[1].OldStart, "the second hunk should start one line before 9")
+		assert.Equal(8, hunks[1].NewStart, "the line numbers match on both sides")
+	})
+
+	t.Run("should merge close changes", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		changed := strings.Replace(strings.Replace(old, "2", "two", 1), "4", "four", 1)
+		hunks := diff.Hunks(diff.Lines(old, changed), 1)
+
+		assert.Len(hunks, 1, "changes sharing context should make one hunk")
+		assert.Equal(5, hunks[0].OldLines, "the hunk should span lines 1 to 5")
+	})
+
+	t.Run("should have no hunk without changes", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		assert.Empty(diff.Hunks(diff.Lines(old, old), 3), "identical texts have no hunk")
+	})
+}
+
+func TestSideBySide(t *testing.T) {
+	t.Run("should pair deleted and inserted lines", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		rows := diff.SideBySide(diff.Lines("a\nb\nc", "a\nB\nB2\nc"))
+
+		assert.Len(rows, 4, "the longer side sets the number of rows")
+		assert.Equal("b", rows[1].Old.Text, "the deleted line should be on the left")
+		assert.Equal("B", rows[1].New.Text, "the inserted line should be beside it")
+		assert.Nil(rows[2].Old, "the extra inserted line has nothing on the left")
+		assert.Equal("B2", rows[2].New.Text, "the extra inserted line should be on the right")
+		assert.Equal("c", rows[3].Old.Text, "unchanged lines are on both sides")
+	})
+}
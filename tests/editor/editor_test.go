@@ -0,0 +1,55 @@
+package editor_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/editor"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestName(t *testing.T) {
+	t.Run("should prefer $VISUAL over $EDITOR", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv("VISUAL", "hx")
+		t.Setenv("EDITOR", "nvim")
+
+		assert.Equal("hx", editor.Name(), "VISUAL should win")
+	})
+
+	t.Run("should use $EDITOR without $VISUAL", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv("VISUAL", "")
+		t.Setenv("EDITOR", "nvim")
+
+		assert.Equal("nvim", editor.Name(), "EDITOR should be used")
+	})
+
+	t.Run("should fall back to vi", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv("VISUAL", "")
+		t.Setenv("EDITOR", "")
+
+		assert.Equal("vi", editor.Name(), "vi is the fallback")
+	})
+}
+
+func TestCommand(t *testing.T) {
+	t.Run("should pass editor arguments before the file", func(t *testing.T) {
+		assert := testutil.New(t)
+		t.Setenv("VISUAL", "code --wait")
+
+		cmd, err := editor.Command("/notes/a.md")
+
+		assert.NoError(err, "Command should succeed")
+		assert.Equal([]string{"code", "--wait", "/notes/a.md"}, cmd.Args, "arguments should be split")
+	})
+
+	t.Run("should fail without a file", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		_, err := editor.Command("")
+
+		assert.True(errors.Is(err, editor.ErrNoFile), "should return ErrNoFile")
+	})
+}
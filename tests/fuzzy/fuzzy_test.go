@@ -0,0 +1,117 @@
+package fuzzy_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/fuzzy"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+func TestMatchString(t *testing.T) {
+	t.Run("should match subsequences case-insensitively", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		match, ok := fuzzy.MatchString("mtg", "Meeting notes")
+
+		assert.True(ok, "mtg is a subsequence of Meeting")
+		assert.Equal([]int{0, 3, 6}, match.Positions, "positions should point at the matched runes")
+	})
+
+	t.Run("should reject non-subsequences", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		_, ok := fuzzy.MatchString("xyz", "Meeting notes")
+		assert.False(ok, "xyz is not in the candidate")
+
+		_, ok = fuzzy.MatchString("tm", "Meeting")
+		assert.False(ok, "order matters")
+	})
+
+	t.Run("should prefer word starts", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		match, ok := fuzzy.MatchString("rn", "release notes")
+
+		assert.True(ok, "rn should match")
+		assert.Equal([]int{0, 8}, match.Positions, "should pick the start of 'notes' over the 'n' in between")
+	})
+
+	t.Run("should handle multi-byte runes", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		match, ok := fuzzy.MatchString("éé", "Café été")
+
+		assert.True(ok, "accented runes should match")
+		assert.Equal([]int{3, 5}, match.Positions, "positions are rune indexes")
+	})
+}
+
+func TestFind(t *testing.T) {
+	t.Run("should rank word starts and consecutive matches first", func(t *testing.T) {
+		assert := testutil.New(t)
+		candidates := []string{
+			"Grocery list",
+			"Golang tips",
+			"Go language spec",
+			"Weekly goals",
+		}
+
+		matches := fuzzy.Find("gol", candidates)
+
+		order := make([]int, len(matches))
+		for i, match := range matches {
+			order[i] = match.Index
+		}
+
+		// Consecutive "Gol" at a word start beats "Go" + "l" at the next word start,
+		// then word starts beat a match buried far from the beginning
+		assert.Equal([]int{1, 2, 0, 3}, order, "ranking should favor consecutive word-start matches")
+	})
+
+	t.Run("should return everything for an empty pattern", func(t *testing.T) {
+		assert := testutil.New(t)
+
+		matches := fuzzy.Find("", []string{"a", "b"})
+
+		assert.Len(matches, 2, "empty pattern matches all")
+		assert.Equal(0, matches[0].Index, "original order is kept")
+	})
+}
+
+func TestFind_TenThousandCandidates(t *testing.T) {
+	candidates := tenThousandTitles()
+	matcher := fuzzy.NewMatcher(candidates)
+
+	start := time.Now()
+	matches := matcher.Find("prjmtg")
+	elapsed := time.Since(start)
+
+	if len(matches) == 0 {
+		t.Fatal("expected matches")
+	}
+	// Generous bound: a keystroke must not stall the UI
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("matching 10k candidates took %v", elapsed)
+	}
+}
+
+func BenchmarkFind_10k(b *testing.B) {
+	matcher := fuzzy.NewMatcher(tenThousandTitles())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher.Find("prjmtg")
+	}
+}
+
+// tenThousandTitles generates realistic note titles
+func tenThousandTitles() []string {
+	words := []string{"Project", "meeting", "notes", "Weekly", "review", "Design", "doc", "Incident", "retro", "Planning"}
+	titles := make([]string, 10000)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("%s %s %s %d", words[i%10], words[(i/10)%10], words[(i/100)%10], i)
+	}
+	return titles
+}
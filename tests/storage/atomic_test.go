@@ -0,0 +1,146 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestSaveNote_LeavesNoTemporaryFiles(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	note := storage.NewNote("Atomic", "First version")
+	saveNotes(t, fs, note)
+	note.Content = "Second version"
+	saveNotes(t, fs, note)
+
+	for _, dir := range []string{fs.NotesDir(), filepath.Join(fs.NotesDir(), ".leaf")} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("could not read %s: %v", dir, err)
+		}
+		for _, entry := range entries {
+			if strings.Contains(entry.Name(), ".tmp-") {
+				t.Errorf("temporary file left behind: %s", entry.Name())
+			}
+		}
+	}
+
+	data, err := os.ReadFile(note.FilePath)
+	if err != nil {
+		t.Fatalf("could not read note: %v", err)
+	}
+	if !strings.Contains(string(data), "Second version") {
+		t.Errorf("note should hold the last saved content, got:\n%s", data)
+	}
+}
+
+func TestSaveNote_FileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not enforced on windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), "private")
+	fs, err := storage.NewLocalFileSystemAt(dir, storage.WithFileMode(0600))
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+
+	note := storage.NewNote("Secret", "Eyes only")
+	saveNotes(t, fs, note)
+
+	checkMode := func(path string, want os.FileMode) {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("could not stat %s: %v", path, err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s: expected mode %o, got %o", path, want, got)
+		}
+	}
+
+	checkMode(dir, 0700)
+	checkMode(note.FilePath, 0600)
+	if err := fs.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	checkMode(filepath.Join(dir, ".leaf", "index.gob"), 0600)
+}
+
+// writeTempFile plants a temporary file as left by an interrupted write,
+// last modified age ago
+func writeTempFile(t *testing.T, path string, age time.Duration) string {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("could not create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte("partial"), 0644); err != nil {
+		t.Fatalf("could not write %s: %v", path, err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("could not age %s: %v", path, err)
+	}
+	return path
+}
+
+func TestRecover(t *testing.T) {
+	fs := newTestFileSystem(t)
+	saveNotes(t, fs, storage.NewNote("Kept", "Intact"))
+
+	stale := writeTempFile(t, filepath.Join(fs.NotesDir(), ".kept.md.tmp-123"), time.Hour)
+	fresh := writeTempFile(t, filepath.Join(fs.NotesDir(), ".other.md.tmp-456"), 0)
+
+	removed, err := fs.Recover()
+	if err != nil {
+		t.Fatalf("Recover() failed: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0] != stale {
+		t.Errorf("expected only %s to be removed, got %v", stale, removed)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale temporary file should be gone")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("a write in progress should be left alone: %v", err)
+	}
+
+	notes, err := fs.ListNotes(context.Background())
+	if err != nil {
+		t.Fatalf("ListNotes() failed: %v", err)
+	}
+	if len(notes) != 1 {
+		t.Errorf("expected the saved note to survive, got %d notes", len(notes))
+	}
+}
+
+func TestRecover_NestedFolders(t *testing.T) {
+	fs := newTestFileSystem(t)
+	note := storage.NewNote("Plan", "Nested")
+	note.Folder = "work/projects"
+	saveNotes(t, fs, note)
+
+	nested := writeTempFile(t, filepath.Join(fs.NotesDir(), "work", "projects", ".plan.md.tmp-1"), time.Hour)
+	inGit := writeTempFile(t, filepath.Join(fs.NotesDir(), ".git", ".index.tmp-2"), time.Hour)
+
+	removed, err := fs.Recover()
+	if err != nil {
+		t.Fatalf("Recover() failed: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0] != nested {
+		t.Errorf("expected only %s to be removed, got %v", nested, removed)
+	}
+	if _, err := os.Stat(inGit); err != nil {
+		t.Errorf("files of the git repository should be left alone: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestSaveNote_DetectsConflicts(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Shared", "Original")
+	saveNotes(t, fs, note)
+	if note.Revision == "" {
+		t.Fatal("SaveNote() should set the revision")
+	}
+
+	// Saving again from the same in-memory note is fine
+	note.Content = "Second save"
+	saveNotes(t, fs, note)
+
+	loaded, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if loaded.Revision != note.Revision {
+		t.Errorf("loaded revision %q should match the saved one %q", loaded.Revision, note.Revision)
+	}
+
+	// Someone else changes the file
+	other, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	other.Content = "Changed elsewhere"
+	saveNotes(t, fs, other)
+
+	loaded.Content = "Stale edit"
+	err = fs.SaveNote(ctx, loaded)
+	if !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	data, err := os.ReadFile(note.FilePath)
+	if err != nil {
+		t.Fatalf("could not read note: %v", err)
+	}
+	if !strings.Contains(string(data), "Changed elsewhere") || strings.Contains(string(data), "Stale edit") {
+		t.Errorf("a conflicting save must not touch the file, got:\n%s", data)
+	}
+
+	// Taking the new revision overwrites the file
+	loaded.Revision = other.Revision
+	saveNotes(t, fs, loaded)
+}
@@ -0,0 +1,72 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing notes are ErrNotFound", func(t *testing.T) {
+		fs := newTestFileSystem(t)
+
+		if _, err := fs.GetNote(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("GetNote: expected ErrNotFound, got %v", err)
+		}
+		if err := fs.DeleteNote(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("DeleteNote: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("malformed IDs are ErrInvalidID", func(t *testing.T) {
+		fs := newTestFileSystem(t)
+
+		for _, id := range []string{"", "a/b", `a\b`} {
+			if _, err := fs.GetNote(ctx, id); !errors.Is(err, storage.ErrInvalidID) {
+				t.Errorf("GetNote(%q): expected ErrInvalidID, got %v", id, err)
+			}
+			if err := fs.DeleteNote(ctx, id); !errors.Is(err, storage.ErrInvalidID) {
+				t.Errorf("DeleteNote(%q): expected ErrInvalidID, got %v", id, err)
+			}
+			if err := fs.SaveNote(ctx, &storage.Note{ID: id, Title: "Bad"}); !errors.Is(err, storage.ErrInvalidID) {
+				t.Errorf("SaveNote(%q): expected ErrInvalidID, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("binary files are ErrCorrupt", func(t *testing.T) {
+		fs := newTestFileSystem(t)
+		path := filepath.Join(fs.NotesDir(), "binary.md")
+		if err := os.WriteFile(path, []byte{0xff, 0xfe, 0x00, 0x01}, 0644); err != nil {
+			t.Fatalf("could not write file: %v", err)
+		}
+
+		if _, err := fs.GetNote(ctx, "binary"); !errors.Is(err, storage.ErrCorrupt) {
+			t.Errorf("expected ErrCorrupt, got %v", err)
+		}
+	})
+
+	t.Run("unwritable directories are ErrReadOnly", func(t *testing.T) {
+		if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+			t.Skip("directory permissions are not enforced")
+		}
+
+		fs := newTestFileSystem(t)
+		if err := os.Chmod(fs.NotesDir(), 0555); err != nil {
+			t.Fatalf("could not make the directory read-only: %v", err)
+		}
+		t.Cleanup(func() { os.Chmod(fs.NotesDir(), 0755) })
+
+		err := fs.SaveNote(ctx, storage.NewNote("Locked", "Nope"))
+		if !errors.Is(err, storage.ErrReadOnly) {
+			t.Errorf("expected ErrReadOnly, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// newSlugFileSystem opens dir naming files with strategy
+func newSlugFileSystem(t *testing.T, dir string, strategy storage.FilenameStrategy) *storage.LocalFileSystem {
+	t.Helper()
+
+	fs, err := storage.NewLocalFileSystemAt(dir, storage.WithFilenames(strategy))
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+	return fs
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Project Plan", "project-plan"},
+		{"Café & Crème: v2!", "cafe-creme-v2"},
+		{"  ", "untitled"},
+		{"../../etc/passwd", "etc-passwd"},
+		{strings.Repeat("word ", 40), strings.TrimSuffix(strings.Repeat("word-", 16), "-")},
+	}
+
+	for _, tt := range tests {
+		if got := storage.Slugify(tt.title); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilenameStrategy(t *testing.T) {
+	for _, name := range []string{"", "uuid", "slug", "DATE"} {
+		if _, err := storage.ParseFilenameStrategy(name); err != nil {
+			t.Errorf("ParseFilenameStrategy(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := storage.ParseFilenameStrategy("title"); err == nil {
+		t.Errorf("an unknown strategy should be rejected")
+	}
+}
+
+func TestSlugFilenames(t *testing.T) {
+	dir := t.TempDir()
+	fs := newSlugFileSystem(t, dir, storage.FilenamesSlug)
+	ctx := context.Background()
+
+	plan := storage.NewNote("Project Plan", "the plan")
+	other := storage.NewNote("Project plan", "another plan")
+	saveNotes(t, fs, plan, other)
+
+	if got := filepath.Base(plan.FilePath); got != "project-plan.md" {
+		t.Errorf("the file should be named after the title, got %s", got)
+	}
+	if got := filepath.Base(other.FilePath); got != "project-plan-2.md" {
+		t.Errorf("a taken name should get a suffix, got %s", got)
+	}
+
+	// A fresh storage finds the notes by the ID of their frontmatter
+	reopened := newSlugFileSystem(t, dir, storage.FilenamesSlug)
+	got, err := reopened.GetNote(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if got.ID != plan.ID || got.Content != "the plan" {
+		t.Errorf("GetNote() = %q %q, want the plan", got.ID, got.Content)
+	}
+	if id, ok := reopened.NoteIDAt(other.FilePath); !ok || id != other.ID {
+		t.Errorf("NoteIDAt() = %q, %v, want %q", id, ok, other.ID)
+	}
+
+	// Saving without renaming keeps the file
+	plan.Content = "the new plan"
+	saveNotes(t, fs, plan)
+	if got := filepath.Base(plan.FilePath); got != "project-plan.md" {
+		t.Errorf("the file should keep its name, got %s", got)
+	}
+
+	// Renaming renames the file, and frees the old name
+	plan.Title = "Roadmap"
+	saveNotes(t, fs, plan)
+	if got := filepath.Base(plan.FilePath); got != "roadmap.md" {
+		t.Errorf("the file should follow the title, got %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "project-plan.md")); !os.IsNotExist(err) {
+		t.Errorf("the old file should be gone, got %v", err)
+	}
+	notes, err := fs.ListNotes(ctx)
+	if err != nil {
+		t.Fatalf("ListNotes() failed: %v", err)
+	}
+	if len(notes) != 2 {
+		t.Errorf("expected 2 notes after the rename, got %d", len(notes))
+	}
+	if id, ok := fs.NoteIDAt(filepath.Join(dir, "project-plan.md")); !ok || id != plan.ID {
+		t.Errorf("the old path should still tell the ID, got %q, %v", id, ok)
+	}
+
+	// A note moved to a folder keeps its file name
+	moved, err := fs.MoveNote(ctx, other.ID, "work")
+	if err != nil {
+		t.Fatalf("MoveNote() failed: %v", err)
+	}
+	if want := filepath.Join(dir, "work", "project-plan-2.md"); moved.FilePath != want {
+		t.Errorf("MoveNote() path = %s, want %s", moved.FilePath, want)
+	}
+}
+
+func TestDateFilenames(t *testing.T) {
+	fs := newSlugFileSystem(t, t.TempDir(), storage.FilenamesDate)
+
+	note := storage.NewNote("Standup", "notes")
+	note.CreatedAt = time.Date(2026, 3, 14, 9, 0, 0, 0, time.Local)
+	saveNotes(t, fs, note)
+
+	if got := filepath.Base(note.FilePath); got != "2026-03-14-standup.md" {
+		t.Errorf("the file should be named after the date and title, got %s", got)
+	}
+}
+
+func TestFilenames_LegacyFiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Notes named by ID keep working once files are named after titles
+	note := storage.NewNote("Groceries", "milk")
+	saveNotes(t, newSlugFileSystem(t, dir, storage.FilenamesUUID), note)
+	if err := os.WriteFile(filepath.Join(dir, "by-hand.md"), []byte("# Written by hand\n\nno frontmatter"), 0644); err != nil {
+		t.Fatalf("could not write note: %v", err)
+	}
+
+	fs := newSlugFileSystem(t, dir, storage.FilenamesSlug)
+	if _, err := fs.GetNote(ctx, note.ID); err != nil {
+		t.Fatalf("GetNote() of a note named by ID failed: %v", err)
+	}
+	byHand, err := fs.GetNote(ctx, "by-hand")
+	if err != nil {
+		t.Fatalf("a note without frontmatter should be found by file name: %v", err)
+	}
+
+	saveNotes(t, fs, note)
+	if got := filepath.Base(note.FilePath); got != note.ID+".md" {
+		t.Errorf("saving without renaming should keep the file, got %s", got)
+	}
+	note.Title = "Shopping"
+	byHand.Title = "Chores"
+	saveNotes(t, fs, note, byHand)
+	if got := filepath.Base(note.FilePath); got != "shopping.md" {
+		t.Errorf("renaming should switch to a slug, got %s", got)
+	}
+	got, err := fs.GetNote(ctx, "by-hand")
+	if err != nil {
+		t.Fatalf("the note should keep its ID once renamed: %v", err)
+	}
+	if filepath.Base(got.FilePath) != "chores.md" || got.Title != "Chores" {
+		t.Errorf("GetNote() = %s %q, want chores.md", filepath.Base(got.FilePath), got.Title)
+	}
+}
+
+func TestGitFileSystem_CommitsFileRenames(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	fs, err := storage.NewGitFileSystem(newSlugFileSystem(t, t.TempDir(), storage.FilenamesSlug), storage.WithCommitDelay(0))
+	if err != nil {
+		t.Fatalf("NewGitFileSystem() failed: %v", err)
+	}
+	t.Cleanup(func() { fs.Close() })
+
+	note := storage.NewNote("Draft", "text")
+	saveNotes(t, fs, note)
+	note.Title = "Final"
+	saveNotes(t, fs, note)
+
+	out, err := exec.Command("git", "-C", fs.NotesDir(), "ls-files").Output()
+	if err != nil {
+		t.Fatalf("git ls-files failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "final.md" {
+		t.Errorf("only the renamed file should be tracked, got %q", got)
+	}
+	if got := gitLog(t, fs); len(got) != 2 || got[0] != "Rename note: Draft -> Final" {
+		t.Errorf("unexpected commits: %q", got)
+	}
+}
@@ -0,0 +1,192 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestListNotes_Recursive(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	root := storage.NewNote("Root", "at the top")
+	nested := storage.NewNote("Nested", "deep down")
+	nested.Folder = "work/projects"
+	saveNotes(t, fs, root, nested)
+
+	want := filepath.Join(fs.NotesDir(), "work", "projects", nested.ID+".md")
+	if nested.FilePath != want {
+		t.Errorf("expected the note in %s, got %s", want, nested.FilePath)
+	}
+
+	// Hidden directories are not part of the vault
+	hidden := filepath.Join(fs.NotesDir(), ".cache")
+	if err := os.MkdirAll(hidden, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hidden, "x.md"), []byte("# Hidden\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := fs.ListNotes(ctx)
+	if err != nil {
+		t.Fatalf("ListNotes() failed: %v", err)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 notes, got %d", len(notes))
+	}
+
+	got, err := fs.GetNote(ctx, nested.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if got.Folder != "work/projects" || got.Content != "deep down" {
+		t.Errorf("unexpected nested note: %+v", got)
+	}
+}
+
+func TestSaveNote_MovesBetweenFolders(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Wanderer", "on the move")
+	saveNotes(t, fs, note)
+	old := note.FilePath
+
+	note.Folder = "/archive/"
+	saveNotes(t, fs, note)
+
+	if note.Folder != "archive" {
+		t.Errorf("the folder should be cleaned, got %q", note.Folder)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("the old file should be gone")
+	}
+	notes, err := fs.ListNotes(ctx)
+	if err != nil {
+		t.Fatalf("ListNotes() failed: %v", err)
+	}
+	if len(notes) != 1 || notes[0].ID != note.ID || notes[0].Folder != "archive" {
+		t.Errorf("expected the note once, in archive, got %+v", notes)
+	}
+
+	note.Folder = "../outside"
+	if err := fs.SaveNote(ctx, note); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("expected ErrInvalidID for a folder outside the vault, got %v", err)
+	}
+}
+
+func TestMoveNote(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Mover", "find me later")
+	saveNotes(t, fs, note)
+
+	moved, err := fs.MoveNote(ctx, note.ID, "inbox/today")
+	if err != nil {
+		t.Fatalf("MoveNote() failed: %v", err)
+	}
+	if moved.ID != note.ID || moved.Folder != "inbox/today" || moved.Content != "find me later" {
+		t.Errorf("the note should keep its ID and content, got %+v", moved)
+	}
+
+	results, err := fs.SearchNotes(ctx, "folder:inbox later")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != note.ID {
+		t.Errorf("the note should be found in its new folder, got %v", resultIDs(results))
+	}
+	results, err = fs.SearchNotes(ctx, "folder:inbox/to later")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("folder: should match whole folder names, got %v", resultIDs(results))
+	}
+
+	if _, err := fs.MoveNote(ctx, "missing", "inbox"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRenameFolder(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Plan", "the big plan")
+	note.Folder = "work/projects"
+	saveNotes(t, fs, note)
+	if err := fs.CreateFolder(ctx, "personal"); err != nil {
+		t.Fatalf("CreateFolder() failed: %v", err)
+	}
+
+	folders, err := fs.ListFolders(ctx)
+	if err != nil {
+		t.Fatalf("ListFolders() failed: %v", err)
+	}
+	if want := []string{"personal", "work", "work/projects"}; !reflect.DeepEqual(folders, want) {
+		t.Errorf("expected folders %v, got %v", want, folders)
+	}
+
+	if err := fs.RenameFolder(ctx, "work", "personal"); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("expected ErrConflict when the target exists, got %v", err)
+	}
+	if err := fs.RenameFolder(ctx, "work", "work/projects/inner"); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("expected ErrInvalidID when moving a folder into itself, got %v", err)
+	}
+
+	if err := fs.RenameFolder(ctx, "work", "personal/job"); err != nil {
+		t.Fatalf("RenameFolder() failed: %v", err)
+	}
+	got, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if got.Folder != "personal/job/projects" {
+		t.Errorf("the note should follow its folder, got %q", got.Folder)
+	}
+
+	results, err := fs.SearchNotes(ctx, "folder:personal plan")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("the search should find the note in its new folder, got %d results", len(results))
+	}
+}
+
+func TestRestoreNote_IntoFolder(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Filed", "in a folder")
+	note.Folder = "archive"
+	saveNotes(t, fs, note)
+	if err := fs.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+	// Remove the emptied folder, the restore brings it back
+	if err := os.Remove(filepath.Join(fs.NotesDir(), "archive")); err != nil {
+		t.Fatal(err)
+	}
+
+	trashed, err := fs.ListTrash(ctx)
+	if err != nil || len(trashed) != 1 {
+		t.Fatalf("ListTrash() = %v, %v", trashed, err)
+	}
+	restored, err := fs.RestoreNote(ctx, trashed[0].TrashID)
+	if err != nil {
+		t.Fatalf("RestoreNote() failed: %v", err)
+	}
+	if restored.Folder != "archive" {
+		t.Errorf("the note should go back to its folder, got %q", restored.Folder)
+	}
+}
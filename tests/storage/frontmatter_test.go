@@ -0,0 +1,215 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestSaveNote_WritesFrontmatter(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	ctx := context.Background()
+
+	// Create a note with metadata and an old creation date
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	note := storage.NewNote("Frontmatter Note", "Body text")
+	note.CreatedAt = created
+	note.Tags = []string{"go", "notes"}
+	note.Aliases = []string{"fm"}
+	note.Metadata = map[string]interface{}{"status": "draft", "priority": 2}
+
+	if err := fs.SaveNote(ctx, note); err != nil {
+		t.Fatalf("SaveNote() failed: %v", err)
+	}
+
+	// The file should start with a frontmatter block followed by the title heading
+	raw, err := os.ReadFile(note.FilePath)
+	if err != nil {
+		t.Fatalf("could not read note file: %v", err)
+	}
+	text := string(raw)
+	if !strings.HasPrefix(text, "---\nid: "+note.ID+"\n") {
+		t.Errorf("file should start with frontmatter, got:\n%s", text)
+	}
+	if !strings.Contains(text, "\n---\n# Frontmatter Note\n\nBody text") {
+		t.Errorf("title heading should follow the frontmatter, got:\n%s", text)
+	}
+
+	// Read it back
+	retrieved, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+
+	if !retrieved.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt mismatch: expected %v, got %v", created, retrieved.CreatedAt)
+	}
+	if !retrieved.UpdatedAt.Equal(note.UpdatedAt) {
+		t.Errorf("UpdatedAt mismatch: expected %v, got %v", note.UpdatedAt, retrieved.UpdatedAt)
+	}
+	if retrieved.Title != "Frontmatter Note" || retrieved.Content != "Body text" {
+		t.Errorf("unexpected title/content: %q / %q", retrieved.Title, retrieved.Content)
+	}
+	if strings.Join(retrieved.Tags, ",") != "go,notes" {
+		t.Errorf("tags mismatch: got %v", retrieved.Tags)
+	}
+	if strings.Join(retrieved.Aliases, ",") != "fm" {
+		t.Errorf("aliases mismatch: got %v", retrieved.Aliases)
+	}
+	if retrieved.Metadata["status"] != "draft" || retrieved.Metadata["priority"] != 2 {
+		t.Errorf("custom metadata mismatch: got %v", retrieved.Metadata)
+	}
+	if _, ok := retrieved.Metadata["title"]; ok {
+		t.Error("well-known fields should not leak into Metadata")
+	}
+}
+
+func TestSaveNote_PreservesCreatedAtAcrossSaves(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	ctx := context.Background()
+
+	note := storage.NewNote("Created Twice", "v1")
+	if err := fs.SaveNote(ctx, note); err != nil {
+		t.Fatalf("SaveNote() failed: %v", err)
+	}
+
+	created := note.CreatedAt
+	time.Sleep(10 * time.Millisecond)
+
+	// Load and save again, as the edit flow does
+	loaded, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	loaded.Content = "v2"
+	if err := fs.SaveNote(ctx, loaded); err != nil {
+		t.Fatalf("SaveNote() failed: %v", err)
+	}
+
+	reloaded, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if !reloaded.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt changed on save: expected %v, got %v", created, reloaded.CreatedAt)
+	}
+	if !reloaded.UpdatedAt.After(created) {
+		t.Errorf("UpdatedAt should move forward: created %v, updated %v", created, reloaded.UpdatedAt)
+	}
+}
+
+func TestParseNote_WithoutFrontmatter(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	ctx := context.Background()
+
+	// A legacy note written before frontmatter support
+	id := "legacy-note-without-frontmatter"
+	filePath := filepath.Join(fs.NotesDir(), id+".md")
+	if err := os.WriteFile(filePath, []byte("# Legacy\n\nOld content\n---\nnot: frontmatter\n"), 0644); err != nil {
+		t.Fatalf("could not write legacy note: %v", err)
+	}
+
+	note, err := fs.GetNote(ctx, id)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("could not stat legacy note: %v", err)
+	}
+
+	if note.Title != "Legacy" {
+		t.Errorf("title mismatch: expected %q, got %q", "Legacy", note.Title)
+	}
+	if note.Content != "Old content\n---\nnot: frontmatter" {
+		t.Errorf("content mismatch: got %q", note.Content)
+	}
+	if !note.CreatedAt.Equal(info.ModTime()) {
+		t.Errorf("CreatedAt should fall back to the file time, got %v", note.CreatedAt)
+	}
+	if note.Metadata != nil || note.Tags != nil {
+		t.Errorf("legacy note should have no metadata, got %v / %v", note.Metadata, note.Tags)
+	}
+}
+
+func TestParseNote_FrontmatterWithoutHeading(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	ctx := context.Background()
+
+	// A note authored in another tool: title only in the frontmatter
+	id := "frontmatter-only-note"
+	filePath := filepath.Join(fs.NotesDir(), id+".md")
+	raw := "---\ntitle: From Obsidian\ncreated: 2023-05-04T10:00:00Z\ntags: [a, b]\n---\nJust a body\n"
+	if err := os.WriteFile(filePath, []byte(raw), 0644); err != nil {
+		t.Fatalf("could not write note: %v", err)
+	}
+
+	note, err := fs.GetNote(ctx, id)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+
+	if note.Title != "From Obsidian" {
+		t.Errorf("title mismatch: got %q", note.Title)
+	}
+	if note.Content != "Just a body\n" {
+		t.Errorf("content mismatch: got %q", note.Content)
+	}
+	if note.CreatedAt.Year() != 2023 {
+		t.Errorf("CreatedAt should come from frontmatter, got %v", note.CreatedAt)
+	}
+	if len(note.Tags) != 2 {
+		t.Errorf("tags mismatch: got %v", note.Tags)
+	}
+}
+
+func TestParseNote_HeadingEditedOnDisk(t *testing.T) {
+	fs := newTestFileSystem(t)
+
+	ctx := context.Background()
+
+	note := storage.NewNote("Second", "Body")
+	if err := fs.SaveNote(ctx, note); err != nil {
+		t.Fatalf("SaveNote() failed: %v", err)
+	}
+
+	// Rename the heading in another editor, leaving the frontmatter title behind
+	data, err := os.ReadFile(note.FilePath)
+	if err != nil {
+		t.Fatalf("could not read note: %v", err)
+	}
+	edited := strings.Replace(string(data), "# Second\n", "# Renamed heading\n", 1)
+	if err := os.WriteFile(note.FilePath, []byte(edited), 0644); err != nil {
+		t.Fatalf("could not write note: %v", err)
+	}
+
+	loaded, err := fs.GetNote(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if loaded.Title != "Renamed heading" {
+		t.Fatalf("the heading should win over the frontmatter title, got %q", loaded.Title)
+	}
+
+	// Saving again keeps the new title in both places
+	if err := fs.SaveNote(ctx, loaded); err != nil {
+		t.Fatalf("SaveNote() failed: %v", err)
+	}
+	data, err = os.ReadFile(loaded.FilePath)
+	if err != nil {
+		t.Fatalf("could not read note: %v", err)
+	}
+	if !strings.Contains(string(data), "title: Renamed heading\n") || !strings.Contains(string(data), "# Renamed heading\n") {
+		t.Errorf("the saved file should carry the new title, got:\n%s", data)
+	}
+}
@@ -0,0 +1,297 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// newGitFileSystem creates a git vault in a temporary directory
+// Tests are skipped where git is not installed
+func newGitFileSystem(t *testing.T, opts ...storage.GitOption) *storage.GitFileSystem {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	local := newTestFileSystem(t)
+	fs, err := storage.NewGitFileSystem(local, opts...)
+	if err != nil {
+		t.Fatalf("NewGitFileSystem() failed: %v", err)
+	}
+	t.Cleanup(func() { fs.Close() })
+	return fs
+}
+
+// gitLog returns the subjects of the commits of the vault, most recent first
+func gitLog(t *testing.T, fs *storage.GitFileSystem, args ...string) []string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", fs.NotesDir(), "log", "--format=%B%x00"}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var messages []string
+	for _, message := range strings.Split(string(out), "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func TestGitFileSystem_CommitsChanges(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(0))
+	ctx := context.Background()
+
+	note := storage.NewNote("Groceries", "Milk")
+	saveNotes(t, fs, note)
+	note.Content = "Milk\nEggs"
+	saveNotes(t, fs, note)
+	note.Title = "Shopping"
+	saveNotes(t, fs, note)
+	if err := fs.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+
+	want := []string{
+		"Delete note: Shopping",
+		"Rename note: Groceries -> Shopping",
+		"Update note: Groceries",
+		"Add note: Groceries",
+	}
+	if got := gitLog(t, fs); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected commits:\n got %q\nwant %q", got, want)
+	}
+
+	// The index, the history and the trash stay out of the repository
+	out, err := exec.Command("git", "-C", fs.NotesDir(), "status", "--porcelain", "--untracked-files=all").Output()
+	if err != nil {
+		t.Fatalf("git status failed: %v", err)
+	}
+	if len(out) != 0 {
+		t.Errorf("the working tree should be clean, got:\n%s", out)
+	}
+}
+
+func TestGitFileSystem_BatchesRapidEdits(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(time.Hour))
+
+	first := storage.NewNote("First", "1")
+	second := storage.NewNote("Second", "2")
+	saveNotes(t, fs, first, second)
+	first.Content = "one"
+	saveNotes(t, fs, first)
+
+	if got := gitLog(t, fs); len(got) != 0 {
+		t.Fatalf("nothing should be committed before the delay, got %q", got)
+	}
+	if err := fs.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	got := gitLog(t, fs)
+	if len(got) != 1 {
+		t.Fatalf("the edits should share one commit, got %q", got)
+	}
+	want := "Update 2 notes\n\n- Add note: First\n- Add note: Second"
+	if got[0] != want {
+		t.Errorf("unexpected message:\n got %q\nwant %q", got[0], want)
+	}
+}
+
+func TestGitFileSystem_SkipsNetZeroChanges(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(time.Hour))
+	ctx := context.Background()
+
+	note := storage.NewNote("Scratch", "")
+	saveNotes(t, fs, note)
+	if err := fs.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+	if err := fs.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	if got := gitLog(t, fs); len(got) != 0 {
+		t.Errorf("a note created and deleted before committing leaves no commit, got %q", got)
+	}
+}
+
+func TestGitFileSystem_LeavesStagedFilesAlone(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(0))
+
+	other := filepath.Join(fs.NotesDir(), "README.txt")
+	if err := os.WriteFile(other, []byte("mine"), 0644); err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+	if err := exec.Command("git", "-C", fs.NotesDir(), "add", "README.txt").Run(); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+
+	saveNotes(t, fs, storage.NewNote("Note", ""))
+
+	out, err := exec.Command("git", "-C", fs.NotesDir(), "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		t.Fatalf("git diff failed: %v", err)
+	}
+	if strings.TrimSpace(string(out)) != "README.txt" {
+		t.Errorf("the file staged by the user should stay staged, got %q", out)
+	}
+}
+
+func TestGitFileSystem_LogAndBlame(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(time.Hour))
+	ctx := context.Background()
+
+	note := storage.NewNote("Plan", "step one\n")
+	saveNotes(t, fs, note)
+	if err := fs.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	note.Content = "step one\nstep two\n"
+	saveNotes(t, fs, note)
+
+	// Pending changes are committed first
+	commits, err := fs.Log(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("Log() failed: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	if commits[0].Subject != "Update note: Plan" || commits[1].Subject != "Add note: Plan" {
+		t.Errorf("commits should be listed most recent first, got %q and %q", commits[0].Subject, commits[1].Subject)
+	}
+	if len(commits[0].Hash) != 40 || commits[0].Date.IsZero() || commits[0].Author == "" {
+		t.Errorf("commit details should be filled, got %+v", commits[0])
+	}
+
+	lines, err := fs.Blame(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("Blame() failed: %v", err)
+	}
+	var stepOne, stepTwo *storage.BlameLine
+	for _, line := range lines {
+		switch line.Text {
+		case "step one":
+			stepOne = line
+		case "step two":
+			stepTwo = line
+		}
+	}
+	if stepOne == nil || stepTwo == nil {
+		t.Fatalf("every line of the note should be blamed, got %d lines", len(lines))
+	}
+	if stepOne.Commit == nil || stepOne.Commit.Hash != commits[1].Hash {
+		t.Errorf("the first step should come from the first commit, got %+v", stepOne.Commit)
+	}
+	if stepTwo.Commit == nil || stepTwo.Commit.Hash != commits[0].Hash {
+		t.Errorf("the second step should come from the second commit, got %+v", stepTwo.Commit)
+	}
+
+	if _, err := fs.Log(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("logging a missing note should be ErrNotFound, got %v", err)
+	}
+}
+
+func TestGitFileSystem_RestoreCommits(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(0))
+	ctx := context.Background()
+
+	note := storage.NewNote("Back", "again")
+	saveNotes(t, fs, note)
+	if err := fs.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+	trashed, err := fs.ListTrash(ctx)
+	if err != nil || len(trashed) != 1 {
+		t.Fatalf("ListTrash() = %v, %v", trashed, err)
+	}
+	if _, err := fs.RestoreNote(ctx, trashed[0].TrashID); err != nil {
+		t.Fatalf("RestoreNote() failed: %v", err)
+	}
+
+	if got := gitLog(t, fs, "-1"); len(got) != 1 || got[0] != "Restore note: Back" {
+		t.Errorf("restoring should be committed, got %q", got)
+	}
+}
+
+func TestGitFileSystem_CommitsMoves(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(0))
+	ctx := context.Background()
+
+	note := storage.NewNote("Plan", "the plan")
+	saveNotes(t, fs, note)
+	if _, err := fs.MoveNote(ctx, note.ID, "work"); err != nil {
+		t.Fatalf("MoveNote() failed: %v", err)
+	}
+	if err := fs.RenameFolder(ctx, "work", "archive"); err != nil {
+		t.Fatalf("RenameFolder() failed: %v", err)
+	}
+
+	want := []string{
+		"Rename folder: work -> archive",
+		"Move note: Plan to work",
+		"Add note: Plan",
+	}
+	if got := gitLog(t, fs); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected commits:\n got %q\nwant %q", got, want)
+	}
+
+	out, err := exec.Command("git", "-C", fs.NotesDir(), "ls-files").Output()
+	if err != nil {
+		t.Fatalf("git ls-files failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "archive/"+note.ID+".md" {
+		t.Errorf("only the moved file should be tracked, got %q", got)
+	}
+
+	// The log follows the note across moves
+	commits, err := fs.Log(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("Log() failed: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Errorf("expected 3 commits for the note, got %d", len(commits))
+	}
+}
+
+func TestGitFileSystem_LiteralPaths(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(0))
+
+	plan := storage.NewNote("Plan", "Kept")
+	plan.ID = "plan"
+	glob := storage.NewNote("Pattern", "First")
+	glob.ID = "p*"
+	saveNotes(t, fs, plan, glob)
+
+	// An edit made outside leaf, which is not leaf's to commit
+	path := filepath.Join(fs.NotesDir(), "plan.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read note: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, "Edited by hand\n"...), 0644); err != nil {
+		t.Fatalf("could not edit note: %v", err)
+	}
+
+	// As a glob, p*.md would also stage and commit plan.md
+	glob.Content = "Second"
+	saveNotes(t, fs, glob)
+
+	out, err := exec.Command("git", "-C", fs.NotesDir(), "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatalf("git status failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "M plan.md" {
+		t.Errorf("the edit of plan.md should stay uncommitted, got status %q", got)
+	}
+}
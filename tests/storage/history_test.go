@@ -0,0 +1,209 @@
+package storage_test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestSaveNote_KeepsVersions(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Draft", "first")
+	saveNotes(t, fs, note)
+	note.Content = "second"
+	note.Title = "Final"
+	saveNotes(t, fs, note)
+
+	versions, err := fs.ListVersions(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("ListVersions() failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if !versions[0].SavedAt.After(versions[1].SavedAt) {
+		t.Errorf("versions should be listed most recent first")
+	}
+
+	oldest, err := fs.GetVersion(ctx, note.ID, versions[1].ID)
+	if err != nil {
+		t.Fatalf("GetVersion() failed: %v", err)
+	}
+	if oldest.Title != "Draft" || oldest.Content != "first" {
+		t.Errorf("the first version should be kept as saved, got %q %q", oldest.Title, oldest.Content)
+	}
+	if oldest.Revision != "" {
+		t.Errorf("a version should have no revision, got %q", oldest.Revision)
+	}
+
+	// Versions are not notes
+	notes, err := fs.ListNotes(ctx)
+	if err != nil {
+		t.Fatalf("ListNotes() failed: %v", err)
+	}
+	if len(notes) != 1 {
+		t.Errorf("versions should not be listed as notes, got %d notes", len(notes))
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	t.Run("should keep at most the configured number of versions", func(t *testing.T) {
+		fs, err := storage.NewLocalFileSystemAt(t.TempDir(), storage.WithHistoryRetention(2, 0))
+		if err != nil {
+			t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+		}
+
+		note := storage.NewNote("Counter", "0")
+		for _, content := range []string{"1", "2", "3"} {
+			note.Content = content
+			saveNotes(t, fs, note)
+		}
+
+		versions, err := fs.ListVersions(context.Background(), note.ID)
+		if err != nil {
+			t.Fatalf("ListVersions() failed: %v", err)
+		}
+		if len(versions) != 2 {
+			t.Fatalf("expected 2 versions, got %d", len(versions))
+		}
+		latest, err := fs.GetVersion(context.Background(), note.ID, versions[0].ID)
+		if err != nil {
+			t.Fatalf("GetVersion() failed: %v", err)
+		}
+		if latest.Content != "3" {
+			t.Errorf("the latest version should be kept, got %q", latest.Content)
+		}
+	})
+
+	t.Run("should drop versions older than the configured age", func(t *testing.T) {
+		dir := t.TempDir()
+		fs, err := storage.NewLocalFileSystemAt(dir, storage.WithHistoryRetention(0, time.Hour))
+		if err != nil {
+			t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+		}
+
+		note := storage.NewNote("Old", "old")
+		saveNotes(t, fs, note)
+
+		// Make the first version look two hours old
+		historyDir := filepath.Join(dir, ".leaf", "history", note.ID)
+		versions, _ := fs.ListVersions(context.Background(), note.ID)
+		old := strconv.FormatInt(time.Now().Add(-2*time.Hour).UnixNano(), 10)
+		if err := os.Rename(filepath.Join(historyDir, versions[0].ID+".md.gz"), filepath.Join(historyDir, old+".md.gz")); err != nil {
+			t.Fatalf("could not age the version: %v", err)
+		}
+
+		note.Content = "new"
+		saveNotes(t, fs, note)
+
+		versions, err = fs.ListVersions(context.Background(), note.ID)
+		if err != nil {
+			t.Fatalf("ListVersions() failed: %v", err)
+		}
+		if len(versions) != 1 || versions[0].ID == old {
+			t.Errorf("only the new version should be kept, got %+v", versions)
+		}
+	})
+}
+
+func TestGetVersion_Errors(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Note", "content")
+	saveNotes(t, fs, note)
+
+	if _, err := fs.GetVersion(ctx, note.ID, "123"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("an unknown version should be ErrNotFound, got %v", err)
+	}
+	if _, err := fs.GetVersion(ctx, note.ID, "../../x"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("a malformed version ID should be ErrNotFound, got %v", err)
+	}
+	if _, err := fs.ListVersions(ctx, "../escape"); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("an invalid note ID should be ErrInvalidID, got %v", err)
+	}
+
+	// A snapshot that is not gzip is corrupt
+	versions, _ := fs.ListVersions(ctx, note.ID)
+	path := filepath.Join(filepath.Dir(note.FilePath), ".leaf", "history", note.ID, versions[0].ID+".md.gz")
+	if err := os.WriteFile(path, []byte("not gzip"), 0644); err != nil {
+		t.Fatalf("could not corrupt the version: %v", err)
+	}
+	if _, err := fs.GetVersion(ctx, note.ID, versions[0].ID); !errors.Is(err, storage.ErrCorrupt) {
+		t.Errorf("a broken snapshot should be ErrCorrupt, got %v", err)
+	}
+
+	// The snapshots are plain gzip
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("could not rewrite the version: %v", err)
+	}
+	zw := gzip.NewWriter(file)
+	zw.Write([]byte("---\ntitle: Rewritten\n---\nbody"))
+	zw.Close()
+	file.Close()
+	rewritten, err := fs.GetVersion(ctx, note.ID, versions[0].ID)
+	if err != nil {
+		t.Fatalf("GetVersion() failed: %v", err)
+	}
+	if rewritten.Title != "Rewritten" {
+		t.Errorf("the snapshot should be read as a note file, got %q", rewritten.Title)
+	}
+}
+
+func TestPurgeNote_RemovesHistory(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Gone", "soon")
+	saveNotes(t, fs, note)
+	if err := fs.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+
+	// Trashed notes keep their history, so it comes back with them
+	if versions, _ := fs.ListVersions(ctx, note.ID); len(versions) != 1 {
+		t.Fatalf("a trashed note should keep its versions, got %d", len(versions))
+	}
+
+	if err := fs.EmptyTrash(ctx); err != nil {
+		t.Fatalf("EmptyTrash() failed: %v", err)
+	}
+	if versions, _ := fs.ListVersions(ctx, note.ID); len(versions) != 0 {
+		t.Errorf("a purged note should lose its versions, got %d", len(versions))
+	}
+}
+
+func TestRecover_History(t *testing.T) {
+	fs := newTestFileSystem(t)
+	note := storage.NewNote("Plan", "v1")
+	saveNotes(t, fs, note)
+
+	dir := filepath.Join(fs.NotesDir(), ".leaf", "history", note.ID)
+	stale := writeTempFile(t, filepath.Join(dir, ".1700000000000000000.md.gz.tmp-9"), time.Hour)
+
+	removed, err := fs.Recover()
+	if err != nil {
+		t.Fatalf("Recover() failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != stale {
+		t.Errorf("expected %s to be removed, got %v", stale, removed)
+	}
+
+	versions, err := fs.ListVersions(context.Background(), note.ID)
+	if err != nil {
+		t.Fatalf("ListVersions() failed: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("the snapshot itself should be kept, got %d versions", len(versions))
+	}
+}
@@ -0,0 +1,224 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// saveNotes saves every note or fails the test
+func saveNotes(t *testing.T, fs storage.FileSystem, notes ...*storage.Note) {
+	t.Helper()
+
+	for _, note := range notes {
+		if err := fs.SaveNote(context.Background(), note); err != nil {
+			t.Fatalf("SaveNote() failed: %v", err)
+		}
+	}
+}
+
+// resultIDs returns the IDs of search results in order
+func resultIDs(notes []*storage.Note) []string {
+	ids := make([]string, len(notes))
+	for i, note := range notes {
+		ids[i] = note.ID
+	}
+	return ids
+}
+
+func TestSearchNotes_RankedByBM25(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	// The note mentioning "raft" most densely should come first,
+	// whatever the save order
+	dense := storage.NewNote("Raft consensus", "Raft leader election. Raft log replication.")
+	sparse := storage.NewNote("Distributed systems", "Paxos, Raft, Zab and many other protocols are compared in this long note about consensus")
+	other := storage.NewNote("Groceries", "Milk and eggs")
+	saveNotes(t, fs, dense, sparse, other)
+
+	results, err := fs.SearchNotes(ctx, "raft")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+
+	ids := resultIDs(results)
+	if len(ids) != 2 || ids[0] != dense.ID || ids[1] != sparse.ID {
+		t.Errorf("unexpected ranking: got %v, want [%s %s]", ids, dense.ID, sparse.ID)
+	}
+}
+
+func TestSearchNotes_AllTermsAndPrefixes(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	both := storage.NewNote("Kubernetes deployment", "Rolling updates")
+	one := storage.NewNote("Kubernetes basics", "Pods and services")
+	saveNotes(t, fs, both, one)
+
+	results, err := fs.SearchNotes(ctx, "kube roll")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+
+	if ids := resultIDs(results); len(ids) != 1 || ids[0] != both.ID {
+		t.Errorf("every term should match as a prefix: got %v", ids)
+	}
+}
+
+func TestSearchNotes_UnicodeNormalization(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Café crème", "Rendez-vous à l'ÉCOLE")
+	saveNotes(t, fs, note)
+
+	for _, query := range []string{"cafe", "CREME", "ecole", "école"} {
+		results, err := fs.SearchNotes(ctx, query)
+		if err != nil {
+			t.Fatalf("SearchNotes(%q) failed: %v", query, err)
+		}
+		if len(results) != 1 {
+			t.Errorf("SearchNotes(%q) should ignore case and accents, got %d results", query, len(results))
+		}
+	}
+}
+
+func TestSearchNotes_IndexPersistedAndUpdated(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	fs, err := storage.NewLocalFileSystemAt(dir)
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+
+	kept := storage.NewNote("Kept", "persistent index")
+	removed := storage.NewNote("Removed", "persistent index")
+	saveNotes(t, fs, kept, removed)
+
+	if err := fs.DeleteNote(ctx, removed.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+
+	// The index lives inside the vault, and is written in batches rather
+	// than on every save
+	indexPath := filepath.Join(dir, ".leaf", "index.gob")
+	if _, err := os.Stat(indexPath); !os.IsNotExist(err) {
+		t.Errorf("index file should not be written on every save: %v", err)
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if _, err := os.Stat(indexPath); err != nil {
+		t.Fatalf("index file should exist once closed: %v", err)
+	}
+
+	// A fresh instance reads the persisted index
+	reopened, err := storage.NewLocalFileSystemAt(dir)
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+
+	results, err := reopened.SearchNotes(ctx, "persistent")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if ids := resultIDs(results); len(ids) != 1 || ids[0] != kept.ID {
+		t.Errorf("deleted notes should leave the index: got %v", ids)
+	}
+}
+
+func TestSearchNotes_RebuildsStaleIndex(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Original", "alpha")
+	saveNotes(t, fs, note)
+
+	// Warm the index, then change the file behind its back
+	if _, err := fs.SearchNotes(ctx, "alpha"); err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if err := os.WriteFile(note.FilePath, []byte("# Original\n\nbeta gamma"), 0644); err != nil {
+		t.Fatalf("could not rewrite note: %v", err)
+	}
+	external := filepath.Join(fs.NotesDir(), "external.md")
+	if err := os.WriteFile(external, []byte("# External\n\nbeta"), 0644); err != nil {
+		t.Fatalf("could not write note: %v", err)
+	}
+
+	// Searching doesn't walk the vault again until told about the changes
+	results, err := fs.SearchNotes(ctx, "beta")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("unannounced changes should not be indexed yet, got %v", resultIDs(results))
+	}
+	fs.Invalidate(note.FilePath)
+	fs.Invalidate(external)
+
+	results, err = fs.SearchNotes(ctx, "alpha")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("stale terms should be dropped, got %v", resultIDs(results))
+	}
+
+	results, err = fs.SearchNotes(ctx, "beta")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("changed and new files should be indexed, got %v", resultIDs(results))
+	}
+
+	// A corrupt index file is discarded and rebuilt
+	if err := os.WriteFile(filepath.Join(fs.NotesDir(), ".leaf", "index.gob"), []byte("garbage"), 0644); err != nil {
+		t.Fatalf("could not corrupt index: %v", err)
+	}
+	reopened, err := storage.NewLocalFileSystemAt(fs.NotesDir())
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+	results, err = reopened.SearchNotes(ctx, "gamma")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("corrupt index should be rebuilt, got %v", resultIDs(results))
+	}
+}
+
+func TestSearchNotes_InvalidateEverything(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	saveNotes(t, fs, storage.NewNote("Inbox", "alpha"))
+	if _, err := fs.SearchNotes(ctx, "alpha"); err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+
+	// A folder dropped in at once, as a watcher that lost events reports it
+	dir := filepath.Join(fs.NotesDir(), "imported")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("could not create folder: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "old.md"), []byte("# Old\n\nalpha"), 0644); err != nil {
+		t.Fatalf("could not write note: %v", err)
+	}
+	fs.Invalidate("")
+
+	results, err := fs.SearchNotes(ctx, "alpha")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("every note file should be checked again, got %v", resultIDs(results))
+	}
+}
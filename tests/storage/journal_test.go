@@ -0,0 +1,73 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestJournalDay(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+
+	if id := storage.JournalID(day.Add(15 * time.Hour)); id != "2024-05-01" {
+		t.Errorf("JournalID() = %q, want 2024-05-01", id)
+	}
+	if got, ok := storage.JournalDay("2024-05-01"); !ok || !got.Equal(day) {
+		t.Errorf("JournalDay() = %v, %v, want %v", got, ok, day)
+	}
+	for _, id := range []string{"", "Meeting", "2024-5-1", "2024-05-01-standup"} {
+		if _, ok := storage.JournalDay(id); ok {
+			t.Errorf("JournalDay(%q) should not name a journal entry", id)
+		}
+	}
+}
+
+func TestJournal_Open(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+	writeTemplate(t, fs, "journal", "---\ntitle: \"{{.Now.Format \"Monday, January 2\"}}\"\ntags: [journal]\n---\n# {{.Date}}\n\n{{cursor}}\n")
+	journal := storage.Journal{Template: "journal", Folder: "journal"}
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+
+	note, err := journal.Open(ctx, fs, day)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if note.ID != "2024-05-01" || note.Title != "Wednesday, May 1" || note.Folder != "journal" {
+		t.Errorf("Open() = %q %q in %q, want the entry of the day", note.ID, note.Title, note.Folder)
+	}
+	if note.Content != "# 2024-05-01\n\n\n" {
+		t.Errorf("content = %q, want the template rendered for the day", note.Content)
+	}
+
+	// Opening it again finds the saved entry
+	note.Content = "Shipped"
+	if err := fs.SaveNote(ctx, note); err != nil {
+		t.Fatalf("SaveNote() failed: %v", err)
+	}
+	again, err := journal.Open(ctx, fs, day.Add(20*time.Hour))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if again.Content != "Shipped" {
+		t.Errorf("Open() should return the existing entry, got %q", again.Content)
+	}
+}
+
+func TestJournal_OpenWithoutTemplate(t *testing.T) {
+	fs := newTestFileSystem(t)
+	journal := storage.Journal{Template: storage.DefaultJournalTemplate}
+
+	note, err := journal.Open(context.Background(), fs, time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if note.ID != "2024-02-29" || note.Title != "2024-02-29" || note.Content != "" {
+		t.Errorf("Open() = %q %q %q, want an empty entry named after the day", note.ID, note.Title, note.Content)
+	}
+	if _, err := fs.GetNote(context.Background(), "2024-02-29"); err != nil {
+		t.Errorf("the entry should be saved: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestParseLinks(t *testing.T) {
+	content := "See [[Plan]] and [[abc-123|the budget]].\n`[[not a link]]`\n```\n[[code]]\n```\n[[ Trip ]] [[]]"
+	got := storage.ParseLinks(content)
+
+	want := []struct{ target, text string }{
+		{"Plan", "Plan"},
+		{"abc-123", "the budget"},
+		{"Trip", "Trip"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseLinks() = %+v, want %d links", got, len(want))
+	}
+	for i, link := range got {
+		if link.Target != want[i].target || link.Text() != want[i].text {
+			t.Errorf("link %d = %q shown as %q, want %q shown as %q", i, link.Target, link.Text(), want[i].target, want[i].text)
+		}
+	}
+	if raw := content[got[1].Start:got[1].End]; raw != "[[abc-123|the budget]]" {
+		t.Errorf("offsets should cover the link, got %q", raw)
+	}
+}
+
+func TestResolveLinkAndBacklinks(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	plan := storage.NewNote("Project Plan", "the plan, see [[Project Plan]]")
+	plan.Aliases = []string{"roadmap"}
+	byTitle := storage.NewNote("Meeting", "we discussed the [[project plan]]")
+	byAlias := storage.NewNote("Ideas", "put it on the [[Roadmap|road map]]")
+	byID := storage.NewNote("Budget", "costs for [["+plan.ID+"]]")
+	other := storage.NewNote("Trip", "nothing to see, [[Missing]]")
+	saveNotes(t, fs, plan, byTitle, byAlias, byID, other)
+
+	for _, target := range []string{plan.ID, "project plan", "ROADMAP"} {
+		note, err := fs.ResolveLink(ctx, target)
+		if err != nil {
+			t.Errorf("ResolveLink(%q) failed: %v", target, err)
+			continue
+		}
+		if note.ID != plan.ID {
+			t.Errorf("ResolveLink(%q) = %q, want the plan", target, note.Title)
+		}
+	}
+	if _, err := fs.ResolveLink(ctx, "Missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("a broken link should fail with ErrNotFound, got %v", err)
+	}
+
+	backlinks, err := fs.Backlinks(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("Backlinks() failed: %v", err)
+	}
+	if got, want := resultIDs(backlinks), []string{byID.ID, byAlias.ID, byTitle.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Backlinks() = %v, want %v sorted by title, without the note itself", got, want)
+	}
+
+	// The graph follows the notes as they are saved
+	other.Content = "see the [[Project Plan]]"
+	byTitle.Content = "no more links"
+	saveNotes(t, fs, other, byTitle)
+	backlinks, err = fs.Backlinks(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("Backlinks() failed: %v", err)
+	}
+	if got, want := resultIDs(backlinks), []string{byID.ID, byAlias.ID, other.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Backlinks() after saving = %v, want %v", got, want)
+	}
+}
+
+func TestSaveNote_RenameRewritesLinks(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	plan := storage.NewNote("Plan", "the plan")
+	meeting := storage.NewNote("Meeting", "see [[plan]], the [[Plan|big plan]] and `[[Plan]]`")
+	budget := storage.NewNote("Budget", "by ID: [["+plan.ID+"]]")
+	saveNotes(t, fs, plan, meeting, budget)
+
+	plan.Title = "Roadmap"
+	saveNotes(t, fs, plan)
+
+	got, err := fs.GetNote(ctx, meeting.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if want := "see [[Roadmap]], the [[Roadmap|big plan]] and `[[Plan]]`"; got.Content != want {
+		t.Errorf("links should follow the rename:\n got %q\nwant %q", got.Content, want)
+	}
+	got, err = fs.GetNote(ctx, budget.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if !strings.Contains(got.Content, "[["+plan.ID+"]]") {
+		t.Errorf("links by ID should be left alone, got %q", got.Content)
+	}
+
+	// A title still naming another note keeps its links
+	other := storage.NewNote("Roadmap", "an older roadmap")
+	saveNotes(t, fs, other)
+	plan.Title = "Strategy"
+	saveNotes(t, fs, plan)
+	got, err = fs.GetNote(ctx, meeting.ID)
+	if err != nil {
+		t.Fatalf("GetNote() failed: %v", err)
+	}
+	if !strings.Contains(got.Content, "[[Roadmap]]") {
+		t.Errorf("links to a title another note has should not change, got %q", got.Content)
+	}
+}
+
+func TestGitFileSystem_CommitsRewrittenLinks(t *testing.T) {
+	fs := newGitFileSystem(t, storage.WithCommitDelay(0))
+
+	plan := storage.NewNote("Plan", "the plan")
+	meeting := storage.NewNote("Meeting", "see [[Plan]]")
+	saveNotes(t, fs, plan, meeting)
+	plan.Title = "Roadmap"
+	saveNotes(t, fs, plan)
+
+	want := []string{
+		"Update 2 notes\n\n- Rename note: Plan -> Roadmap\n- Update note: Meeting",
+		"Add note: Meeting",
+		"Add note: Plan",
+	}
+	if got := gitLog(t, fs); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected commits:\n got %q\nwant %q", got, want)
+	}
+
+	// Nothing is left behind for a later commit
+	out, err := exec.Command("git", "-C", fs.NotesDir(), "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatalf("git status failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "" {
+		t.Errorf("the rename should leave the repository clean, got status %q", got)
+	}
+}
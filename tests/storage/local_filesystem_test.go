@@ -3,6 +3,7 @@ package storage_test
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -28,11 +29,42 @@ func TestNewLocalFileSystem(t *testing.T) {
 	t.Logf("Notes directory created: %s", fs.NotesDir())
 }
 
-func TestSaveAndGetNote(t *testing.T) {
-	fs, err := storage.NewLocalFileSystem()
+func TestNewLocalFileSystemAt(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "vaults", "work")
+
+	fs, err := storage.NewLocalFileSystemAt(dir)
 	if err != nil {
-		t.Fatalf("NewLocalFileSystem() failed: %v", err)
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+
+	if fs.NotesDir() != dir {
+		t.Errorf("notes directory mismatch: expected %q, got %q", dir, fs.NotesDir())
+	}
+
+	// Verify that the directory exists
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		t.Fatalf("notes directory was not created: %s", dir)
+	}
+
+	// An empty path is rejected
+	if _, err := storage.NewLocalFileSystemAt(""); err == nil {
+		t.Error("NewLocalFileSystemAt(\"\") should return an error")
 	}
+}
+
+// newTestFileSystem creates a LocalFileSystem in a temporary directory
+func newTestFileSystem(t *testing.T) *storage.LocalFileSystem {
+	t.Helper()
+
+	fs, err := storage.NewLocalFileSystemAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+	return fs
+}
+
+func TestSaveAndGetNote(t *testing.T) {
+	fs := newTestFileSystem(t)
 
 	ctx := context.Background()
 
@@ -41,7 +73,7 @@ func TestSaveAndGetNote(t *testing.T) {
 	originalID := note.ID
 
 	// Save the note
-	err = fs.SaveNote(ctx, note)
+	err := fs.SaveNote(ctx, note)
 	if err != nil {
 		t.Fatalf("SaveNote() failed: %v", err)
 	}
@@ -70,10 +102,7 @@ func TestSaveAndGetNote(t *testing.T) {
 }
 
 func TestListNotes(t *testing.T) {
-	fs, err := storage.NewLocalFileSystem()
-	if err != nil {
-		t.Fatalf("NewLocalFileSystem() failed: %v", err)
-	}
+	fs := newTestFileSystem(t)
 
 	ctx := context.Background()
 
@@ -119,10 +148,7 @@ func TestListNotes(t *testing.T) {
 }
 
 func TestSearchNotes(t *testing.T) {
-	fs, err := storage.NewLocalFileSystem()
-	if err != nil {
-		t.Fatalf("NewLocalFileSystem() failed: %v", err)
-	}
+	fs := newTestFileSystem(t)
 
 	ctx := context.Background()
 
@@ -168,10 +194,7 @@ func TestSearchNotes(t *testing.T) {
 }
 
 func TestDeleteNote(t *testing.T) {
-	fs, err := storage.NewLocalFileSystem()
-	if err != nil {
-		t.Fatalf("NewLocalFileSystem() failed: %v", err)
-	}
+	fs := newTestFileSystem(t)
 
 	ctx := context.Background()
 
@@ -197,7 +220,7 @@ func TestDeleteNote(t *testing.T) {
 	}
 
 	// Try to delete a non-existent note
-	err = fs.DeleteNote(ctx, "non-existent-note")
+	err := fs.DeleteNote(ctx, "non-existent-note")
 	if err == nil {
 		t.Error("DeleteNote() should return an error for a non-existent note")
 	}
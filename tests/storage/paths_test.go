@@ -0,0 +1,150 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// hostileIDs try to reach files outside the notes directory
+var hostileIDs = []string{
+	"..",
+	".",
+	"../secret",
+	"../../.bashrc",
+	"/etc/passwd",
+	`..\secret`,
+	"sub/../../secret",
+	".hidden",
+	"nul\x00byte",
+	"line\nbreak",
+	strings.Repeat("a", 300),
+}
+
+// newSandbox creates a vault next to a file that must never be touched
+func newSandbox(t testing.TB) (fs *storage.LocalFileSystem, secret string) {
+	parent := t.TempDir()
+	secret = filepath.Join(parent, "secret.md")
+	if err := os.WriteFile(secret, []byte("# Secret\n\nDo not touch"), 0644); err != nil {
+		t.Fatalf("could not write %s: %v", secret, err)
+	}
+
+	fs, err := storage.NewLocalFileSystemAt(filepath.Join(parent, "vault"))
+	if err != nil {
+		t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+	}
+	return fs, secret
+}
+
+// checkSandbox fails if anything outside the vault changed
+func checkSandbox(t testing.TB, fs *storage.LocalFileSystem, secret string) {
+	t.Helper()
+
+	data, err := os.ReadFile(secret)
+	if err != nil || string(data) != "# Secret\n\nDo not touch" {
+		t.Fatalf("the file outside the vault was changed (%v)", err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(secret))
+	if err != nil {
+		t.Fatalf("could not read %s: %v", filepath.Dir(secret), err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("files were created outside the vault: %v", entries)
+	}
+}
+
+func TestNoteIDs_RejectTraversal(t *testing.T) {
+	fs, secret := newSandbox(t)
+	ctx := context.Background()
+
+	for _, id := range hostileIDs {
+		if _, err := fs.GetNote(ctx, id); !errors.Is(err, storage.ErrInvalidID) {
+			t.Errorf("GetNote(%q): expected ErrInvalidID, got %v", id, err)
+		}
+		if err := fs.SaveNote(ctx, &storage.Note{ID: id, Title: "Evil"}); !errors.Is(err, storage.ErrInvalidID) {
+			t.Errorf("SaveNote(%q): expected ErrInvalidID, got %v", id, err)
+		}
+		if err := fs.DeleteNote(ctx, id); !errors.Is(err, storage.ErrInvalidID) {
+			t.Errorf("DeleteNote(%q): expected ErrInvalidID, got %v", id, err)
+		}
+	}
+
+	checkSandbox(t, fs, secret)
+}
+
+func TestNoteIDs_RejectSymlinksOutOfTheVault(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links need privileges on windows")
+	}
+
+	fs, secret := newSandbox(t)
+	ctx := context.Background()
+	if err := os.Symlink(secret, filepath.Join(fs.NotesDir(), "link.md")); err != nil {
+		t.Fatalf("could not create link: %v", err)
+	}
+
+	if _, err := fs.GetNote(ctx, "link"); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("GetNote: expected ErrInvalidID, got %v", err)
+	}
+	if err := fs.SaveNote(ctx, &storage.Note{ID: "link", Title: "Evil"}); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("SaveNote: expected ErrInvalidID, got %v", err)
+	}
+
+	notes, err := fs.ListNotes(ctx)
+	if err != nil {
+		t.Fatalf("ListNotes() failed: %v", err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("the link should not be listed, got %d notes", len(notes))
+	}
+
+	checkSandbox(t, fs, secret)
+}
+
+func FuzzNoteID(f *testing.F) {
+	for _, id := range hostileIDs {
+		f.Add(id)
+	}
+	f.Add("8c1f6a52-64a3-4a5e-9a43-1f0e5c2b7d90")
+	f.Add("2026-10-17-daily")
+
+	fs, secret := newSandbox(f)
+	ctx := context.Background()
+
+	f.Fuzz(func(t *testing.T, id string) {
+		note := &storage.Note{ID: id, Title: "Fuzz", Content: "content"}
+		err := fs.SaveNote(ctx, note)
+		if err != nil && !errors.Is(err, storage.ErrInvalidID) {
+			// Names the OS refuses on its own are fine, as long as nothing leaks
+			checkSandbox(t, fs, secret)
+			return
+		}
+
+		if err == nil {
+			if filepath.Dir(note.FilePath) != fs.NotesDir() {
+				t.Fatalf("note %q was written to %s", id, note.FilePath)
+			}
+			if _, err := fs.GetNote(ctx, id); err != nil {
+				t.Fatalf("GetNote(%q) failed after saving: %v", id, err)
+			}
+			if err := fs.DeleteNote(ctx, id); err != nil {
+				t.Fatalf("DeleteNote(%q) failed: %v", id, err)
+			}
+		} else {
+			if _, err := fs.GetNote(ctx, id); !errors.Is(err, storage.ErrInvalidID) {
+				t.Fatalf("GetNote(%q) accepted an ID SaveNote rejected: %v", id, err)
+			}
+			if err := fs.DeleteNote(ctx, id); !errors.Is(err, storage.ErrInvalidID) {
+				t.Fatalf("DeleteNote(%q) accepted an ID SaveNote rejected: %v", id, err)
+			}
+		}
+
+		checkSandbox(t, fs, secret)
+	})
+}
@@ -0,0 +1,226 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"go", "go"},
+		{"go rust", "go AND rust"},
+		{"go AND rust OR zig", "(go AND rust) OR zig"},
+		{"go (rust OR zig)", "go AND (rust OR zig)"},
+		{`"exact phrase" -draft`, `"exact phrase" AND NOT draft`},
+		{"NOT title:todo", "NOT title:todo"},
+		{`title:"release notes" tag:#work`, `title:"release notes" AND tag:work`},
+		{"/fo+/i", "/fo+/i"},
+		{"created:>2026-01-01 updated:<7d", "created:>2026-01-01 AND updated:<7d"},
+		{"C++ foo-bar", "C++ AND foo-bar"},
+	}
+
+	for _, tt := range tests {
+		q, err := storage.ParseQueryAt(tt.input, now)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got := q.String(); got != tt.want {
+			t.Errorf("ParseQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuery_Dates(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		input    string
+		from, to time.Time
+	}{
+		{"created:>2026-01-01", day(2026, 1, 2), time.Time{}},
+		{"created:>=2026-01-01", day(2026, 1, 1), time.Time{}},
+		{"created:<2026-01-01", time.Time{}, day(2026, 1, 1)},
+		{"created:<=2026-01-01", time.Time{}, day(2026, 1, 2)},
+		{"created:2026-01-01", day(2026, 1, 1), day(2026, 1, 2)},
+		{"updated:<7d", now.AddDate(0, 0, -7), time.Time{}},
+		{"updated:>2w", time.Time{}, now.AddDate(0, 0, -14)},
+	}
+
+	for _, tt := range tests {
+		q, err := storage.ParseQueryAt(tt.input, now)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) failed: %v", tt.input, err)
+			continue
+		}
+		dq, ok := q.(*storage.DateQuery)
+		if !ok {
+			t.Errorf("ParseQuery(%q) should return a DateQuery, got %T", tt.input, q)
+			continue
+		}
+		if !dq.From.Equal(tt.from) || !dq.To.Equal(tt.to) {
+			t.Errorf("ParseQuery(%q) range = [%v, %v), want [%v, %v)", tt.input, dq.From, dq.To, tt.from, tt.to)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		input  string
+		column int
+	}{
+		{`title:"unterminated`, 6},
+		{"foo AND", 7},
+		{"OR foo", 0},
+		{"(foo OR bar", 0},
+		{"foo)", 3},
+		{"/[a-/", 0},
+		{"/open", 0},
+		{"tag:", 4},
+		{"created:>yesterday", 9},
+		{"updated:7d", 8},
+		{"été AND", 7},
+	}
+
+	for _, tt := range tests {
+		_, err := storage.ParseQuery(tt.input)
+
+		var parseErr *storage.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("ParseQuery(%q) should return a *ParseError, got %v", tt.input, err)
+			continue
+		}
+		if parseErr.Column() != tt.column {
+			t.Errorf("ParseQuery(%q) error column = %d, want %d (%v)", tt.input, parseErr.Column(), tt.column, err)
+		}
+	}
+}
+
+func TestSearchNotes_QueryLanguage(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	release := storage.NewNote("Release notes", "Version 2 ships the new parser")
+	release.Tags = []string{"work"}
+	release.CreatedAt = time.Now().AddDate(0, 0, -40)
+
+	draft := storage.NewNote("Parser draft", "Notes about the release process")
+	draft.Tags = []string{"work", "draft"}
+
+	recipe := storage.NewNote("Pancakes", "Flour, eggs, milk. Serves 4")
+	recipe.Tags = []string{"home"}
+
+	saveNotes(t, fs, release, draft, recipe)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`"release notes"`, []string{release.ID}},
+		{`"notes release"`, nil},
+		{"title:release", []string{release.ID}},
+		{`title:"parser draft"`, []string{draft.ID}},
+		{"tag:work -tag:draft", []string{release.ID}},
+		{"tag:WORK NOT parser", nil},
+		{"pancakes OR ships", []string{recipe.ID, release.ID}},
+		{"created:<30d", []string{draft.ID, recipe.ID}},
+		{"created:>30d", []string{release.ID}},
+		{`/Serves \d+/`, []string{recipe.ID}},
+		{"/FLOUR/i", []string{recipe.ID}},
+		{"/FLOUR/", nil},
+	}
+
+	for _, tt := range tests {
+		results, err := fs.SearchNotes(ctx, tt.query)
+		if err != nil {
+			t.Errorf("SearchNotes(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if !sameIDs(resultIDs(results), tt.want) {
+			t.Errorf("SearchNotes(%q) = %v, want %v", tt.query, resultIDs(results), tt.want)
+		}
+	}
+
+	// Malformed queries are reported as parse errors
+	_, err := fs.SearchNotes(ctx, "tag:")
+	var parseErr *storage.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("SearchNotes() should return a *ParseError, got %v", err)
+	}
+}
+
+func TestSearchNotes_TitlePhraseUsesIndexPositions(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	// "notes" appears in the content but not in the title
+	note := storage.NewNote("Release", "notes")
+	saveNotes(t, fs, note)
+
+	legacy := filepath.Join(fs.NotesDir(), "legacy.md")
+	if err := os.WriteFile(legacy, []byte("# Release notes\n\nbody"), 0644); err != nil {
+		t.Fatalf("could not write note: %v", err)
+	}
+
+	results, err := fs.SearchNotes(ctx, `title:"release notes"`)
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if ids := resultIDs(results); len(ids) != 1 || ids[0] != "legacy" {
+		t.Errorf("title phrases should not span into the content: got %v", ids)
+	}
+}
+
+func TestSearchNotes_PhraseAcrossTitleAndContent(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("First note", "hello")
+	saveNotes(t, fs, note)
+
+	results, err := fs.SearchNotes(ctx, `"note hello"`)
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if ids := resultIDs(results); len(ids) != 0 {
+		t.Errorf("phrases should not span the title and the content: got %v", ids)
+	}
+
+	results, err = fs.SearchNotes(ctx, `"first note"`)
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if ids := resultIDs(results); len(ids) != 1 || ids[0] != note.ID {
+		t.Errorf("phrases in the title should still match: got %v", ids)
+	}
+}
+
+// sameIDs reports whether two ID lists hold the same elements, ignoring order
+func sameIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, id := range got {
+		seen[id]++
+	}
+	for _, id := range want {
+		seen[id]--
+		if seen[id] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package storage_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestInlineTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"words", "Call #alice about #project-x.", []string{"alice", "project-x"}},
+		{"nested", "#work/meetings and #work/", []string{"work/meetings", "work"}},
+		{"duplicates", "#Work then #work", []string{"Work"}},
+		{"headings", "# Title\n## Section\n###", nil},
+		{"numbers", "fixed in #42 and #1st", []string{"1st"}},
+		{"anchors and urls", "[see](#intro) https://example.com/page#top a#b", nil},
+		{"code", "`#notatag` #tag\n```\n#code\n```\n#after", []string{"tag", "after"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := storage.InlineTags(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InlineTags(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNote_AddAndRemoveTag(t *testing.T) {
+	note := storage.NewNote("Plan", "Ask #alice, then #Review it")
+	note.Tags = []string{"draft"}
+
+	if want := []string{"draft", "alice", "Review"}; !reflect.DeepEqual(note.AllTags(), want) {
+		t.Errorf("AllTags() = %q, want %q", note.AllTags(), want)
+	}
+	if note.AddTag("review") {
+		t.Errorf("a tag carried by a hashtag should not be added again")
+	}
+	if !note.AddTag("#work") || !reflect.DeepEqual(note.Tags, []string{"draft", "work"}) {
+		t.Errorf("the tag should be added to the frontmatter, got %q", note.Tags)
+	}
+
+	if !note.RemoveTag("review") {
+		t.Fatalf("RemoveTag() should report the change")
+	}
+	if note.Content != "Ask #alice, then Review it" {
+		t.Errorf("the hashtag should become a word, got %q", note.Content)
+	}
+	if !note.RemoveTag("DRAFT") || !reflect.DeepEqual(note.Tags, []string{"work"}) {
+		t.Errorf("the frontmatter tag should be removed, got %q", note.Tags)
+	}
+	if note.RemoveTag("missing") {
+		t.Errorf("removing a missing tag should change nothing")
+	}
+}
+
+func TestListTagsAndNotesByTag(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	a := storage.NewNote("Beta", "about #go")
+	a.Tags = []string{"Work"}
+	b := storage.NewNote("Alpha", "#work and #Go")
+	c := storage.NewNote("Gamma", "#go")
+	saveNotes(t, fs, a, b, c)
+
+	tags, err := fs.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("ListTags() failed: %v", err)
+	}
+	want := []storage.TagCount{{Tag: "go", Count: 3}, {Tag: "Work", Count: 2}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("ListTags() = %+v, want %+v", tags, want)
+	}
+
+	notes, err := fs.NotesByTag(ctx, "#WORK")
+	if err != nil {
+		t.Fatalf("NotesByTag() failed: %v", err)
+	}
+	if got := resultIDs(notes); !reflect.DeepEqual(got, []string{b.ID, a.ID}) {
+		t.Errorf("expected the tagged notes by title, got %v", got)
+	}
+
+	// Hashtags are found by tag: searches too
+	results, err := fs.SearchNotes(ctx, "tag:go -tag:work")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if got := resultIDs(results); !reflect.DeepEqual(got, []string{c.ID}) {
+		t.Errorf("expected only %s, got %v", c.ID, got)
+	}
+}
@@ -0,0 +1,130 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+// writeTemplate adds a template to the .templates folder of the vault
+func writeTemplate(t *testing.T, fs *storage.LocalFileSystem, name, content string) {
+	t.Helper()
+
+	dir := filepath.Join(fs.NotesDir(), ".templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("could not create templates folder: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(content), 0o644); err != nil {
+		t.Fatalf("could not write template: %v", err)
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl := &storage.Template{Name: "meeting", Content: "---\ntitle: Meeting {{.Date}}\ntags: [meeting]\nroom: B12\n---\n" +
+		"# {{.Title}}\n{{.Date}} {{.Time}}, by {{.User}}\n\nAttendees: {{prompt \"Attendees\"}}\n" +
+		"Agenda: {{prompt \"Agenda\"}} ({{prompt \"Attendees\"}})\n\n- {{cursor}}\n"}
+
+	fields, err := tmpl.Fields()
+	if err != nil {
+		t.Fatalf("Fields() failed: %v", err)
+	}
+	if want := []string{"Attendees", "Agenda"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Fields() = %q, want %q", fields, want)
+	}
+
+	data := storage.TemplateData{
+		Title:  "Sync",
+		User:   "sam",
+		Now:    time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		Fields: map[string]string{"Attendees": "Ana, Bo", "Agenda": "Roadmap"},
+	}
+	note, cursor, err := tmpl.Render(data)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	want := "# Sync\n2024-05-01 09:30, by sam\n\nAttendees: Ana, Bo\nAgenda: Roadmap (Ana, Bo)\n\n- \n"
+	if note.Content != want {
+		t.Errorf("content = %q, want %q", note.Content, want)
+	}
+	if cursor != len(want)-1 {
+		t.Errorf("cursor = %d, want %d", cursor, len(want)-1)
+	}
+	if note.Title != "Sync" {
+		t.Errorf("the given title should win, got %q", note.Title)
+	}
+	if !reflect.DeepEqual(note.Tags, []string{"meeting"}) || note.Metadata["room"] != "B12" {
+		t.Errorf("the frontmatter should carry over, got tags %q and metadata %v", note.Tags, note.Metadata)
+	}
+
+	data.Title = ""
+	note, _, err = tmpl.Render(data)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if note.Title != "Meeting 2024-05-01" {
+		t.Errorf("the template should give the default title, got %q", note.Title)
+	}
+}
+
+func TestTemplate_RenderWithoutCursor(t *testing.T) {
+	note, cursor, err := (&storage.Template{Name: "plain", Content: "Notes"}).Render(storage.NewTemplateData("Plain"))
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if cursor != -1 || note.Content != "Notes" || note.Title != "Plain" {
+		t.Errorf("Render() = %q, %q, %d", note.Title, note.Content, cursor)
+	}
+
+	if _, _, err := (&storage.Template{Name: "broken", Content: "{{.Title"}).Render(storage.NewTemplateData("x")); err == nil {
+		t.Errorf("a malformed template should fail to render")
+	}
+}
+
+func TestLocalFileSystem_Templates(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	templates, err := fs.ListTemplates(ctx)
+	if err != nil || len(templates) != 0 {
+		t.Fatalf("a vault without templates folder should have none, got %v, %v", templates, err)
+	}
+
+	writeTemplate(t, fs, "standup", "Yesterday:\nToday:\n")
+	writeTemplate(t, fs, "Incident", "Impact:\n")
+	saveNotes(t, fs, storage.NewNote("Real note", "text"))
+
+	templates, err = fs.ListTemplates(ctx)
+	if err != nil {
+		t.Fatalf("ListTemplates() failed: %v", err)
+	}
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	if want := []string{"Incident", "standup"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListTemplates() = %q, want %q", names, want)
+	}
+
+	tmpl, err := fs.GetTemplate(ctx, "incident")
+	if err != nil || tmpl.Content != "Impact:\n" {
+		t.Errorf("GetTemplate() should ignore case, got %v, %v", tmpl, err)
+	}
+	if _, err := fs.GetTemplate(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetTemplate() of a missing template = %v, want ErrNotFound", err)
+	}
+
+	notes, err := fs.ListNotes(ctx)
+	if err != nil {
+		t.Fatalf("ListNotes() failed: %v", err)
+	}
+	if len(notes) != 1 {
+		t.Errorf("templates should not be listed as notes, got %d notes", len(notes))
+	}
+}
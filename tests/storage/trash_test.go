@@ -0,0 +1,162 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/storage"
+)
+
+func TestDeleteNote_MovesToTrash(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Draft", "Keep me around")
+	saveNotes(t, fs, note)
+	path := note.FilePath
+
+	if err := fs.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("the note file should be gone from the notes directory")
+	}
+
+	trashed, err := fs.ListTrash(ctx)
+	if err != nil {
+		t.Fatalf("ListTrash() failed: %v", err)
+	}
+	if len(trashed) != 1 {
+		t.Fatalf("expected 1 trashed note, got %d", len(trashed))
+	}
+	if trashed[0].ID != note.ID || trashed[0].Title != "Draft" || trashed[0].OriginalPath != path {
+		t.Errorf("unexpected trash record: %+v", trashed[0])
+	}
+	if time.Since(trashed[0].DeletedAt) > time.Minute {
+		t.Errorf("deletion time should be recorded, got %v", trashed[0].DeletedAt)
+	}
+
+	restored, err := fs.RestoreNote(ctx, trashed[0].TrashID)
+	if err != nil {
+		t.Fatalf("RestoreNote() failed: %v", err)
+	}
+	if restored.ID != note.ID || restored.Content != "Keep me around" || restored.FilePath != path {
+		t.Errorf("the note should be back as it was, got %+v", restored)
+	}
+
+	// Restored notes are searchable again
+	results, err := fs.SearchNotes(ctx, "around")
+	if err != nil {
+		t.Fatalf("SearchNotes() failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("the restored note should be found, got %d results", len(results))
+	}
+
+	if trashed, _ := fs.ListTrash(ctx); len(trashed) != 0 {
+		t.Errorf("the trash should be empty after restoring, got %d notes", len(trashed))
+	}
+}
+
+func TestRestoreNote_Conflict(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	note := storage.NewNote("Twice", "First")
+	saveNotes(t, fs, note)
+	if err := fs.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("DeleteNote() failed: %v", err)
+	}
+	saveNotes(t, fs, &storage.Note{ID: note.ID, Title: "Twice", Content: "Second"})
+
+	trashed, _ := fs.ListTrash(ctx)
+	if _, err := fs.RestoreNote(ctx, trashed[0].TrashID); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestPurgeAndEmptyTrash(t *testing.T) {
+	fs := newTestFileSystem(t)
+	ctx := context.Background()
+
+	first, second := storage.NewNote("First", ""), storage.NewNote("Second", "")
+	saveNotes(t, fs, first, second)
+	for _, note := range []*storage.Note{first, second} {
+		if err := fs.DeleteNote(ctx, note.ID); err != nil {
+			t.Fatalf("DeleteNote() failed: %v", err)
+		}
+	}
+
+	trashed, _ := fs.ListTrash(ctx)
+	if err := fs.PurgeNote(ctx, trashed[0].TrashID); err != nil {
+		t.Fatalf("PurgeNote() failed: %v", err)
+	}
+	if trashed, _ := fs.ListTrash(ctx); len(trashed) != 1 {
+		t.Fatalf("expected 1 trashed note left, got %d", len(trashed))
+	}
+	if err := fs.PurgeNote(ctx, "../escape"); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("expected ErrInvalidID for a hostile trash ID, got %v", err)
+	}
+
+	if err := fs.EmptyTrash(ctx); err != nil {
+		t.Fatalf("EmptyTrash() failed: %v", err)
+	}
+	if trashed, _ := fs.ListTrash(ctx); len(trashed) != 0 {
+		t.Errorf("the trash should be empty, got %d notes", len(trashed))
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	ctx := context.Background()
+
+	trashOne := func(t *testing.T, opts ...storage.LocalOption) *storage.LocalFileSystem {
+		fs, err := storage.NewLocalFileSystemAt(filepath.Join(t.TempDir(), "notes"), opts...)
+		if err != nil {
+			t.Fatalf("NewLocalFileSystemAt() failed: %v", err)
+		}
+		note := storage.NewNote("Old", "")
+		saveNotes(t, fs, note)
+		if err := fs.DeleteNote(ctx, note.ID); err != nil {
+			t.Fatalf("DeleteNote() failed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		return fs
+	}
+
+	t.Run("removes notes past the retention", func(t *testing.T) {
+		fs := trashOne(t, storage.WithTrashRetention(time.Millisecond))
+
+		purged, err := fs.PurgeExpired(ctx)
+		if err != nil {
+			t.Fatalf("PurgeExpired() failed: %v", err)
+		}
+		if len(purged) != 1 || purged[0].Title != "Old" {
+			t.Errorf("expected the old note to be purged, got %v", purged)
+		}
+		if trashed, _ := fs.ListTrash(ctx); len(trashed) != 0 {
+			t.Errorf("the trash should be empty, got %d notes", len(trashed))
+		}
+	})
+
+	t.Run("keeps recent notes", func(t *testing.T) {
+		fs := trashOne(t)
+
+		purged, _ := fs.PurgeExpired(ctx)
+		if len(purged) != 0 {
+			t.Errorf("nothing should be purged within the default retention, got %v", purged)
+		}
+	})
+
+	t.Run("keeps everything with a zero retention", func(t *testing.T) {
+		fs := trashOne(t, storage.WithTrashRetention(0))
+
+		purged, _ := fs.PurgeExpired(ctx)
+		if len(purged) != 0 {
+			t.Errorf("nothing should be purged, got %v", purged)
+		}
+	})
+}
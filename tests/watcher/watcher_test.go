@@ -0,0 +1,146 @@
+package watcher_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N95Ryan/leaf/internal/watcher"
+	"github.com/N95Ryan/leaf/tests/testutil"
+)
+
+// nextEvent waits for an event, failing the test after a few seconds
+func nextEvent(t *testing.T, w *watcher.Watcher) watcher.Event {
+	t.Helper()
+
+	select {
+	case ev := <-w.Events():
+		return ev
+	case <-time.After(3 * time.Second):
+		t.Fatal("no event received")
+		return watcher.Event{}
+	}
+}
+
+// writeFile writes a file, failing the test on error
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("could not write %s: %v", path, err)
+	}
+}
+
+// testWatcher runs the same scenario against both implementations
+func testWatcher(t *testing.T, open func(dir string) *watcher.Watcher) {
+	t.Run("should report created, modified and removed notes", func(t *testing.T) {
+		assert := testutil.New(t)
+		dir := t.TempDir()
+		w := open(dir)
+		defer w.Close()
+
+		path := filepath.Join(dir, "abc.md")
+		writeFile(t, path, "# One")
+		ev := nextEvent(t, w)
+		assert.Equal(watcher.Changed, ev.Op, "creation is a change")
+		assert.Equal("abc", ev.ID, "ID should come from the file name")
+
+		// Make sure the poller sees a different size
+		writeFile(t, path, "# One, edited")
+		ev = nextEvent(t, w)
+		assert.Equal(watcher.Changed, ev.Op, "modification is a change")
+
+		os.Remove(path)
+		ev = nextEvent(t, w)
+		assert.Equal(watcher.Removed, ev.Op, "removal should be reported")
+		assert.Equal("abc", ev.ID, "removed ID should be reported")
+	})
+
+	t.Run("should ignore other files", func(t *testing.T) {
+		assert := testutil.New(t)
+		dir := t.TempDir()
+		w := open(dir)
+		defer w.Close()
+
+		writeFile(t, filepath.Join(dir, "notes.txt"), "x")
+		writeFile(t, filepath.Join(dir, ".abc.md.tmp"), "x")
+		writeFile(t, filepath.Join(dir, "real.md"), "x")
+
+		ev := nextEvent(t, w)
+		assert.Equal("real", ev.ID, "only notes should be reported")
+	})
+
+	t.Run("should report notes in folders but not in hidden directories", func(t *testing.T) {
+		assert := testutil.New(t)
+		dir := t.TempDir()
+		for _, sub := range []string{"work/projects", ".leaf"} {
+			if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w := open(dir)
+		defer w.Close()
+
+		writeFile(t, filepath.Join(dir, ".leaf", "hidden.md"), "x")
+		writeFile(t, filepath.Join(dir, "work", "projects", "nested.md"), "x")
+
+		ev := nextEvent(t, w)
+		assert.Equal(watcher.Changed, ev.Op, "creation is a change")
+		assert.Equal("nested", ev.ID, "notes in folders should be reported")
+	})
+
+	t.Run("should ask for a rescan when a folder moves", func(t *testing.T) {
+		assert := testutil.New(t)
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "work"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dir, "work", "abc.md"), "x")
+		w := open(dir)
+		defer w.Close()
+
+		if err := os.Rename(filepath.Join(dir, "work"), filepath.Join(dir, "archive")); err != nil {
+			t.Fatal(err)
+		}
+
+		// The poller sees the notes move, notifications only see the folder
+		ev := nextEvent(t, w)
+		if ev.Op != watcher.Rescan {
+			assert.Equal("abc", ev.ID, "the moved note should be reported")
+		}
+	})
+
+	t.Run("close should end the events", func(t *testing.T) {
+		assert := testutil.New(t)
+		w := open(t.TempDir())
+
+		assert.NoError(w.Close(), "Close should succeed")
+		_, ok := <-w.Events()
+		assert.False(ok, "events channel should be closed")
+	})
+}
+
+func TestWatcher(t *testing.T) {
+	testWatcher(t, func(dir string) *watcher.Watcher {
+		w, err := watcher.New(dir)
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		return w
+	})
+}
+
+func TestPoller(t *testing.T) {
+	testWatcher(t, func(dir string) *watcher.Watcher {
+		return watcher.NewPoller(dir, 20*time.Millisecond)
+	})
+}
+
+func TestNew_MissingDir(t *testing.T) {
+	assert := testutil.New(t)
+
+	_, err := watcher.New(filepath.Join(t.TempDir(), "missing"))
+
+	assert.Error(err, "missing directories should fail")
+}